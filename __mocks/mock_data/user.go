@@ -1,34 +1,60 @@
 package mock_data
 
 import (
-	"time"
+	"fmt"
+	"strings"
 
 	"boilerplate-api/database/dao"
+	"boilerplate-api/database/seeds/faker"
+	"boilerplate-api/lib/constants"
+
 	"github.com/brianvoe/gofakeit/v7"
+	"golang.org/x/crypto/bcrypt"
 )
 
-type FakeUser struct {
-	dao.User
-}
-
-var createdDate = time.Now()
-
-func (u *FakeUser) Fake(f *gofakeit.Faker) (any, error) {
-	u.ID = f.Uint32()
-	u.FullName = f.Name()
-	u.Email = f.Email()
-	u.Phone = f.Phone()
-	u.Gender = f.Gender()
-	u.Password = f.Word()
-	u.CreatedAt = createdDate
-	u.UpdatedAt = &u.CreatedAt
-
-	createdDate = createdDate.Add(1 * time.Minute)
-	return *u, nil
-}
+// EmailDomain domain of the fake users, they are unseeded by it
+const EmailDomain = "fake.example.com"
+
+// Password password of every fake user
+const Password = "password"
+
+// Users fake users, they can sign in with Password
+var Users = faker.NewFactory(
+	func(f *gofakeit.Faker) dao.User {
+		password, _ := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.DefaultCost)
+		return dao.User{
+			FullName: f.Name(),
+			Email:    fmt.Sprintf("%s.%s@%s", strings.ToLower(f.FirstName()), f.DigitN(6), EmailDomain),
+			Phone:    f.DigitN(10),
+			Gender:   f.RandomString([]string{string(constants.Male), string(constants.Female), string(constants.Other)}),
+			Password: string(password),
+			Status:   string(constants.UnVerifiedEmail),
+			Locale:   constants.DefaultLocale,
+		}
+	},
+)
 
-var Users = make([]FakeUser, 5)
+// UserDevices push notification devices, the user is set by faker.Has or faker.For
+var UserDevices = faker.NewFactory(
+	func(f *gofakeit.Faker) dao.UserDevice {
+		return dao.UserDevice{
+			Token: f.UUID(),
+			Platform: f.RandomString(
+				[]string{
+					string(constants.DevicePlatforms.Android),
+					string(constants.DevicePlatforms.IOS),
+					string(constants.DevicePlatforms.Web),
+				},
+			),
+		}
+	},
+)
 
-func init() {
-	gofakeit.Slice(&Users)
-}
+// Customers stripe customers, the user is set by faker.Has or faker.For
+var Customers = faker.NewFactory(
+	func(f *gofakeit.Faker) dao.Customer {
+		return dao.Customer{
+			StripeCustomerID: "cus_fake_" + f.LetterN(14),
+		}
+	},
+)
@@ -0,0 +1,101 @@
+package email_template
+
+import (
+	"errors"
+	"net/http"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/json_response"
+	"boilerplate-api/lib/utils"
+	"boilerplate-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	logger    config.Logger
+	templates services.EmailTemplates
+}
+
+// NewController creates new email template controller
+func NewController(
+	logger config.Logger,
+	templates services.EmailTemplates,
+) Controller {
+	return Controller{
+		logger:    logger,
+		templates: templates,
+	}
+}
+
+//	@Tags			EmailTemplateApi
+//	@Summary		Email templates
+//	@Description	lists the locales of every email template
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			If-None-Match	header		string	false	"ETag of the cached templates"
+//	@Success		200				{object}	json_response.Data[map[string][]string]
+//	@Header			200				{string}	ETag	"ETag of the templates"
+//	@Success		304				{string}	string	"Not modified"
+//	@Failure		403				{object}	json_response.Error[string]
+//	@Router			/api/v1/admin/email-templates [get]
+//	@Id				GetEmailTemplates
+func (cc Controller) GetTemplates(c *gin.Context) {
+	utils.DataWithETag(c, json_response.Data[map[string][]string]{Data: cc.templates.Locales()})
+}
+
+//	@Tags			EmailTemplateApi
+//	@Summary		Preview email template
+//	@Description	renders the template with its sample data, data in the request overrides the sample values
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			name	path		string			true	"Template name"
+//	@Param			data	body		PreviewRequest	false	"Enter JSON"
+//	@Success		200		{object}	json_response.Data[services.RenderedEmail]
+//	@Failure		400		{object}	json_response.Error[string]
+//	@Failure		403		{object}	json_response.Error[string]
+//	@Failure		404		{object}	json_response.Error[string]
+//	@Router			/api/v1/admin/email-templates/{name}/preview [post]
+//	@Id				PreviewEmailTemplate
+func (cc Controller) PreviewTemplate(c *gin.Context) {
+	request := PreviewRequest{}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			cc.logger.Error("Error [ShouldBindJson] : ", err)
+			c.JSON(
+				http.StatusBadRequest, json_response.Error[string]{
+					Error:   err.Error(),
+					Message: "Failed to bind request data",
+				},
+			)
+			return
+		}
+	}
+	if request.Locale == "" {
+		request.Locale = constants.DefaultLocale
+	}
+
+	rendered, err := cc.templates.Preview(c.Param("name"), request.Locale, request.Data)
+	if errors.Is(err, services.ErrEmailTemplateNotFound) {
+		c.JSON(
+			http.StatusNotFound, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to preview template",
+			},
+		)
+		return
+	}
+	if err != nil {
+		// missing keys in the data fail the render
+		c.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to preview template",
+			},
+		)
+		return
+	}
+
+	c.JSON(http.StatusOK, json_response.Data[services.RenderedEmail]{Data: rendered})
+}
@@ -0,0 +1,7 @@
+package email_template
+
+// PreviewRequest locale and data overriding the sample data of the template
+type PreviewRequest struct {
+	Locale string                 `json:"locale" example:"ja"`
+	Data   map[string]interface{} `json:"data"`
+}
@@ -0,0 +1,13 @@
+package email_template
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("email_template",
+	fx.Options(
+		fx.Provide(
+			NewController,
+		),
+		fx.Invoke(SetupRoutes),
+	))
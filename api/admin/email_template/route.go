@@ -0,0 +1,22 @@
+package email_template
+
+import (
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/middlewares"
+	"boilerplate-api/lib/router"
+)
+
+// SetupRoutes email template routes
+func SetupRoutes(
+	logger config.Logger,
+	router router.Router,
+	controller Controller,
+	jwtMiddleware middlewares.JWTAuthMiddleWare,
+) {
+	logger.Info(" Setting up email template routes")
+	templates := router.V1.Group("/admin/email-templates").Use(jwtMiddleware.HandleAdmin())
+	{
+		templates.GET("", controller.GetTemplates)
+		templates.POST("/:name/preview", controller.PreviewTemplate)
+	}
+}
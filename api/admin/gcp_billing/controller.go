@@ -5,6 +5,7 @@ import (
 
 	"boilerplate-api/lib/config"
 	"boilerplate-api/lib/json_response"
+	"boilerplate-api/lib/utils"
 	"boilerplate-api/services/gcp"
 	"cloud.google.com/go/billing/budgets/apiv1/budgetspb"
 	"google.golang.org/api/cloudbilling/v1"
@@ -45,8 +46,8 @@ func (cc Controller) GetCost(c *gin.Context) {
 		)
 		return
 	}
-	c.JSON(
-		http.StatusOK, json_response.Data[*cloudbilling.ProjectBillingInfo]{
+	utils.DataWithETag(
+		c, json_response.Data[*cloudbilling.ProjectBillingInfo]{
 			Data: billingData,
 		},
 	)
@@ -65,8 +66,8 @@ func (cc Controller) GetBudgetInfo(c *gin.Context) {
 		)
 		return
 	}
-	c.JSON(
-		http.StatusOK, json_response.Data[*budgetspb.Budget]{
+	utils.DataWithETag(
+		c, json_response.Data[*budgetspb.Budget]{
 			Data: billingData,
 		},
 	)
@@ -1,6 +1,9 @@
 package admin
 
 import (
+	"boilerplate-api/api/admin/email_template"
+	"boilerplate-api/api/admin/outbox"
+	"boilerplate-api/api/admin/task"
 	"boilerplate-api/api/admin/user"
 	"go.uber.org/fx"
 )
@@ -9,6 +12,9 @@ var Module = fx.Module(
 	"admin",
 	fx.Options(
 		user.Module,
+		email_template.Module,
+		outbox.Module,
+		task.Module,
 		//gcp_billing.Module,
 		//utility.Module,
 	),
@@ -0,0 +1,103 @@
+package outbox
+
+import (
+	"net/http"
+	"strconv"
+
+	"boilerplate-api/api/outbox"
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/json_response"
+	"boilerplate-api/lib/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	logger  config.Logger
+	service outbox.Service
+}
+
+// NewController creates new outbox controller
+func NewController(
+	logger config.Logger,
+	service outbox.Service,
+) Controller {
+	return Controller{
+		logger:  logger,
+		service: service,
+	}
+}
+
+//	@Tags			OutboxApi
+//	@Summary		Outbox messages
+//	@Description	lists outbox messages of the status, dead letters by default
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			pagination		query		outbox.Pagination	false	"query param"
+//	@Param			If-None-Match	header		string				false	"ETag of the cached messages"
+//	@Success		200				{object}	json_response.DataCount[dao.OutboxMessage]
+//	@Header			200				{string}	ETag	"ETag of the messages"
+//	@Success		304				{string}	string	"Not modified"
+//	@Failure		403				{object}	json_response.Error[string]
+//	@Failure		500				{object}	json_response.Error[string]
+//	@Router			/api/v1/admin/outbox [get]
+//	@Id				GetOutboxMessages
+func (cc Controller) GetMessages(c *gin.Context) {
+	pagination := utils.BuildPagination[*outbox.Pagination](c)
+
+	messages, count, errResponse := cc.service.GetMessages(c.Request.Context(), *pagination)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to get outbox messages",
+			},
+		)
+		return
+	}
+
+	utils.DataWithETag(
+		c, json_response.DataCount[dao.OutboxMessage]{
+			Count: count,
+			Data:  messages,
+		},
+	)
+}
+
+//	@Tags			OutboxApi
+//	@Summary		Retry outbox message
+//	@Description	queues dead message again with fresh attempts
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			id	path		int	true	"Message id"
+//	@Success		200	{object}	json_response.Message
+//	@Failure		400	{object}	json_response.Error[string]
+//	@Failure		403	{object}	json_response.Error[string]
+//	@Failure		404	{object}	json_response.Error[string]
+//	@Router			/api/v1/admin/outbox/{id}/retry [post]
+//	@Id				RetryOutboxMessage
+func (cc Controller) RetryMessage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Invalid message id",
+			},
+		)
+		return
+	}
+
+	if errResponse := cc.service.Retry(c.Request.Context(), id); errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to retry outbox message",
+			},
+		)
+		return
+	}
+
+	c.JSON(http.StatusOK, json_response.Message{Msg: "Message queued"})
+}
@@ -0,0 +1,13 @@
+package outbox
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("admin_outbox",
+	fx.Options(
+		fx.Provide(
+			NewController,
+		),
+		fx.Invoke(SetupRoutes),
+	))
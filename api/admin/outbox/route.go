@@ -0,0 +1,22 @@
+package outbox
+
+import (
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/middlewares"
+	"boilerplate-api/lib/router"
+)
+
+// SetupRoutes outbox routes
+func SetupRoutes(
+	logger config.Logger,
+	router router.Router,
+	controller Controller,
+	jwtMiddleware middlewares.JWTAuthMiddleWare,
+) {
+	logger.Info(" Setting up outbox routes")
+	messages := router.V1.Group("/admin/outbox").Use(jwtMiddleware.HandleAdmin())
+	{
+		messages.GET("", controller.GetMessages)
+		messages.POST("/:id/retry", controller.RetryMessage)
+	}
+}
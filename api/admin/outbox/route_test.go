@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-api/lib/auth"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/middlewares"
+	"boilerplate-api/lib/router"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryMessageRequiresAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := config.GetLogger()
+	env := config.Env{JwtAccessSecret: "secret"}
+	jwtService := auth.NewJWTAuthService(logger, env)
+
+	engine := gin.New()
+	SetupRoutes(
+		logger, router.Router{Engine: engine, V1: engine.Group("/api/v1")}, Controller{},
+		middlewares.NewJWTAuthMiddleWare(jwtService, logger, env),
+	)
+
+	token, err := jwtService.GenerateToken(
+		auth.JWTClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+				ID:        "1",
+			},
+			Role: constants.Roles.User,
+		}, env.JwtAccessSecret,
+	)
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/admin/outbox/1/retry", nil)
+	request.Header.Set(constants.Headers.Authorization.ToString(), "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
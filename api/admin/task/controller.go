@@ -0,0 +1,121 @@
+package task
+
+import (
+	"net/http"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/json_response"
+	"boilerplate-api/lib/utils"
+	"boilerplate-api/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	logger    config.Logger
+	scheduler *scheduler.Scheduler
+}
+
+// NewController creates new task controller
+func NewController(
+	logger config.Logger,
+	scheduler *scheduler.Scheduler,
+) Controller {
+	return Controller{
+		logger:    logger,
+		scheduler: scheduler,
+	}
+}
+
+//	@Tags			TaskApi
+//	@Summary		Scheduled tasks
+//	@Description	lists scheduled tasks with their next and last run
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			If-None-Match	header		string	false	"ETag of the cached tasks"
+//	@Success		200				{object}	json_response.Data[[]scheduler.TaskInfo]
+//	@Header			200				{string}	ETag	"ETag of the tasks"
+//	@Success		304				{string}	string	"Not modified"
+//	@Failure		403				{object}	json_response.Error[string]
+//	@Failure		500				{object}	json_response.Error[string]
+//	@Router			/api/v1/admin/tasks [get]
+//	@Id				GetTasks
+func (cc Controller) GetTasks(c *gin.Context) {
+	tasks, errResponse := cc.scheduler.Tasks(c.Request.Context())
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to get tasks",
+			},
+		)
+		return
+	}
+
+	utils.DataWithETag(c, json_response.Data[[]scheduler.TaskInfo]{Data: tasks})
+}
+
+//	@Tags			TaskApi
+//	@Summary		Task runs
+//	@Description	run history of the task, newest first
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			name			path		string				true	"Task name"
+//	@Param			pagination		query		utils.Pagination	false	"query param"
+//	@Param			If-None-Match	header		string				false	"ETag of the cached runs"
+//	@Success		200				{object}	json_response.DataCount[dao.TaskRun]
+//	@Header			200				{string}	ETag	"ETag of the runs"
+//	@Success		304				{string}	string	"Not modified"
+//	@Failure		403				{object}	json_response.Error[string]
+//	@Failure		404				{object}	json_response.Error[string]
+//	@Router			/api/v1/admin/tasks/{name}/runs [get]
+//	@Id				GetTaskRuns
+func (cc Controller) GetRuns(c *gin.Context) {
+	pagination := utils.BuildPagination[*utils.Pagination](c)
+
+	runs, count, errResponse := cc.scheduler.Runs(c.Request.Context(), constants.TaskName(c.Param("name")), *pagination)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to get task runs",
+			},
+		)
+		return
+	}
+
+	utils.DataWithETag(
+		c, json_response.DataCount[dao.TaskRun]{
+			Count: count,
+			Data:  runs,
+		},
+	)
+}
+
+//	@Tags			TaskApi
+//	@Summary		Run task
+//	@Description	runs the task now in background, see the runs for the result
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			name	path		string	true	"Task name"
+//	@Success		202		{object}	json_response.Message
+//	@Failure		403		{object}	json_response.Error[string]
+//	@Failure		404		{object}	json_response.Error[string]
+//	@Failure		409		{object}	json_response.Error[string]
+//	@Router			/api/v1/admin/tasks/{name}/run [post]
+//	@Id				RunTask
+func (cc Controller) RunTask(c *gin.Context) {
+	if errResponse := cc.scheduler.Trigger(constants.TaskName(c.Param("name"))); errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to run task",
+			},
+		)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, json_response.Message{Msg: "Task started"})
+}
@@ -0,0 +1,13 @@
+package task
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("task",
+	fx.Options(
+		fx.Provide(
+			NewController,
+		),
+		fx.Invoke(SetupRoutes),
+	))
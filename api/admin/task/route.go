@@ -0,0 +1,23 @@
+package task
+
+import (
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/middlewares"
+	"boilerplate-api/lib/router"
+)
+
+// SetupRoutes task routes
+func SetupRoutes(
+	logger config.Logger,
+	router router.Router,
+	controller Controller,
+	jwtMiddleware middlewares.JWTAuthMiddleWare,
+) {
+	logger.Info(" Setting up task routes")
+	tasks := router.V1.Group("/admin/tasks").Use(jwtMiddleware.HandleAdmin())
+	{
+		tasks.GET("", controller.GetTasks)
+		tasks.GET("/:name/runs", controller.GetRuns)
+		tasks.POST("/:name/run", controller.RunTask)
+	}
+}
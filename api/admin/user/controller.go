@@ -1,11 +1,11 @@
 package user
 
 import (
+	"errors"
 	"net/http"
 
 	"boilerplate-api/lib/api_errors"
 	"boilerplate-api/lib/config"
-	"boilerplate-api/lib/constants"
 	"boilerplate-api/lib/json_response"
 	"boilerplate-api/lib/request_validator"
 	"boilerplate-api/lib/utils"
@@ -50,7 +50,7 @@ func NewController(
 // @Id				CreateUser
 func (cc Controller) CreateUser(c *gin.Context) {
 	reqData := CreateUserRequestData{}
-	trx := c.MustGet(constants.DBTransaction).(*gorm.DB)
+	ctx := c.Request.Context()
 
 	if err := c.ShouldBindJSON(&reqData); err != nil {
 		cc.logger.Error("Error [CUser] (ShouldBindJson) : ", err)
@@ -83,7 +83,7 @@ func (cc Controller) CreateUser(c *gin.Context) {
 		return
 	}
 
-	if _, err := cc.userService.GetOneUserWithEmail(reqData.Email); err != nil {
+	if _, err := cc.userService.GetOneUserWithEmail(ctx, reqData.Email); !errors.Is(err, gorm.ErrRecordNotFound) {
 		cc.logger.Error("Error [CUser] [db CUser]: CUser with this email already exists")
 		c.JSON(
 			http.StatusBadRequest, json_response.Error[string]{
@@ -94,7 +94,7 @@ func (cc Controller) CreateUser(c *gin.Context) {
 		return
 	}
 
-	if _, err := cc.userService.GetOneUserWithPhone(reqData.Phone); err != nil {
+	if _, err := cc.userService.GetOneUserWithPhone(ctx, reqData.Phone); !errors.Is(err, gorm.ErrRecordNotFound) {
 		cc.logger.Error("Error [db GetOneUserWithPhone]: CUser with this phone already exists")
 		c.JSON(
 			http.StatusBadRequest, json_response.Error[string]{
@@ -105,7 +105,7 @@ func (cc Controller) CreateUser(c *gin.Context) {
 		return
 	}
 
-	if err := cc.userService.WithTrx(trx).CreateUser(reqData.CUser); err != nil {
+	if err := cc.userService.CreateUser(ctx, reqData.CUser); err != nil {
 		cc.logger.Error("Error [CUser] [db CUser]: ", err.Error())
 		c.JSON(
 			http.StatusInternalServerError, json_response.Error[string]{
@@ -128,15 +128,18 @@ func (cc Controller) CreateUser(c *gin.Context) {
 // @Description	get all users
 // @Security		Bearer
 // @Produce		application/json
-// @Param			pagination	query		Pagination	false	"query param"
-// @Success		200			{object}	json_response.DataCount[GetUserResponse]
-// @Failure		500			{object}	json_response.Error[string]
+// @Param			pagination		query		Pagination	false	"query param"
+// @Param			If-None-Match	header		string		false	"ETag of the cached users"
+// @Success		200				{object}	json_response.DataCount[GetUserResponse]
+// @Header			200				{string}	ETag	"ETag of the users"
+// @Success		304				{string}	string	"Not modified"
+// @Failure		500				{object}	json_response.Error[string]
 // @Router			/api/v1/users [get]
 // @Id				GetAllUsers
 func (cc Controller) GetAllUsers(c *gin.Context) {
 	pagination := utils.BuildPagination[*Pagination](c)
 
-	users, count, err := cc.userService.GetAllUsers(*pagination)
+	users, count, err := cc.userService.GetAllUsers(c.Request.Context(), *pagination)
 	if err != nil {
 		cc.logger.Error("Error finding user records", err.Error())
 		c.JSON(
@@ -148,8 +151,8 @@ func (cc Controller) GetAllUsers(c *gin.Context) {
 		return
 	}
 
-	c.JSON(
-		http.StatusOK, json_response.DataCount[GetUserResponse]{
+	utils.DataWithETag(
+		c, json_response.DataCount[GetUserResponse]{
 			Count: count,
 			Data:  users,
 		},
@@ -161,8 +164,11 @@ func (cc Controller) GetAllUsers(c *gin.Context) {
 // @Description	get user profile
 // @Security		Bearer
 // @Produce		application/json
-// @Success		200	{object}	json_response.Data[GetUserResponse]
-// @Failure		500	{object}	json_response.Error[string]
+// @Param			If-None-Match	header		string	false	"ETag of the cached user"
+// @Success		200				{object}	json_response.Data[GetUserResponse]
+// @Header			200				{string}	ETag	"Version of the user"
+// @Success		304				{string}	string	"Not modified"
+// @Failure		500				{object}	json_response.Error[string]
 // @Router			/api/v1/{id} [get]
 // @Id				GetOneUser
 func (cc Controller) GetOneUser(c *gin.Context) {
@@ -178,7 +184,7 @@ func (cc Controller) GetOneUser(c *gin.Context) {
 		return
 	}
 
-	user, err := cc.userService.GetOneUser(userID)
+	user, err := cc.userService.GetOneUser(c.Request.Context(), userID)
 	if err != nil {
 		cc.logger.Error("Error finding user", err.Error())
 		c.JSON(
@@ -190,9 +196,111 @@ func (cc Controller) GetOneUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(
-		http.StatusOK, json_response.Data[GetUserResponse]{
+	utils.DataWithETag(
+		c, json_response.Data[GetUserResponse]{
 			Data: user,
 		},
 	)
 }
+
+// @Tags			UserManagementApi
+// @Summary		Update user
+// @Description	update one user, If-Match has the ETag of the user it was read with
+// @Security		Bearer
+// @Produce		application/json
+// @Param			id			path		int						true	"User ID"
+// @Param			If-Match	header		string					true	"ETag of the user"
+// @Param			data		body		UpdateUserRequestData	true	"Enter JSON"
+// @Success		200			{object}	json_response.Data[GetUserResponse]
+// @Header			200			{string}	ETag	"Version of the updated user"
+// @Failure		400			{object}	json_response.Error[string]
+// @Failure		404			{object}	json_response.Error[string]
+// @Failure		412			{object}	json_response.Error[string]
+// @Failure		422			{object}	json_response.Error[[]api_errors.ValidationError]
+// @Failure		428			{object}	json_response.Error[string]
+// @Failure		500			{object}	json_response.Error[string]
+// @Router			/api/v1/users/{id} [put]
+// @Id				UpdateUser
+func (cc Controller) UpdateUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, errResponse := utils.StringToInt64(c.Param("id"))
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to update user",
+			},
+		)
+		return
+	}
+
+	version, errResponse := utils.IfMatchVersion(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to update user",
+			},
+		)
+		return
+	}
+
+	reqData := UpdateUserRequestData{}
+	if err := c.ShouldBindJSON(&reqData); err != nil {
+		cc.logger.Error("Error [UpdateUser] (ShouldBindJson) : ", err)
+		c.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to bind user data",
+			},
+		)
+		return
+	}
+
+	if validationErr := cc.validator.Struct(reqData); validationErr != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity, json_response.Error[[]api_errors.ValidationError]{
+				Error:   cc.validator.GenerateValidationResponse(validationErr),
+				Message: "Invalid input information",
+			},
+		)
+		return
+	}
+
+	existing, err := cc.userService.GetOneUserWithPhone(ctx, reqData.Phone)
+	if err == nil && int64(existing.ID) != userID {
+		c.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   "Failed to update user",
+				Message: "CUser with this phone already exists",
+			},
+		)
+		return
+	}
+
+	user, err := cc.userService.UpdateUser(ctx, userID, version, reqData)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, config.ErrVersionConflict):
+			status = http.StatusPreconditionFailed
+		default:
+			cc.logger.Error("Error [UpdateUser] [db UpdateUser]: ", err.Error())
+		}
+		c.JSON(
+			status, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to update user",
+			},
+		)
+		return
+	}
+
+	response := json_response.Data[GetUserResponse]{
+		Data: user,
+	}
+	c.Header("ETag", response.ETag())
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,133 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"boilerplate-api/api/user/user"
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/json_response"
+	"boilerplate-api/lib/middlewares"
+	"boilerplate-api/lib/request_validator"
+	"boilerplate-api/tests"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestControllerCreateAndGetAllUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := config.GetLogger()
+	db := tests.NewDatabase(t)
+	controller := NewController(
+		logger,
+		NewService(NewRepository(db, logger)),
+		config.Env{},
+		request_validator.NewValidator(),
+	)
+
+	engine := gin.New()
+	engine.POST(
+		"/users",
+		middlewares.NewDBTransactionMiddleware(logger, db).DBTransactionHandle(),
+		controller.CreateUser,
+	)
+	engine.GET("/users", controller.GetAllUsers)
+
+	create := func() int {
+		body := `{"full_name":"Jane Doe","email":"jane@example.com","phone":"9800000000","gender":"female",` +
+			`"password":"secret123","confirm_password":"secret123"}`
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body)))
+		return recorder.Code
+	}
+	assert.Equal(t, http.StatusOK, create())
+	assert.Equal(t, http.StatusBadRequest, create(), "email is taken")
+
+	etag := ""
+	list := func(keyword string) (response json_response.DataCount[GetUserResponse]) {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users?keyword="+keyword, nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		etag = recorder.Header().Get("ETag")
+		return response
+	}
+
+	users := list("Jane")
+	assert.True(t, strings.HasPrefix(etag, `W/"`), "pages get a weak etag")
+	request := httptest.NewRequest(http.MethodGet, "/users?keyword=Jane", nil)
+	request.Header.Set("If-None-Match", etag)
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.Bytes())
+
+	assert.EqualValues(t, 1, users.Count)
+	if assert.Len(t, users.Data, 1) {
+		assert.Equal(t, "jane@example.com", users.Data[0].Email)
+		assert.NotEqual(t, "secret123", users.Data[0].CUser.Password, "password is hashed")
+	}
+	assert.Zero(t, list("John").Count)
+}
+
+func TestControllerUpdateUserWithETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := config.GetLogger()
+	db := tests.NewDatabase(t)
+	service := NewService(NewRepository(db, logger))
+	controller := NewController(logger, service, config.Env{}, request_validator.NewValidator())
+
+	engine := gin.New()
+	engine.GET("/users/:id", controller.GetOneUser)
+	engine.PUT(
+		"/users/:id",
+		middlewares.NewDBTransactionMiddleware(logger, db).DBTransactionHandle(),
+		controller.UpdateUser,
+	)
+
+	assert.NoError(
+		t, service.CreateUser(
+			context.Background(), user.CUser{
+				User: dao.User{FullName: "Jane Doe", Email: "jane@example.com", Phone: "9800000000", Gender: "female"},
+			},
+		),
+	)
+	created, err := service.GetOneUserWithEmail(context.Background(), "jane@example.com")
+	assert.NoError(t, err)
+	path := fmt.Sprintf("/users/%d", created.ID)
+
+	serve := func(method string, header string, value string) *httptest.ResponseRecorder {
+		body := `{"full_name":"Jane Roe","phone":"9800000000","gender":"female"}`
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		if header != "" {
+			request.Header.Set(header, value)
+		}
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	read := serve(http.MethodGet, "", "")
+	assert.Equal(t, http.StatusOK, read.Code)
+	etag := read.Header().Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+	assert.Equal(t, http.StatusNotModified, serve(http.MethodGet, "If-None-Match", etag).Code)
+
+	assert.Equal(t, http.StatusPreconditionRequired, serve(http.MethodPut, "", "").Code)
+	updated := serve(http.MethodPut, "If-Match", etag)
+	assert.Equal(t, http.StatusOK, updated.Code)
+	assert.Equal(t, `"2"`, updated.Header().Get("ETag"))
+	assert.Equal(
+		t, http.StatusPreconditionFailed, serve(http.MethodPut, "If-Match", etag).Code,
+		"the user was updated since it was read",
+	)
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "If-None-Match", etag).Code, "cached user is stale")
+}
@@ -10,6 +10,13 @@ type CreateUserRequestData struct {
 	ConfirmPassword string `json:"confirm_password" validate:"required"`
 }
 
+// UpdateUserRequestData Request body data to update user
+type UpdateUserRequestData struct {
+	FullName string `json:"full_name" validate:"required"`
+	Phone    string `json:"phone" validate:"required"`
+	Gender   string `json:"gender" validate:"required"`
+}
+
 // GetUserResponse Dtos for CUser model
 type GetUserResponse struct {
 	user.CUser
@@ -1,9 +1,10 @@
 package user
 
 import (
+	"context"
+
 	"boilerplate-api/api/user/user"
 	"boilerplate-api/lib/config"
-	"gorm.io/gorm"
 )
 
 // Repository database structure
@@ -20,29 +21,23 @@ func NewRepository(db *config.Database, logger config.Logger) Repository {
 	}
 }
 
-// WithTrx enables repository with transaction
-func (c Repository) WithTrx(trxHandle *gorm.DB) Repository {
-	if trxHandle == nil {
-		c.logger.Error("Transaction Database not found in gin context. ")
-		return c
-	}
-	c.db.DB = trxHandle
-	return c
-}
-
 // Create user
-func (c Repository) Create(User user.CUser) error {
-	return c.db.DB.Create(&User).Error
+func (c Repository) Create(ctx context.Context, User user.CUser) error {
+	return c.db.Conn(ctx).Create(&User).Error
 }
 
 // GetAllUsers Get All users
-func (c Repository) GetAllUsers(pagination Pagination) (users []GetUserResponse, count int64, err error) {
-	queryBuilder := c.db.DB.Limit(pagination.PageSize).Offset(pagination.Offset).Order("created_at desc")
+func (c Repository) GetAllUsers(ctx context.Context, pagination Pagination) (
+	users []GetUserResponse,
+	count int64,
+	err error,
+) {
+	queryBuilder := c.db.Conn(ctx).Limit(pagination.PageSize).Offset(pagination.Offset).Order("created_at desc")
 	queryBuilder = queryBuilder.Model(&user.CUser{})
 
 	if pagination.Keyword != "" {
 		searchQuery := "%" + pagination.Keyword + "%"
-		queryBuilder.Where(c.db.DB.Where("`users`.`name` LIKE ?", searchQuery))
+		queryBuilder.Where("users.full_name LIKE ?", searchQuery)
 	}
 
 	return users, count, queryBuilder.
@@ -53,24 +48,35 @@ func (c Repository) GetAllUsers(pagination Pagination) (users []GetUserResponse,
 		Error
 }
 
-func (c Repository) GetOneUser(Id int64) (userModel GetUserResponse, err error) {
-	return userModel, c.db.DB.
+func (c Repository) GetOneUser(ctx context.Context, Id int64) (userModel GetUserResponse, err error) {
+	return userModel, c.db.Conn(ctx).
 		Model(&userModel).
 		Where("id = ?", Id).
 		First(&userModel).
 		Error
 }
 
-func (c Repository) GetOneUserWithEmail(Email string) (user user.CUser, err error) {
-	return user, c.db.DB.Model(&user).
+func (c Repository) GetOneUserWithEmail(ctx context.Context, Email string) (user user.CUser, err error) {
+	return user, c.db.Conn(ctx).Model(&user).
 		Where("email = ?", Email).
 		First(&user).
 		Error
 }
 
-func (c Repository) GetOneUserWithPhone(Phone string) (user user.CUser, err error) {
-	return user, c.db.DB.
+func (c Repository) GetOneUserWithPhone(ctx context.Context, Phone string) (user user.CUser, err error) {
+	return user, c.db.Conn(ctx).
 		First(&user, "phone = ?", Phone).
 		Error
 
 }
+
+// UpdateUser updates the user when it still has the version
+func (c Repository) UpdateUser(ctx context.Context, Id int64, version uint32, data UpdateUserRequestData) error {
+	return c.db.UpdateVersioned(
+		ctx, &user.CUser{}, Id, version, map[string]interface{}{
+			"full_name": data.FullName,
+			"phone":     data.Phone,
+			"gender":    data.Gender,
+		},
+	)
+}
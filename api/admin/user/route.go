@@ -24,5 +24,6 @@ func SetupRoutes(
 		users.GET("", userController.GetAllUsers)
 		users.POST("", trxMiddleware.DBTransactionHandle(), userController.CreateUser)
 		users.GET("/:id", userController.GetOneUser)
+		users.PUT("/:id", trxMiddleware.DBTransactionHandle(), userController.UpdateUser)
 	}
 }
@@ -1,8 +1,9 @@
 package user
 
 import (
+	"context"
+
 	"boilerplate-api/api/user/user"
-	"gorm.io/gorm"
 )
 
 type Service struct {
@@ -16,34 +17,39 @@ func NewService(repository Repository) Service {
 	}
 }
 
-// WithTrx repository with transaction
-func (c Service) WithTrx(trxHandle *gorm.DB) Service {
-	c.repository = c.repository.WithTrx(trxHandle)
-	return c
-}
-
 // CreateUser to create the CreateUser
-func (c Service) CreateUser(user user.CUser) error {
-	err := c.repository.Create(user)
+func (c Service) CreateUser(ctx context.Context, user user.CUser) error {
+	err := c.repository.Create(ctx, user)
 	return err
 }
 
 // GetAllUsers to get all the CreateUser
-func (c Service) GetAllUsers(pagination Pagination) ([]GetUserResponse, int64, error) {
-	return c.repository.GetAllUsers(pagination)
+func (c Service) GetAllUsers(ctx context.Context, pagination Pagination) ([]GetUserResponse, int64, error) {
+	return c.repository.GetAllUsers(ctx, pagination)
 }
 
 // GetOneUser one user
-func (c Service) GetOneUser(Id int64) (GetUserResponse, error) {
-	return c.repository.GetOneUser(Id)
+func (c Service) GetOneUser(ctx context.Context, Id int64) (GetUserResponse, error) {
+	return c.repository.GetOneUser(ctx, Id)
 }
 
 // GetOneUserWithEmail Get one user with email
-func (c Service) GetOneUserWithEmail(Email string) (user.CUser, error) {
-	return c.repository.GetOneUserWithEmail(Email)
+func (c Service) GetOneUserWithEmail(ctx context.Context, Email string) (user.CUser, error) {
+	return c.repository.GetOneUserWithEmail(ctx, Email)
 }
 
 // GetOneUserWithPhone Get one user with phone
-func (c Service) GetOneUserWithPhone(Phone string) (user.CUser, error) {
-	return c.repository.GetOneUserWithPhone(Phone)
+func (c Service) GetOneUserWithPhone(ctx context.Context, Phone string) (user.CUser, error) {
+	return c.repository.GetOneUserWithPhone(ctx, Phone)
+}
+
+// UpdateUser updates the user when it still has the version, 0 updates any version
+func (c Service) UpdateUser(ctx context.Context, Id int64, version uint32, data UpdateUserRequestData) (
+	GetUserResponse,
+	error,
+) {
+	if err := c.repository.UpdateUser(ctx, Id, version, data); err != nil {
+		return GetUserResponse{}, err
+	}
+	return c.repository.GetOneUser(ctx, Id)
 }
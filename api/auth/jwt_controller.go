@@ -74,7 +74,7 @@ func (cc JwtAuthController) LoginUserWithJWT(c *gin.Context) {
 	}
 
 	// Check if the user exists with provided email address
-	userData, err := cc.userService.GetOneUserWithEmail(reqData.Email)
+	userData, err := cc.userService.GetOneUserWithEmail(c.Request.Context(), reqData.Email)
 	if err != nil {
 		c.JSON(
 			http.StatusBadRequest, json_response.Error[string]{
@@ -105,6 +105,7 @@ func (cc JwtAuthController) LoginUserWithJWT(c *gin.Context) {
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * time.Duration(cc.env.JwtAccessTokenExpiresAt))),
 			ID:        fmt.Sprintf("%v", userData.ID),
 		},
+		Role: cc.jwtService.RoleOf(userData.Email),
 		//Add other claims
 	}
 
@@ -127,6 +128,7 @@ func (cc JwtAuthController) LoginUserWithJWT(c *gin.Context) {
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(cc.env.JwtRefreshTokenExpiresAt))),
 			ID:        fmt.Sprintf("%v", userData.ID),
 		},
+		Role: accessClaims.Role,
 	}
 
 	// Create a new JWT Refresh token using the claims and the secret key
@@ -197,6 +199,7 @@ func (cc JwtAuthController) RefreshJwtToken(c *gin.Context) {
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * time.Duration(cc.env.JwtAccessTokenExpiresAt))),
 			ID:        fmt.Sprintf("%v", claims.ID),
 		},
+		Role: claims.Role,
 		// Add other claims
 	}
 
@@ -0,0 +1,404 @@
+package billing
+
+import (
+	"fmt"
+	"net/http"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/json_response"
+	"boilerplate-api/lib/request_validator"
+	"boilerplate-api/lib/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxIdempotencyKeyLength longest idempotency key accepted by stripe
+const maxIdempotencyKeyLength = 255
+
+type Controller struct {
+	logger    config.Logger
+	service   Service
+	validator request_validator.Validator
+}
+
+// NewController creates new billing controller
+func NewController(
+	logger config.Logger,
+	service Service,
+	validator request_validator.Validator,
+) Controller {
+	return Controller{
+		logger:    logger,
+		service:   service,
+		validator: validator,
+	}
+}
+
+//	@Tags			BillingApi
+//	@Summary		Subscription plans
+//	@Description	lists active plans from stripe prices
+//	@Produce		application/json
+//	@Param			If-None-Match	header		string	false	"ETag of the cached plans"
+//	@Success		200				{object}	json_response.Data[[]PlanResponse]
+//	@Header			200				{string}	ETag	"ETag of the plans"
+//	@Success		304				{string}	string	"Not modified"
+//	@Failure		500				{object}	json_response.Error[string]
+//	@Router			/api/v1/billing/plans [get]
+//	@Id				GetPlans
+func (cc Controller) GetPlans(c *gin.Context) {
+	plans, errResponse := cc.service.GetPlans(c.Request.Context())
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to get plans",
+			},
+		)
+		return
+	}
+
+	utils.DataWithETag(c, json_response.Data[[]PlanResponse]{Data: plans})
+}
+
+//	@Tags			BillingApi
+//	@Summary		Current subscription
+//	@Description	returns subscription of the user mirrored from stripe
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			If-None-Match	header		string	false	"ETag of the cached subscription"
+//	@Success		200				{object}	json_response.Data[dao.Subscription]
+//	@Header			200				{string}	ETag	"ETag of the subscription"
+//	@Success		304				{string}	string	"Not modified"
+//	@Failure		404				{object}	json_response.Error[string]
+//	@Router			/api/v1/billing/subscription [get]
+//	@Id				GetSubscription
+func (cc Controller) GetSubscription(c *gin.Context) {
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to get subscription",
+			},
+		)
+		return
+	}
+
+	subscription, errResponse := cc.service.GetSubscription(c.Request.Context(), userID)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to get subscription",
+			},
+		)
+		return
+	}
+
+	utils.DataWithETag(c, json_response.Data[dao.Subscription]{Data: *subscription})
+}
+
+//	@Tags			BillingApi
+//	@Summary		Subscribe
+//	@Description	starts subscription of the plan, confirm the payment with returned client secret
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			data	body		SubscribeRequest	true	"Enter JSON"
+//	@Success		201		{object}	json_response.Data[SubscriptionResponse]
+//	@Failure		400		{object}	json_response.Error[string]
+//	@Failure		409		{object}	json_response.Error[string]
+//	@Failure		422		{object}	json_response.Error[[]api_errors.ValidationError]
+//	@Router			/api/v1/billing/subscription [post]
+//	@Id				Subscribe
+func (cc Controller) Subscribe(c *gin.Context) {
+
+	request, ok := cc.bindSubscribeRequest(c)
+	if !ok {
+		return
+	}
+
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to subscribe",
+			},
+		)
+		return
+	}
+
+	subscription, errResponse := cc.service.Subscribe(c.Request.Context(), userID, request)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to subscribe",
+			},
+		)
+		return
+	}
+
+	c.JSON(http.StatusCreated, json_response.Data[SubscriptionResponse]{Data: *subscription})
+}
+
+//	@Tags			BillingApi
+//	@Summary		Change plan
+//	@Description	upgrades or downgrades the plan or number of seats with proration
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			data	body		SubscribeRequest	true	"Enter JSON"
+//	@Success		200		{object}	json_response.Data[dao.Subscription]
+//	@Failure		400		{object}	json_response.Error[string]
+//	@Failure		404		{object}	json_response.Error[string]
+//	@Failure		422		{object}	json_response.Error[[]api_errors.ValidationError]
+//	@Router			/api/v1/billing/subscription [put]
+//	@Id				ChangePlan
+func (cc Controller) ChangePlan(c *gin.Context) {
+
+	request, ok := cc.bindSubscribeRequest(c)
+	if !ok {
+		return
+	}
+
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to change plan",
+			},
+		)
+		return
+	}
+
+	subscription, errResponse := cc.service.ChangePlan(c.Request.Context(), userID, request)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to change plan",
+			},
+		)
+		return
+	}
+
+	c.JSON(http.StatusOK, json_response.Data[dao.Subscription]{Data: *subscription})
+}
+
+//	@Tags			BillingApi
+//	@Summary		Cancel subscription
+//	@Description	cancels at the end of the period, or immediately with prorated credit
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			query	query		CancelSubscriptionQuery	false	"query param"
+//	@Success		200		{object}	json_response.Data[dao.Subscription]
+//	@Failure		404		{object}	json_response.Error[string]
+//	@Router			/api/v1/billing/subscription [delete]
+//	@Id				CancelSubscription
+func (cc Controller) CancelSubscription(c *gin.Context) {
+
+	query := CancelSubscriptionQuery{}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to bind query",
+			},
+		)
+		return
+	}
+
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to cancel subscription",
+			},
+		)
+		return
+	}
+
+	subscription, errResponse := cc.service.Cancel(c.Request.Context(), userID, query.Immediately)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to cancel subscription",
+			},
+		)
+		return
+	}
+
+	c.JSON(http.StatusOK, json_response.Data[dao.Subscription]{Data: *subscription})
+}
+
+//	@Tags			BillingApi
+//	@Summary		Checkout session
+//	@Description	creates stripe hosted checkout page for a plan or one-time price
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			Idempotency-Key	header		string					false	"Retries with the same key return the same session"
+//	@Param			data			body		CheckoutSessionRequest	true	"Enter JSON"
+//	@Success		201				{object}	json_response.Data[SessionResponse]
+//	@Failure		400				{object}	json_response.Error[string]
+//	@Failure		409				{object}	json_response.Error[string]
+//	@Failure		422				{object}	json_response.Error[[]api_errors.ValidationError]
+//	@Router			/api/v1/billing/checkout-sessions [post]
+//	@Id				CreateCheckoutSession
+func (cc Controller) CreateCheckoutSession(c *gin.Context) {
+
+	request := CheckoutSessionRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		cc.logger.Error("Error [CheckoutSessionRequest] (ShouldBindJson) : ", err)
+		c.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to bind request data",
+			},
+		)
+		return
+	}
+	if validationErr := cc.validator.Struct(request); validationErr != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity, json_response.Error[[]api_errors.ValidationError]{
+				Error:   cc.validator.GenerateValidationResponse(validationErr),
+				Message: "Invalid input information",
+			},
+		)
+		return
+	}
+
+	idempotencyKey, errResponse := getIdempotencyKey(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to create checkout session",
+			},
+		)
+		return
+	}
+
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to create checkout session",
+			},
+		)
+		return
+	}
+
+	session, errResponse := cc.service.CreateCheckoutSession(c.Request.Context(), userID, request, idempotencyKey)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to create checkout session",
+			},
+		)
+		return
+	}
+
+	c.JSON(http.StatusCreated, json_response.Data[SessionResponse]{Data: *session})
+}
+
+//	@Tags			BillingApi
+//	@Summary		Customer portal session
+//	@Description	creates stripe billing portal session to manage cards, invoices and the subscription
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			Idempotency-Key	header		string	false	"Retries with the same key return the same session"
+//	@Success		201				{object}	json_response.Data[SessionResponse]
+//	@Failure		404				{object}	json_response.Error[string]
+//	@Failure		409				{object}	json_response.Error[string]
+//	@Router			/api/v1/billing/portal-sessions [post]
+//	@Id				CreatePortalSession
+func (cc Controller) CreatePortalSession(c *gin.Context) {
+	idempotencyKey, errResponse := getIdempotencyKey(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to create portal session",
+			},
+		)
+		return
+	}
+
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to create portal session",
+			},
+		)
+		return
+	}
+
+	session, errResponse := cc.service.CreatePortalSession(c.Request.Context(), userID, idempotencyKey)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to create portal session",
+			},
+		)
+		return
+	}
+
+	c.JSON(http.StatusCreated, json_response.Data[SessionResponse]{Data: *session})
+}
+
+func (cc Controller) bindSubscribeRequest(c *gin.Context) (SubscribeRequest, bool) {
+	request := SubscribeRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		cc.logger.Error("Error [SubscribeRequest] (ShouldBindJson) : ", err)
+		c.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to bind request data",
+			},
+		)
+		return request, false
+	}
+	if validationErr := cc.validator.Struct(request); validationErr != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity, json_response.Error[[]api_errors.ValidationError]{
+				Error:   cc.validator.GenerateValidationResponse(validationErr),
+				Message: "Invalid input information",
+			},
+		)
+		return request, false
+	}
+	return request, true
+}
+
+// getUserID id of the authenticated user
+func getUserID(c *gin.Context) (uint32, *api_errors.ErrorResponse) {
+	userID, errResponse := utils.StringToInt64(fmt.Sprintf("%v", c.MustGet(constants.UserID)))
+	if errResponse != nil {
+		errResponse.ErrorType = api_errors.Unauthorized
+		return 0, errResponse
+	}
+	return uint32(userID), nil
+}
+
+// getIdempotencyKey optional Idempotency-Key header, forwarded to stripe
+func getIdempotencyKey(c *gin.Context) (string, *api_errors.ErrorResponse) {
+	key := c.GetHeader(constants.Headers.IdempotencyKey.ToString())
+	if len(key) > maxIdempotencyKeyLength {
+		return "", &api_errors.ErrorResponse{
+			ErrorType: api_errors.BadRequest,
+			Message:   fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength),
+		}
+	}
+	return key, nil
+}
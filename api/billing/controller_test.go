@@ -0,0 +1,79 @@
+package billing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/json_response"
+	"boilerplate-api/lib/request_validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stripe/stripe-go/v76"
+)
+
+func TestControllerSubscribe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service, backend, db := newTestService(t)
+	userID := createTestUser(t, db)
+	controller := NewController(config.GetLogger(), service, request_validator.NewValidator())
+	expectPlans(backend)
+
+	backend.On("Call", "POST", "/v1/customers", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			*args.Get(4).(*stripe.Customer) = stripe.Customer{ID: "cus_1"}
+		},
+	).Return(nil).Once()
+	backend.On("Call", "POST", "/v1/subscriptions", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			subscription := testSubscription(stripe.SubscriptionStatusIncomplete)
+			subscription.PendingSetupIntent = &stripe.SetupIntent{ClientSecret: "seti_1_secret"}
+			*args.Get(4).(*stripe.Subscription) = subscription
+		},
+	).Return(nil).Once()
+
+	engine := gin.New()
+	engine.GET("/billing/plans", controller.GetPlans)
+	subscription := engine.Group("/billing/subscription").Use(
+		func(c *gin.Context) {
+			c.Set(constants.UserID, userID)
+		},
+	)
+	subscription.GET("", controller.GetSubscription)
+	subscription.POST("", controller.Subscribe)
+
+	serve := func(method string, path string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	plans := serve(http.MethodGet, "/billing/plans", "")
+	assert.Equal(t, http.StatusOK, plans.Code)
+	assert.Contains(t, plans.Body.String(), `"formatted_amount":"10.00 USD"`)
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/billing/subscription", "").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, serve(http.MethodPost, "/billing/subscription", `{}`).Code)
+
+	created := serve(http.MethodPost, "/billing/subscription", `{"price_id":"price_1","quantity":2}`)
+	assert.Equal(t, http.StatusCreated, created.Code)
+	var response json_response.Data[SubscriptionResponse]
+	assert.NoError(t, json.Unmarshal(created.Body.Bytes(), &response))
+	assert.Equal(t, "sub_1", response.Data.StripeSubscriptionID)
+	if assert.NotNil(t, response.Data.ClientSecret) {
+		assert.Equal(t, "seti_1_secret", *response.Data.ClientSecret)
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/billing/subscription", "").Code)
+	assert.Equal(
+		t, http.StatusConflict, serve(http.MethodPost, "/billing/subscription", `{"price_id":"price_1"}`).Code,
+		"user already has a subscription",
+	)
+	backend.AssertExpectations(t)
+}
@@ -0,0 +1,58 @@
+package billing
+
+import (
+	"boilerplate-api/database/dao"
+)
+
+// PlanResponse subscription plan backed by a stripe price
+type PlanResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// UnitAmount in the smallest currency unit
+	UnitAmount      int64  `json:"unit_amount"`
+	FormattedAmount string `json:"formatted_amount"`
+	Currency        string `json:"currency"`
+	Interval        string `json:"interval"`
+	IntervalCount   int64  `json:"interval_count"`
+	TaxBehavior     string `json:"tax_behavior"`
+} // @name PlanResponse
+
+// SubscribeRequest request body to start or change the subscription
+type SubscribeRequest struct {
+	PriceID string `json:"price_id" validate:"required"`
+	// Quantity number of seats, defaults to 1
+	Quantity int64 `json:"quantity" validate:"omitempty,min=1,max=1000"`
+	// PromotionCode code entered by the customer, only applied when subscribing
+	PromotionCode string `json:"promotion_code" validate:"omitempty,max=100"`
+	// Coupon id of the coupon, only applied when subscribing, stripe takes either a coupon or a promotion code
+	Coupon string `json:"coupon" validate:"omitempty,max=100,excluded_with=PromotionCode"`
+	// TrialDays free trial before the first invoice, only on the first subscription of the user
+	TrialDays int64 `json:"trial_days" validate:"omitempty,min=1,max=90"`
+} // @name SubscribeRequest
+
+// CancelSubscriptionQuery query params to cancel the subscription
+type CancelSubscriptionQuery struct {
+	// Immediately cancels now with prorated credit instead of at the end of the period
+	Immediately bool `form:"immediately"`
+} // @name CancelSubscriptionQuery
+
+// SubscriptionResponse local subscription state
+type SubscriptionResponse struct {
+	dao.Subscription
+	// ClientSecret of the payment or setup intent to confirm on the client, set when the first payment is pending
+	ClientSecret *string `json:"client_secret,omitempty"`
+} // @name SubscriptionResponse
+
+// CheckoutSessionRequest request body to create hosted checkout page
+type CheckoutSessionRequest struct {
+	// Mode subscription for plans, payment for one-time prices
+	Mode     string `json:"mode" validate:"required,oneof=subscription payment"`
+	PriceID  string `json:"price_id" validate:"required"`
+	Quantity int64  `json:"quantity" validate:"omitempty,min=1,max=1000"`
+} // @name CheckoutSessionRequest
+
+// SessionResponse hosted stripe page the user is redirected to
+type SessionResponse struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+} // @name SessionResponse
@@ -0,0 +1,36 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"boilerplate-api/jobs"
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/constants"
+)
+
+// SyncSubscriptionPayload payload of the sync subscription job
+type SyncSubscriptionPayload struct {
+	StripeSubscriptionID string `json:"stripe_subscription_id"`
+}
+
+// NewSyncSubscriptionJob fetches the subscription from stripe and mirrors it, e.g. when a webhook was missed
+func NewSyncSubscriptionJob(service Service) jobs.Handler {
+	return jobs.NewHandler(
+		constants.JobNames.SyncSubscription,
+		func(ctx context.Context, payload SyncSubscriptionPayload) error {
+			if payload.StripeSubscriptionID == "" {
+				return jobs.Permanent(errors.New("stripe subscription id is required"))
+			}
+			if _, errResponse := service.refresh(ctx, payload.StripeSubscriptionID); errResponse != nil {
+				if errResponse.ErrorType == api_errors.NotFound {
+					return jobs.Permanent(errors.New(errResponse.Message))
+				}
+				return errors.New(errResponse.Message)
+			}
+			return nil
+		},
+		// stripe rate limits the api per account
+		jobs.WithConcurrency(2),
+	)
+}
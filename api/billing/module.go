@@ -0,0 +1,19 @@
+package billing
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"billing",
+	fx.Options(
+		fx.Provide(
+			NewRepository,
+			NewService,
+			NewController,
+			fx.Annotate(NewSyncSubscriptionJob, fx.ResultTags(`group:"jobs"`)),
+			fx.Annotate(NewReconcileSubscriptionsTask, fx.ResultTags(`group:"tasks"`)),
+		),
+		fx.Invoke(SetupRoutes),
+	),
+)
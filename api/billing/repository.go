@@ -0,0 +1,127 @@
+package billing
+
+import (
+	"context"
+	"errors"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"github.com/stripe/stripe-go/v76"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository database structure
+type Repository struct {
+	db     *config.Database
+	logger config.Logger
+}
+
+// NewRepository creates a new billing repository
+func NewRepository(db *config.Database, logger config.Logger) Repository {
+	return Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetUser gets user by id
+func (r Repository) GetUser(ctx context.Context, userID uint32) (user dao.User, err error) {
+	return user, r.db.Conn(ctx).
+		Where("id = ?", userID).
+		First(&user).
+		Error
+}
+
+// UpdateUserStatus updates signup status of the user, the version is incremented so cached ETags of the user go stale
+func (r Repository) UpdateUserStatus(ctx context.Context, userID uint32, status constants.UserStatus) error {
+	return r.db.Conn(ctx).Model(&dao.User{}).
+		Where("id = ?", userID).
+		Updates(
+			map[string]interface{}{
+				"status":  string(status),
+				"version": gorm.Expr("version + 1"),
+			},
+		).
+		Error
+}
+
+// GetCustomerByUserID gets stripe customer of the user, nil when it doesn't exist
+func (r Repository) GetCustomerByUserID(ctx context.Context, userID uint32) (*dao.Customer, error) {
+	return first[dao.Customer](r.db.Conn(ctx).Where("user_id = ?", userID))
+}
+
+// GetCustomerByStripeID gets customer by stripe customer id, nil when it doesn't exist
+func (r Repository) GetCustomerByStripeID(ctx context.Context, stripeCustomerID string) (*dao.Customer, error) {
+	return first[dao.Customer](r.db.Conn(ctx).Where("stripe_customer_id = ?", stripeCustomerID))
+}
+
+// CreateCustomer creates customer
+func (r Repository) CreateCustomer(ctx context.Context, customer *dao.Customer) error {
+	return r.db.Conn(ctx).Create(customer).Error
+}
+
+// GetSubscriptionForUpdate gets subscription by stripe id and locks the row, nil when it doesn't exist
+func (r Repository) GetSubscriptionForUpdate(ctx context.Context, stripeSubscriptionID string) (*dao.Subscription, error) {
+	return first[dao.Subscription](
+		r.db.Conn(ctx).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("stripe_subscription_id = ?", stripeSubscriptionID),
+	)
+}
+
+// GetCurrentSubscription latest subscription of the user which is not ended, nil when there is none
+func (r Repository) GetCurrentSubscription(ctx context.Context, userID uint32) (*dao.Subscription, error) {
+	return first[dao.Subscription](
+		r.db.Conn(ctx).
+			Where("user_id = ?", userID).
+			Where(
+				"status NOT IN ?", []string{
+					string(stripe.SubscriptionStatusCanceled),
+					string(stripe.SubscriptionStatusIncompleteExpired),
+				},
+			).
+			Order("id desc"),
+	)
+}
+
+// CountSubscriptions number of subscriptions the user ever had, including the ended ones
+func (r Repository) CountSubscriptions(ctx context.Context, userID uint32) (count int64, err error) {
+	return count, r.db.Conn(ctx).
+		Model(&dao.Subscription{}).
+		Where("user_id = ?", userID).
+		Count(&count).
+		Error
+}
+
+// GetOpenSubscriptions subscriptions which are not ended
+func (r Repository) GetOpenSubscriptions(ctx context.Context) (subscriptions []dao.Subscription, err error) {
+	return subscriptions, r.db.Conn(ctx).
+		Where(
+			"status NOT IN ?", []string{
+				string(stripe.SubscriptionStatusCanceled),
+				string(stripe.SubscriptionStatusIncompleteExpired),
+			},
+		).
+		Find(&subscriptions).
+		Error
+}
+
+// SaveSubscription creates or updates the subscription
+func (r Repository) SaveSubscription(ctx context.Context, subscription *dao.Subscription) error {
+	return r.db.Conn(ctx).Save(subscription).Error
+}
+
+func first[T any](query *gorm.DB) (*T, error) {
+	record := new(T)
+	err := query.First(record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
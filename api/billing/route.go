@@ -0,0 +1,30 @@
+package billing
+
+import (
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/middlewares"
+	"boilerplate-api/lib/router"
+)
+
+// SetupRoutes billing routes
+func SetupRoutes(
+	logger config.Logger,
+	router router.Router,
+	controller Controller,
+	jwtMiddleware middlewares.JWTAuthMiddleWare,
+) {
+	logger.Info(" Setting up billing routes")
+	billing := router.V1.Group("/billing")
+	{
+		billing.GET("/plans", controller.GetPlans)
+		// stripe is called outside of the database transaction, the service saves the local state in its own
+		billing.POST("/checkout-sessions", jwtMiddleware.Handle(), controller.CreateCheckoutSession)
+		billing.POST("/portal-sessions", jwtMiddleware.Handle(), controller.CreatePortalSession)
+
+		subscription := billing.Group("/subscription").Use(jwtMiddleware.Handle())
+		subscription.GET("", controller.GetSubscription)
+		subscription.POST("", controller.Subscribe)
+		subscription.PUT("", controller.ChangePlan)
+		subscription.DELETE("", controller.CancelSubscription)
+	}
+}
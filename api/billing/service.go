@@ -0,0 +1,585 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/services"
+
+	"github.com/stripe/stripe-go/v76"
+	"gorm.io/gorm"
+)
+
+// Service subscription billing, local tables mirror the stripe state
+//
+// stripe is never called inside a database transaction, a rollback would leave the stripe objects it created
+// behind. They are created with idempotency keys of the user and the action so retries get the same objects,
+// and the local state is saved in its own transaction afterward
+type Service struct {
+	logger     config.Logger
+	repository Repository
+	stripe     services.StripeService
+	unitOfWork config.UnitOfWork
+}
+
+// NewService creates new billing service
+func NewService(
+	logger config.Logger,
+	repository Repository,
+	stripe services.StripeService,
+	unitOfWork config.UnitOfWork,
+) Service {
+	return Service{
+		logger:     logger,
+		repository: repository,
+		stripe:     stripe,
+		unitOfWork: unitOfWork,
+	}
+}
+
+// GetPlans active plans of the product
+func (s Service) GetPlans(ctx context.Context) ([]PlanResponse, *api_errors.ErrorResponse) {
+	prices, err := s.stripe.ListPlans(ctx)
+	if err != nil {
+		s.logger.Error("Error listing stripe prices: ", err.Error())
+		return nil, services.ToErrorResponse(err, "Failed to get plans")
+	}
+
+	plans := make([]PlanResponse, 0, len(prices))
+	for _, price := range prices {
+		plan := PlanResponse{
+			ID:              price.ID,
+			Name:            price.Nickname,
+			UnitAmount:      price.UnitAmount,
+			FormattedAmount: services.FormatAmount(price.UnitAmount, price.Currency),
+			Currency:        string(price.Currency),
+			TaxBehavior:     string(price.TaxBehavior),
+		}
+		if price.Recurring != nil {
+			plan.Interval = string(price.Recurring.Interval)
+			plan.IntervalCount = price.Recurring.IntervalCount
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// GetSubscription current subscription of the user
+func (s Service) GetSubscription(ctx context.Context, userID uint32) (*dao.Subscription, *api_errors.ErrorResponse) {
+	subscription, err := s.repository.GetCurrentSubscription(ctx, userID)
+	if err != nil {
+		s.logger.Error("Error getting subscription: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get subscription",
+		}
+	}
+	if subscription == nil {
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.NotFound,
+			Message:   "Subscription not found",
+		}
+	}
+	return subscription, nil
+}
+
+// Subscribe starts subscription of the plan, stripe customer is created on first checkout
+//
+// subscription stays incomplete until the returned payment is confirmed on the client
+func (s Service) Subscribe(
+	ctx context.Context,
+	userID uint32,
+	request SubscribeRequest,
+) (*SubscriptionResponse, *api_errors.ErrorResponse) {
+	user, errResponse := s.getUser(ctx, userID)
+	if errResponse != nil {
+		return nil, errResponse
+	}
+
+	current, err := s.repository.GetCurrentSubscription(ctx, userID)
+	if err != nil {
+		s.logger.Error("Error getting subscription: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get subscription",
+		}
+	}
+	if current != nil {
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.Conflict,
+			Message:   "User already has a subscription, change the plan instead",
+		}
+	}
+
+	plan, errResponse := s.validatePlan(ctx, request.PriceID)
+	if errResponse != nil {
+		return nil, errResponse
+	}
+
+	options := services.SubscriptionOptions{
+		PriceID:   request.PriceID,
+		Quantity:  request.Quantity,
+		TrialDays: request.TrialDays,
+		// day of month anchors are only valid for monthly and yearly prices
+		AnchorToStartDate: plan.Interval == string(stripe.PriceRecurringIntervalDay) ||
+			plan.Interval == string(stripe.PriceRecurringIntervalWeek),
+		// prices of taxed plans have a tax behavior, the tax is added to or taken from the amount by stripe tax
+		AutomaticTax: plan.TaxBehavior != "" && plan.TaxBehavior != string(stripe.PriceTaxBehaviorUnspecified),
+	}
+	if request.PromotionCode != "" {
+		promotionCode, err := s.stripe.FindPromotionCode(ctx, request.PromotionCode)
+		if err != nil {
+			s.logger.Error("Error finding promotion code: ", err.Error())
+			return nil, services.ToErrorResponse(err, "Failed to apply promotion code")
+		}
+		if promotionCode == nil {
+			return nil, &api_errors.ErrorResponse{
+				ErrorType: api_errors.BadRequest,
+				Message:   "Invalid promotion code",
+			}
+		}
+		options.PromotionCode = promotionCode.ID
+	}
+	if request.Coupon != "" {
+		coupon, err := s.stripe.FindCoupon(ctx, request.Coupon)
+		if err != nil {
+			s.logger.Error("Error finding coupon: ", err.Error())
+			return nil, services.ToErrorResponse(err, "Failed to apply coupon")
+		}
+		if coupon == nil {
+			return nil, &api_errors.ErrorResponse{
+				ErrorType: api_errors.BadRequest,
+				Message:   "Invalid coupon",
+			}
+		}
+		options.Coupon = coupon.ID
+	}
+
+	count, err := s.repository.CountSubscriptions(ctx, userID)
+	if err != nil {
+		s.logger.Error("Error counting subscriptions: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get subscription",
+		}
+	}
+	if request.TrialDays > 0 && count > 0 {
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.BadRequest,
+			Message:   "Free trial is only available on the first subscription",
+		}
+	}
+
+	customer, errResponse := s.getOrCreateCustomer(ctx, user)
+	if errResponse != nil {
+		return nil, errResponse
+	}
+	options.CustomerID = customer.StripeCustomerID
+	// concurrent and retried requests get the same subscription until it is saved, the next one gets a new key
+	options.IdempotencyKey = fmt.Sprintf("subscription-%d-%d", userID, count+1)
+
+	subscription, err := s.stripe.CreateSubscription(ctx, options)
+	if err != nil {
+		s.logger.Error("Error creating stripe subscription: ", err.Error())
+		return nil, services.ToErrorResponse(err, "Failed to create subscription")
+	}
+
+	local, errResponse := s.sync(ctx, subscription)
+	if errResponse != nil {
+		return nil, errResponse
+	}
+
+	response := &SubscriptionResponse{Subscription: *local}
+	if invoice := subscription.LatestInvoice; invoice != nil && invoice.PaymentIntent != nil {
+		response.ClientSecret = &invoice.PaymentIntent.ClientSecret
+	} else if subscription.PendingSetupIntent != nil {
+		response.ClientSecret = &subscription.PendingSetupIntent.ClientSecret
+	}
+	return response, nil
+}
+
+// ChangePlan upgrades or downgrades the plan or seats, difference is prorated on the next invoice
+func (s Service) ChangePlan(
+	ctx context.Context,
+	userID uint32,
+	request SubscribeRequest,
+) (*dao.Subscription, *api_errors.ErrorResponse) {
+	local, errResponse := s.GetSubscription(ctx, userID)
+	if errResponse != nil {
+		return nil, errResponse
+	}
+
+	if _, errResponse = s.validatePlan(ctx, request.PriceID); errResponse != nil {
+		return nil, errResponse
+	}
+
+	subscription, err := s.stripe.GetSubscription(ctx, local.StripeSubscriptionID)
+	if err != nil {
+		s.logger.Error("Error getting stripe subscription: ", err.Error())
+		return nil, services.ToErrorResponse(err, "Failed to get subscription")
+	}
+	if subscription.Items == nil || len(subscription.Items.Data) == 0 {
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.Conflict,
+			Message:   "Subscription has no items",
+		}
+	}
+
+	item := subscription.Items.Data[0]
+	quantity := request.Quantity
+	if quantity == 0 {
+		quantity = item.Quantity
+	}
+	if item.Price != nil && item.Price.ID == request.PriceID && item.Quantity == quantity {
+		return local, nil
+	}
+
+	err = s.stripe.UpdateSubscription(
+		ctx, local.StripeSubscriptionID, &stripe.SubscriptionParams{
+			Items: []*stripe.SubscriptionItemsParams{
+				{
+					ID:       stripe.String(item.ID),
+					Price:    stripe.String(request.PriceID),
+					Quantity: stripe.Int64(quantity),
+				},
+			},
+			ProrationBehavior: stripe.String("create_prorations"),
+		},
+	)
+	if err != nil {
+		s.logger.Error("Error changing subscription plan: ", err.Error())
+		return nil, services.ToErrorResponse(err, "Failed to change plan")
+	}
+
+	return s.refresh(ctx, local.StripeSubscriptionID)
+}
+
+// Cancel cancels the subscription at the end of the period, or immediately with prorated credit
+func (s Service) Cancel(
+	ctx context.Context,
+	userID uint32,
+	immediately bool,
+) (*dao.Subscription, *api_errors.ErrorResponse) {
+	local, errResponse := s.GetSubscription(ctx, userID)
+	if errResponse != nil {
+		return nil, errResponse
+	}
+
+	var err error
+	if immediately {
+		err = s.stripe.CancelSubscription(
+			ctx, local.StripeSubscriptionID, &stripe.SubscriptionCancelParams{
+				Prorate: stripe.Bool(true),
+			},
+		)
+	} else {
+		err = s.stripe.UpdateSubscription(
+			ctx, local.StripeSubscriptionID, &stripe.SubscriptionParams{
+				CancelAtPeriodEnd: stripe.Bool(true),
+			},
+		)
+	}
+	if err != nil {
+		s.logger.Error("Error canceling subscription: ", err.Error())
+		return nil, services.ToErrorResponse(err, "Failed to cancel subscription")
+	}
+
+	return s.refresh(ctx, local.StripeSubscriptionID)
+}
+
+// CreateCheckoutSession creates hosted checkout page for a plan or one-time price
+func (s Service) CreateCheckoutSession(
+	ctx context.Context,
+	userID uint32,
+	request CheckoutSessionRequest,
+	idempotencyKey string,
+) (*SessionResponse, *api_errors.ErrorResponse) {
+	mode := stripe.CheckoutSessionMode(request.Mode)
+
+	user, errResponse := s.getUser(ctx, userID)
+	if errResponse != nil {
+		return nil, errResponse
+	}
+
+	if mode == stripe.CheckoutSessionModeSubscription {
+		current, err := s.repository.GetCurrentSubscription(ctx, userID)
+		if err != nil {
+			s.logger.Error("Error getting subscription: ", err.Error())
+			return nil, &api_errors.ErrorResponse{
+				ErrorType: api_errors.InternalError,
+				Message:   "Failed to get subscription",
+			}
+		}
+		if current != nil {
+			return nil, &api_errors.ErrorResponse{
+				ErrorType: api_errors.Conflict,
+				Message:   "User already has a subscription, change the plan instead",
+			}
+		}
+	}
+
+	price, err := s.stripe.GetPrice(ctx, request.PriceID)
+	if err != nil {
+		s.logger.Error("Error getting stripe price: ", err.Error())
+		if errResponse = services.ToErrorResponse(err, "Failed to get price"); errResponse.ErrorType != api_errors.NotFound {
+			return nil, errResponse
+		}
+	}
+	recurring := price != nil && price.Type == stripe.PriceTypeRecurring
+	if price == nil || !s.stripe.IsProductPrice(price) || recurring != (mode == stripe.CheckoutSessionModeSubscription) {
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.BadRequest,
+			Message:   "Invalid price for checkout mode",
+		}
+	}
+
+	customer, errResponse := s.getOrCreateCustomer(ctx, user)
+	if errResponse != nil {
+		return nil, errResponse
+	}
+
+	session, err := s.stripe.CreateCheckoutSession(
+		ctx, services.CheckoutSessionOptions{
+			Mode:           mode,
+			CustomerID:     customer.StripeCustomerID,
+			PriceID:        request.PriceID,
+			Quantity:       request.Quantity,
+			UserID:         strconv.FormatUint(uint64(userID), 10),
+			IdempotencyKey: idempotencyKey,
+			// customers enter promotion codes on the checkout page
+			AllowPromotionCodes: true,
+		},
+	)
+	if err != nil {
+		s.logger.Error("Error creating checkout session: ", err.Error())
+		return nil, services.ToErrorResponse(err, "Failed to create checkout session")
+	}
+	return &SessionResponse{ID: session.ID, URL: session.URL}, nil
+}
+
+// CreatePortalSession creates billing portal session to manage cards, invoices and the subscription
+func (s Service) CreatePortalSession(
+	ctx context.Context,
+	userID uint32,
+	idempotencyKey string,
+) (*SessionResponse, *api_errors.ErrorResponse) {
+	customer, err := s.repository.GetCustomerByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Error getting customer: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get customer",
+		}
+	}
+	if customer == nil {
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.NotFound,
+			Message:   "User has no billing account yet",
+		}
+	}
+
+	session, err := s.stripe.CreatePortalSession(ctx, customer.StripeCustomerID, idempotencyKey)
+	if err != nil {
+		s.logger.Error("Error creating portal session: ", err.Error())
+		return nil, services.ToErrorResponse(err, "Failed to create portal session")
+	}
+	return &SessionResponse{ID: session.ID, URL: session.URL}, nil
+}
+
+// SyncSubscription mirrors stripe subscription into local tables and moves the user through the signup statuses
+//
+// stripe doesn't guarantee delivery order of the events, changes older than the last applied one are skipped
+func (s Service) SyncSubscription(
+	ctx context.Context,
+	changedAt time.Time,
+	subscription *stripe.Subscription,
+	invoice *stripe.Invoice,
+) (*dao.Subscription, error) {
+	local, err := s.repository.GetSubscriptionForUpdate(ctx, subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+	if local == nil {
+		local = &dao.Subscription{StripeSubscriptionID: subscription.ID}
+	} else if local.StripeEventAt.After(changedAt) {
+		s.logger.Info("Skipping stale subscription change: ", subscription.ID)
+		return local, nil
+	}
+
+	local.StripeEventAt = changedAt
+	local.Status = string(subscription.Status)
+	local.CancelAtPeriodEnd = subscription.CancelAtPeriodEnd
+	local.CurrentPeriodEnd = unixToTime(subscription.CurrentPeriodEnd)
+	local.CanceledAt = unixToTime(subscription.CanceledAt)
+	if subscription.Customer != nil {
+		local.StripeCustomerID = subscription.Customer.ID
+	}
+	if subscription.Items != nil && len(subscription.Items.Data) > 0 && subscription.Items.Data[0].Price != nil {
+		local.StripePriceID = &subscription.Items.Data[0].Price.ID
+	}
+	if invoice != nil {
+		invoiceStatus := string(invoice.Status)
+		local.LatestInvoiceID = &invoice.ID
+		local.LatestInvoiceStatus = &invoiceStatus
+	}
+
+	if local.UserID == nil {
+		customer, err := s.repository.GetCustomerByStripeID(ctx, local.StripeCustomerID)
+		if err != nil {
+			return nil, err
+		}
+		if customer != nil {
+			local.UserID = &customer.UserID
+		}
+	}
+
+	if err = s.repository.SaveSubscription(ctx, local); err != nil {
+		return nil, err
+	}
+	if local.UserID == nil {
+		return local, nil
+	}
+
+	// an ended subscription must not reset the status driven by a newer one
+	current, err := s.repository.GetCurrentSubscription(ctx, *local.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.ID != local.ID {
+		return local, nil
+	}
+
+	return local, s.repository.UpdateUserStatus(ctx, *local.UserID, userStatusOf(subscription.Status))
+}
+
+// sync mirrors subscription returned by the stripe api in its own transaction
+func (s Service) sync(ctx context.Context, subscription *stripe.Subscription) (*dao.Subscription, *api_errors.ErrorResponse) {
+	var local *dao.Subscription
+	err := s.unitOfWork.Do(
+		ctx, func(ctx context.Context) (err error) {
+			local, err = s.SyncSubscription(ctx, time.Now(), subscription, nil)
+			return err
+		},
+	)
+	if err != nil {
+		s.logger.Error("Error saving subscription: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to save subscription",
+		}
+	}
+	return local, nil
+}
+
+// refresh fetches the subscription after a change and mirrors it
+func (s Service) refresh(ctx context.Context, stripeSubscriptionID string) (*dao.Subscription, *api_errors.ErrorResponse) {
+	subscription, err := s.stripe.GetSubscription(ctx, stripeSubscriptionID)
+	if err != nil {
+		s.logger.Error("Error getting stripe subscription: ", err.Error())
+		return nil, services.ToErrorResponse(err, "Failed to get subscription")
+	}
+	return s.sync(ctx, subscription)
+}
+
+func (s Service) validatePlan(ctx context.Context, priceID string) (PlanResponse, *api_errors.ErrorResponse) {
+	plans, errResponse := s.GetPlans(ctx)
+	if errResponse != nil {
+		return PlanResponse{}, errResponse
+	}
+	index := slices.IndexFunc(plans, func(plan PlanResponse) bool { return plan.ID == priceID })
+	if index == -1 {
+		return PlanResponse{}, &api_errors.ErrorResponse{
+			ErrorType: api_errors.BadRequest,
+			Message:   "Invalid plan",
+		}
+	}
+	return plans[index], nil
+}
+
+func (s Service) getUser(ctx context.Context, userID uint32) (dao.User, *api_errors.ErrorResponse) {
+	user, err := s.repository.GetUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("Error getting user: ", err.Error())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, &api_errors.ErrorResponse{
+				ErrorType: api_errors.NotFound,
+				Message:   "User not found",
+			}
+		}
+		return user, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get user",
+		}
+	}
+	return user, nil
+}
+
+// getOrCreateCustomer stripe customer of the user, it is saved right after it is created.
+// Concurrent and retried requests get the same customer from stripe and save it once
+func (s Service) getOrCreateCustomer(ctx context.Context, user dao.User) (*dao.Customer, *api_errors.ErrorResponse) {
+	customer, err := s.repository.GetCustomerByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.Error("Error getting customer: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get customer",
+		}
+	}
+	if customer != nil {
+		return customer, nil
+	}
+
+	stripeCustomer, err := s.stripe.CreateCustomer(
+		ctx, user.FullName, user.Email, fmt.Sprintf("customer-%d", user.ID),
+	)
+	if err != nil {
+		s.logger.Error("Error creating stripe customer: ", err.Error())
+		return nil, services.ToErrorResponse(err, "Failed to create customer")
+	}
+
+	customer = &dao.Customer{
+		UserID:           user.ID,
+		StripeCustomerID: stripeCustomer.ID,
+	}
+	if err = s.repository.CreateCustomer(ctx, customer); err != nil {
+		// saved by a concurrent request
+		if saved, getErr := s.repository.GetCustomerByUserID(ctx, user.ID); getErr == nil && saved != nil {
+			return saved, nil
+		}
+		s.logger.Error("Error saving customer: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to save customer",
+		}
+	}
+	return customer, nil
+}
+
+// userStatusOf signup status of the user with subscription in given status
+func userStatusOf(status stripe.SubscriptionStatus) constants.UserStatus {
+	switch status {
+	case stripe.SubscriptionStatusActive, stripe.SubscriptionStatusTrialing:
+		return constants.CardRegistered
+	case stripe.SubscriptionStatusIncomplete, stripe.SubscriptionStatusPastDue, stripe.SubscriptionStatusUnpaid:
+		// payment is pending or the card was declined
+		return constants.CardRegister
+	default:
+		return constants.PlanSelect
+	}
+}
+
+func unixToTime(timestamp int64) *time.Time {
+	if timestamp == 0 {
+		return nil
+	}
+	t := time.Unix(timestamp, 0)
+	return &t
+}
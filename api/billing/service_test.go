@@ -0,0 +1,227 @@
+package billing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/services"
+	"boilerplate-api/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stripe/stripe-go/v76"
+)
+
+func newTestService(t *testing.T) (Service, *tests.StripeBackendMock, *config.Database) {
+	logger := config.GetLogger()
+	db := tests.NewDatabase(t)
+	backend := new(tests.StripeBackendMock)
+	stripeService := services.NewStripeService(
+		services.NewStripeTestConfig("prod_1", backend.Backends(), logger.SugaredLogger),
+	)
+	return NewService(logger, NewRepository(db, logger), stripeService, config.NewUnitOfWork(db)), backend, db
+}
+
+func createTestUser(t *testing.T, db *config.Database) uint32 {
+	user := dao.User{
+		FullName: "Jane Doe",
+		Email:    "jane@example.com",
+		Phone:    "9800000000",
+		Gender:   "female",
+		Password: "secret",
+	}
+	assert.NoError(t, db.Create(&user).Error)
+	return user.ID
+}
+
+// expectPlans stripe has one monthly plan of the product
+func expectPlans(backend *tests.StripeBackendMock) {
+	backend.On("CallRaw", "GET", "/v1/prices", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			*args.Get(4).(*stripe.PriceList) = stripe.PriceList{
+				Data: []*stripe.Price{
+					{
+						ID:         "price_1",
+						Active:     true,
+						Currency:   stripe.CurrencyUSD,
+						UnitAmount: 1000,
+						Type:       stripe.PriceTypeRecurring,
+						Product:    &stripe.Product{ID: "prod_1"},
+						Recurring:  &stripe.PriceRecurring{Interval: stripe.PriceRecurringIntervalMonth, IntervalCount: 1},
+					},
+				},
+			}
+		},
+	).Return(nil)
+}
+
+func testSubscription(status stripe.SubscriptionStatus) stripe.Subscription {
+	return stripe.Subscription{
+		ID:       "sub_1",
+		Status:   status,
+		Customer: &stripe.Customer{ID: "cus_1"},
+		Items: &stripe.SubscriptionItemList{
+			Data: []*stripe.SubscriptionItem{{ID: "si_1", Quantity: 1, Price: &stripe.Price{ID: "price_1"}}},
+		},
+	}
+}
+
+func TestServiceSubscribe(t *testing.T) {
+	service, backend, db := newTestService(t)
+	userID := createTestUser(t, db)
+	ctx := context.Background()
+	expectPlans(backend)
+
+	backend.On("Call", "POST", "/v1/customers", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			params := args.Get(3).(*stripe.CustomerParams)
+			assert.Equal(t, fmt.Sprintf("customer-%d", userID), *params.IdempotencyKey)
+			*args.Get(4).(*stripe.Customer) = stripe.Customer{ID: "cus_1"}
+		},
+	).Return(nil).Once()
+
+	subscriptionKey := func(args mock.Arguments) {
+		params := args.Get(3).(*stripe.SubscriptionParams)
+		assert.Equal(t, fmt.Sprintf("subscription-%d-1", userID), *params.IdempotencyKey)
+	}
+	backend.On("Call", "POST", "/v1/subscriptions", "sk_test", mock.Anything, mock.Anything).
+		Run(subscriptionKey).
+		Return(&stripe.Error{HTTPStatusCode: 500, Msg: "unavailable"}).
+		Once()
+	backend.On("Call", "POST", "/v1/subscriptions", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			subscriptionKey(args)
+			subscription := testSubscription(stripe.SubscriptionStatusIncomplete)
+			subscription.LatestInvoice = &stripe.Invoice{
+				ID:            "in_1",
+				PaymentIntent: &stripe.PaymentIntent{ClientSecret: "pi_1_secret"},
+			}
+			*args.Get(4).(*stripe.Subscription) = subscription
+		},
+	).Return(nil).Once()
+
+	request := SubscribeRequest{PriceID: "price_1"}
+	_, errResponse := service.Subscribe(ctx, userID, request)
+	if assert.NotNil(t, errResponse) {
+		assert.NotEqual(t, api_errors.Conflict, errResponse.ErrorType)
+	}
+	customer, err := service.repository.GetCustomerByUserID(ctx, userID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, customer, "customer is saved before the subscription is created") {
+		assert.Equal(t, "cus_1", customer.StripeCustomerID)
+	}
+
+	// the retry reuses the saved customer and the idempotency key of the failed subscription
+	response, errResponse := service.Subscribe(ctx, userID, request)
+	assert.Nil(t, errResponse)
+	if assert.NotNil(t, response) {
+		assert.Equal(t, "sub_1", response.StripeSubscriptionID)
+		assert.Equal(t, userID, *response.UserID)
+		assert.Equal(t, "pi_1_secret", *response.ClientSecret)
+	}
+
+	var user dao.User
+	assert.NoError(t, db.First(&user, userID).Error)
+	assert.Equal(t, string(constants.CardRegister), user.Status)
+
+	_, errResponse = service.Subscribe(ctx, userID, request)
+	if assert.NotNil(t, errResponse) {
+		assert.Equal(t, api_errors.Conflict, errResponse.ErrorType)
+	}
+
+	_, errResponse = service.Subscribe(ctx, userID, SubscribeRequest{PriceID: "price_other"})
+	assert.NotNil(t, errResponse)
+	backend.AssertExpectations(t)
+}
+
+func TestServiceSubscribeTrialAndCoupon(t *testing.T) {
+	service, backend, db := newTestService(t)
+	userID := createTestUser(t, db)
+	ctx := context.Background()
+	expectPlans(backend)
+
+	backend.On("Call", "GET", "/v1/coupons/co_expired", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			*args.Get(4).(*stripe.Coupon) = stripe.Coupon{ID: "co_expired", Valid: false}
+		},
+	).Return(nil)
+	backend.On("Call", "GET", "/v1/coupons/co_1", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			*args.Get(4).(*stripe.Coupon) = stripe.Coupon{ID: "co_1", Valid: true}
+		},
+	).Return(nil)
+	backend.On("Call", "POST", "/v1/customers", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			*args.Get(4).(*stripe.Customer) = stripe.Customer{ID: "cus_1"}
+		},
+	).Return(nil).Once()
+	backend.On("Call", "POST", "/v1/subscriptions", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			params := args.Get(3).(*stripe.SubscriptionParams)
+			assert.Equal(t, int64(14), *params.TrialPeriodDays)
+			assert.Equal(t, "co_1", *params.Coupon)
+			assert.Equal(t, int64(1), *params.BillingCycleAnchorConfig.DayOfMonth, "monthly plans bill on the 1st")
+			assert.Nil(t, params.AutomaticTax, "the plan has no tax behavior")
+			subscription := testSubscription(stripe.SubscriptionStatusTrialing)
+			*args.Get(4).(*stripe.Subscription) = subscription
+		},
+	).Return(nil).Once()
+
+	_, errResponse := service.Subscribe(ctx, userID, SubscribeRequest{PriceID: "price_1", Coupon: "co_expired"})
+	if assert.NotNil(t, errResponse) {
+		assert.Equal(t, api_errors.BadRequest, errResponse.ErrorType)
+	}
+
+	_, errResponse = service.Subscribe(ctx, userID, SubscribeRequest{PriceID: "price_1", Coupon: "co_1", TrialDays: 14})
+	assert.Nil(t, errResponse)
+
+	// the ended subscription still counts
+	assert.NoError(
+		t, db.Model(&dao.Subscription{}).
+			Where("stripe_subscription_id = ?", "sub_1").
+			Update("status", string(stripe.SubscriptionStatusCanceled)).Error,
+	)
+	_, errResponse = service.Subscribe(ctx, userID, SubscribeRequest{PriceID: "price_1", TrialDays: 14})
+	if assert.NotNil(t, errResponse, "trial is only for the first subscription") {
+		assert.Equal(t, api_errors.BadRequest, errResponse.ErrorType)
+	}
+}
+
+func TestServiceCancel(t *testing.T) {
+	service, backend, db := newTestService(t)
+	userID := createTestUser(t, db)
+	ctx := context.Background()
+
+	assert.NoError(t, db.Create(&dao.Customer{UserID: userID, StripeCustomerID: "cus_1"}).Error)
+	active := testSubscription(stripe.SubscriptionStatusActive)
+	_, err := service.SyncSubscription(ctx, time.Now().Add(-time.Hour), &active, nil)
+	assert.NoError(t, err)
+
+	backend.On("Call", "POST", "/v1/subscriptions/sub_1", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			params := args.Get(3).(*stripe.SubscriptionParams)
+			assert.True(t, *params.CancelAtPeriodEnd)
+		},
+	).Return(nil).Once()
+	backend.On("Call", "GET", "/v1/subscriptions/sub_1", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			subscription := testSubscription(stripe.SubscriptionStatusActive)
+			subscription.CancelAtPeriodEnd = true
+			*args.Get(4).(*stripe.Subscription) = subscription
+		},
+	).Return(nil).Once()
+
+	subscription, errResponse := service.Cancel(ctx, userID, false)
+	assert.Nil(t, errResponse)
+	if assert.NotNil(t, subscription) {
+		assert.True(t, subscription.CancelAtPeriodEnd)
+		assert.Equal(t, string(stripe.SubscriptionStatusActive), subscription.Status)
+	}
+	backend.AssertExpectations(t)
+}
@@ -0,0 +1,36 @@
+package billing
+
+import (
+	"context"
+
+	"boilerplate-api/jobs"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/scheduler"
+)
+
+// NewReconcileSubscriptionsTask queues sync of every open subscription, so missed webhooks are caught up
+func NewReconcileSubscriptionsTask(logger config.Logger, repository Repository, client jobs.Client) scheduler.Task {
+	return scheduler.NewTask(
+		constants.TaskNames.ReconcileSubscriptions,
+		"@daily",
+		func(ctx context.Context) error {
+			subscriptions, err := repository.GetOpenSubscriptions(ctx)
+			if err != nil {
+				return err
+			}
+			for _, subscription := range subscriptions {
+				_, err := client.Enqueue(
+					ctx,
+					constants.JobNames.SyncSubscription,
+					SyncSubscriptionPayload{StripeSubscriptionID: subscription.StripeSubscriptionID},
+				)
+				if err != nil {
+					return err
+				}
+			}
+			logger.Info("Queued sync of ", len(subscriptions), " subscriptions")
+			return nil
+		},
+	)
+}
@@ -3,8 +3,12 @@ package api
 import (
 	"boilerplate-api/api/admin"
 	"boilerplate-api/api/auth"
+	"boilerplate-api/api/billing"
+	"boilerplate-api/api/notification"
+	"boilerplate-api/api/outbox"
 	"boilerplate-api/api/swagger"
 	"boilerplate-api/api/user"
+	"boilerplate-api/api/webhook"
 	"go.uber.org/fx"
 )
 
@@ -15,5 +19,9 @@ var Module = fx.Module(
 		admin.Module,
 		user.Module,
 		auth.Module,
+		billing.Module,
+		notification.Module,
+		outbox.Module,
+		webhook.Module,
 	),
 )
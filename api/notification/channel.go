@@ -0,0 +1,169 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/services"
+)
+
+// Recipient user the notification is sent to
+type Recipient struct {
+	User    dao.User
+	Devices []dao.UserDevice
+}
+
+// Message template rendered for the recipient
+type Message struct {
+	Event constants.NotificationEvent
+	// Subject and Body rendered from notification_templates, empty for channels rendering their own templates
+	Subject string
+	Body    string
+	Locale  string
+	Data    map[string]interface{}
+}
+
+// Channel delivers rendered messages, channels are provided to the `notification_channels` fx group
+type Channel interface {
+	Name() constants.NotificationChannel
+	// Reachable recipient has an address on this channel
+	Reachable(recipient Recipient) bool
+	Send(ctx context.Context, recipient Recipient, message Message) error
+}
+
+// TemplatedChannel renders messages from templates of its own instead of the notification_templates rows
+type TemplatedChannel interface {
+	Channel
+	HasTemplate(event constants.NotificationEvent, locale string) bool
+}
+
+// EmailChannel sends notifications by email, rendered from the email template of the event
+type EmailChannel struct {
+	env       config.Env
+	sender    services.EmailSender
+	templates services.EmailTemplates
+}
+
+// NewEmailChannel creates email channel
+func NewEmailChannel(
+	env config.Env,
+	sender services.EmailSender,
+	templates services.EmailTemplates,
+) EmailChannel {
+	return EmailChannel{
+		env:       env,
+		sender:    sender,
+		templates: templates,
+	}
+}
+
+func (EmailChannel) Name() constants.NotificationChannel {
+	return constants.NotificationChannels.Email
+}
+
+func (EmailChannel) Reachable(recipient Recipient) bool {
+	return recipient.User.Email != ""
+}
+
+func (e EmailChannel) HasTemplate(event constants.NotificationEvent, locale string) bool {
+	return e.templates.Has(string(event), locale)
+}
+
+func (e EmailChannel) Send(ctx context.Context, recipient Recipient, message Message) error {
+	rendered, err := e.templates.Render(string(message.Event), message.Locale, message.Data)
+	if err != nil {
+		return err
+	}
+
+	return e.sender.Send(
+		ctx, services.EmailParams{
+			From:     mail.Address{Name: e.env.MailFromName, Address: e.env.MailFromAddress},
+			To:       []mail.Address{{Name: recipient.User.FullName, Address: recipient.User.Email}},
+			Subject:  rendered.Subject,
+			TextBody: rendered.Text,
+			HTMLBody: rendered.HTML,
+		},
+	)
+}
+
+// SMSChannel sends notifications by sms
+type SMSChannel struct {
+	twilio services.TwilioService
+}
+
+// NewSMSChannel creates sms channel
+func NewSMSChannel(twilio services.TwilioService) SMSChannel {
+	return SMSChannel{twilio: twilio}
+}
+
+func (SMSChannel) Name() constants.NotificationChannel {
+	return constants.NotificationChannels.SMS
+}
+
+func (SMSChannel) Reachable(recipient Recipient) bool {
+	return recipient.User.Phone != ""
+}
+
+func (s SMSChannel) Send(ctx context.Context, recipient Recipient, message Message) error {
+	return s.twilio.MessageSuccess(
+		ctx, services.PhoneMessage{
+			Phone:   recipient.User.Phone,
+			Message: message.Body,
+		},
+	)
+}
+
+// PushChannel sends notifications to every registered device of the user
+type PushChannel struct {
+	logger     config.Logger
+	fcm        services.FCMService
+	repository Repository
+}
+
+// NewPushChannel creates push channel
+func NewPushChannel(logger config.Logger, fcm services.FCMService, repository Repository) PushChannel {
+	return PushChannel{
+		logger:     logger,
+		fcm:        fcm,
+		repository: repository,
+	}
+}
+
+func (PushChannel) Name() constants.NotificationChannel {
+	return constants.NotificationChannels.Push
+}
+
+func (PushChannel) Reachable(recipient Recipient) bool {
+	return len(recipient.Devices) > 0
+}
+
+// Send sends to each device, tokens fcm no longer knows are removed
+func (p PushChannel) Send(ctx context.Context, recipient Recipient, message Message) error {
+	var errs []error
+	for _, device := range recipient.Devices {
+		err := p.fcm.Send(
+			ctx, services.PushMessage{
+				Token: device.Token,
+				Title: message.Subject,
+				Body:  message.Body,
+				Data:  map[string]string{"event": string(message.Event)},
+			},
+		)
+		if services.IsUnregisteredToken(err) {
+			p.logger.Info("Removing unregistered device: ", device.ID)
+			if err := p.repository.DeleteDevice(ctx, 0, device.Token); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("device %d: %w", device.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
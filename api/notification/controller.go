@@ -0,0 +1,221 @@
+package notification
+
+import (
+	"fmt"
+	"net/http"
+
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/json_response"
+	"boilerplate-api/lib/request_validator"
+	"boilerplate-api/lib/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Controller struct {
+	logger    config.Logger
+	service   Service
+	validator request_validator.Validator
+}
+
+// NewController creates new notification controller
+func NewController(
+	logger config.Logger,
+	service Service,
+	validator request_validator.Validator,
+) Controller {
+	return Controller{
+		logger:    logger,
+		service:   service,
+		validator: validator,
+	}
+}
+
+//	@Tags			NotificationApi
+//	@Summary		Notification preferences
+//	@Description	lists every event and channel, channels are enabled unless the user disabled them
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			If-None-Match	header		string	false	"ETag of the cached preferences"
+//	@Success		200				{object}	json_response.Data[[]Preference]
+//	@Header			200				{string}	ETag	"ETag of the preferences"
+//	@Success		304				{string}	string	"Not modified"
+//	@Failure		500				{object}	json_response.Error[string]
+//	@Router			/api/v1/notifications/preferences [get]
+//	@Id				GetNotificationPreferences
+func (cc Controller) GetPreferences(c *gin.Context) {
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to get preferences",
+			},
+		)
+		return
+	}
+
+	preferences, errResponse := cc.service.GetPreferences(c.Request.Context(), userID)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to get preferences",
+			},
+		)
+		return
+	}
+
+	utils.DataWithETag(c, json_response.Data[[]Preference]{Data: preferences})
+}
+
+//	@Tags			NotificationApi
+//	@Summary		Update notification preferences
+//	@Description	enables or disables channels per event, preferences not in the request are kept
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			data	body		UpdatePreferencesRequest	true	"Enter JSON"
+//	@Success		200		{object}	json_response.Data[[]Preference]
+//	@Failure		400		{object}	json_response.Error[string]
+//	@Failure		422		{object}	json_response.Error[[]api_errors.ValidationError]
+//	@Router			/api/v1/notifications/preferences [put]
+//	@Id				UpdateNotificationPreferences
+func (cc Controller) UpdatePreferences(c *gin.Context) {
+	request := UpdatePreferencesRequest{}
+	if !cc.bind(c, &request) {
+		return
+	}
+
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to update preferences",
+			},
+		)
+		return
+	}
+
+	if errResponse := cc.service.UpdatePreferences(c.Request.Context(), userID, request.Preferences); errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to update preferences",
+			},
+		)
+		return
+	}
+
+	cc.GetPreferences(c)
+}
+
+//	@Tags			NotificationApi
+//	@Summary		Register device
+//	@Description	registers fcm token of the app for push notifications
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			data	body		RegisterDeviceRequest	true	"Enter JSON"
+//	@Success		200		{object}	json_response.Message
+//	@Failure		422		{object}	json_response.Error[[]api_errors.ValidationError]
+//	@Router			/api/v1/notifications/devices [post]
+//	@Id				RegisterDevice
+func (cc Controller) RegisterDevice(c *gin.Context) {
+	request := RegisterDeviceRequest{}
+	if !cc.bind(c, &request) {
+		return
+	}
+
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to register device",
+			},
+		)
+		return
+	}
+
+	if errResponse := cc.service.RegisterDevice(c.Request.Context(), userID, request); errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to register device",
+			},
+		)
+		return
+	}
+
+	c.JSON(http.StatusOK, json_response.Message{Msg: "Device registered"})
+}
+
+//	@Tags			NotificationApi
+//	@Summary		Unregister device
+//	@Description	stops push notifications to the token, e.g. on logout
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			token	path		string	true	"fcm token"
+//	@Success		200		{object}	json_response.Message
+//	@Router			/api/v1/notifications/devices/{token} [delete]
+//	@Id				UnregisterDevice
+func (cc Controller) UnregisterDevice(c *gin.Context) {
+	userID, errResponse := getUserID(c)
+	if errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to unregister device",
+			},
+		)
+		return
+	}
+
+	if errResponse := cc.service.UnregisterDevice(c.Request.Context(), userID, c.Param("token")); errResponse != nil {
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to unregister device",
+			},
+		)
+		return
+	}
+
+	c.JSON(http.StatusOK, json_response.Message{Msg: "Device unregistered"})
+}
+
+// bind binds and validates json body, responds with the error when it is invalid
+func (cc Controller) bind(c *gin.Context, request interface{}) bool {
+	if err := c.ShouldBindJSON(request); err != nil {
+		cc.logger.Error("Error [ShouldBindJson] : ", err)
+		c.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to bind request data",
+			},
+		)
+		return false
+	}
+	if validationErr := cc.validator.Struct(request); validationErr != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity, json_response.Error[[]api_errors.ValidationError]{
+				Error:   cc.validator.GenerateValidationResponse(validationErr),
+				Message: "Invalid input information",
+			},
+		)
+		return false
+	}
+	return true
+}
+
+// getUserID id of the authenticated user
+func getUserID(c *gin.Context) (uint32, *api_errors.ErrorResponse) {
+	userID, errResponse := utils.StringToInt64(fmt.Sprintf("%v", c.MustGet(constants.UserID)))
+	if errResponse != nil {
+		errResponse.ErrorType = api_errors.Unauthorized
+		return 0, errResponse
+	}
+	return uint32(userID), nil
+}
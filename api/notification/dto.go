@@ -0,0 +1,20 @@
+package notification
+
+// Preference whether notifications of the event are sent through the channel
+type Preference struct {
+	Event   string `json:"event" validate:"required"`
+	Channel string `json:"channel" validate:"required,oneof=email sms push"`
+	Enabled bool   `json:"enabled"`
+} // @name NotificationPreference
+
+// UpdatePreferencesRequest request body to update notification preferences
+type UpdatePreferencesRequest struct {
+	Preferences []Preference `json:"preferences" validate:"required,min=1,max=100,dive"`
+} // @name UpdatePreferencesRequest
+
+// RegisterDeviceRequest request body to register push notification device
+type RegisterDeviceRequest struct {
+	// Token fcm registration token of the app
+	Token    string `json:"token" validate:"required,max=255"`
+	Platform string `json:"platform" validate:"required,oneof=android ios web"`
+} // @name RegisterDeviceRequest
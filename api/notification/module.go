@@ -0,0 +1,35 @@
+package notification
+
+import (
+	"boilerplate-api/api/outbox"
+
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"notification",
+	fx.Options(
+		fx.Provide(
+			NewRepository,
+			fx.Annotate(
+				NewService,
+				fx.ParamTags(``, ``, `group:"notification_channels"`),
+			),
+			NewQueue,
+			func(queue Queue) Notifier {
+				return queue
+			},
+			NewController,
+			fx.Annotate(NewPurgeStaleDevicesTask, fx.ResultTags(`group:"tasks"`)),
+		),
+		fx.Provide(
+			fx.Annotate(NewEmailChannel, fx.As(new(Channel)), fx.ResultTags(`group:"notification_channels"`)),
+			fx.Annotate(NewSMSChannel, fx.As(new(Channel)), fx.ResultTags(`group:"notification_channels"`)),
+			fx.Annotate(NewPushChannel, fx.As(new(Channel)), fx.ResultTags(`group:"notification_channels"`)),
+		),
+		fx.Provide(
+			fx.Annotate(NewOutboxHandler, fx.As(new(outbox.Handler)), fx.ResultTags(`group:"outbox_handlers"`)),
+		),
+		fx.Invoke(SetupRoutes),
+	),
+)
@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"boilerplate-api/api/outbox"
+	"boilerplate-api/lib/constants"
+)
+
+// Payload outbox message of a notification, one message per channel so a retry doesn't resend the others
+type Payload struct {
+	UserID  uint32                        `json:"user_id"`
+	Event   constants.NotificationEvent   `json:"event"`
+	Channel constants.NotificationChannel `json:"channel"`
+	Data    map[string]interface{}        `json:"data,omitempty"`
+}
+
+// Queue Notifier publishing notifications to the outbox
+//
+// notifications published within a transaction of the context are sent only after it commits
+type Queue struct {
+	outbox outbox.Service
+}
+
+// NewQueue creates new notification queue
+func NewQueue(outbox outbox.Service) Queue {
+	return Queue{outbox: outbox}
+}
+
+// Notify publishes notification of the event for every channel, preferences are checked when it is sent
+func (q Queue) Notify(
+	ctx context.Context,
+	userID uint32,
+	event constants.NotificationEvent,
+	data map[string]interface{},
+) error {
+	for _, channel := range constants.NotificationChannelList {
+		err := q.outbox.Publish(
+			ctx,
+			constants.OutboxTopics.Notification,
+			Payload{
+				UserID:  userID,
+				Event:   event,
+				Channel: channel,
+				Data:    data,
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OutboxHandler sends notifications published by Queue
+type OutboxHandler struct {
+	service Service
+}
+
+// NewOutboxHandler creates outbox handler of the notifications
+func NewOutboxHandler(service Service) OutboxHandler {
+	return OutboxHandler{service: service}
+}
+
+func (OutboxHandler) Topic() constants.OutboxTopic {
+	return constants.OutboxTopics.Notification
+}
+
+func (h OutboxHandler) Handle(ctx context.Context, content []byte) error {
+	payload := Payload{}
+	if err := json.Unmarshal(content, &payload); err != nil {
+		return outbox.Permanent(err)
+	}
+
+	err := h.service.send(ctx, payload.UserID, payload.Event, []constants.NotificationChannel{payload.Channel}, payload.Data)
+	if errors.Is(err, ErrRecipientNotFound) {
+		return outbox.Permanent(err)
+	}
+	return err
+}
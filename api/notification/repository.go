@@ -0,0 +1,112 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository database structure
+type Repository struct {
+	db     *config.Database
+	logger config.Logger
+}
+
+// NewRepository creates a new notification repository
+func NewRepository(db *config.Database, logger config.Logger) Repository {
+	return Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetUser gets the recipient, nil when the user doesn't exist
+func (r Repository) GetUser(ctx context.Context, userID uint32) (*dao.User, error) {
+	user := dao.User{}
+	err := r.db.Conn(ctx).Where("id = ?", userID).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetTemplates templates of the event in the given locales
+func (r Repository) GetTemplates(ctx context.Context, event string, locales []string) (
+	templates []dao.NotificationTemplate,
+	err error,
+) {
+	return templates, r.db.Conn(ctx).
+		Where("event = ?", event).
+		Where("locale IN ?", locales).
+		Find(&templates).
+		Error
+}
+
+// GetPreferences preferences the user has set, missing ones are enabled
+func (r Repository) GetPreferences(ctx context.Context, userID uint32) (
+	preferences []dao.NotificationPreference,
+	err error,
+) {
+	return preferences, r.db.Conn(ctx).
+		Where("user_id = ?", userID).
+		Find(&preferences).
+		Error
+}
+
+// SavePreferences creates or updates the preferences of the user
+func (r Repository) SavePreferences(ctx context.Context, preferences []dao.NotificationPreference) error {
+	return r.db.Conn(ctx).
+		Clauses(
+			clause.OnConflict{
+				DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+			},
+		).
+		Create(&preferences).
+		Error
+}
+
+// GetDevices push notification devices of the user
+func (r Repository) GetDevices(ctx context.Context, userID uint32) (devices []dao.UserDevice, err error) {
+	return devices, r.db.Conn(ctx).
+		Where("user_id = ?", userID).
+		Find(&devices).
+		Error
+}
+
+// SaveDevice registers the device token, token registered by another user is moved to this user
+func (r Repository) SaveDevice(ctx context.Context, device *dao.UserDevice) error {
+	return r.db.Conn(ctx).
+		Clauses(
+			clause.OnConflict{
+				DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "updated_at"}),
+			},
+		).
+		Create(device).
+		Error
+}
+
+// DeleteDevice deletes the device token, userID zero deletes it regardless of the owner
+func (r Repository) DeleteDevice(ctx context.Context, userID uint32, token string) error {
+	query := r.db.Conn(ctx).Where("token = ?", token)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	return query.Delete(&dao.UserDevice{}).Error
+}
+
+// DeleteStaleDevices deletes device tokens not registered again since the time
+func (r Repository) DeleteStaleDevices(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.Conn(ctx).
+		Where("updated_at < ?", before).
+		Delete(&dao.UserDevice{})
+	return result.RowsAffected, result.Error
+}
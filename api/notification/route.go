@@ -0,0 +1,24 @@
+package notification
+
+import (
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/middlewares"
+	"boilerplate-api/lib/router"
+)
+
+// SetupRoutes notification routes
+func SetupRoutes(
+	logger config.Logger,
+	router router.Router,
+	controller Controller,
+	jwtMiddleware middlewares.JWTAuthMiddleWare,
+) {
+	logger.Info(" Setting up notification routes")
+	notifications := router.V1.Group("/notifications").Use(jwtMiddleware.Handle())
+	{
+		notifications.GET("/preferences", controller.GetPreferences)
+		notifications.PUT("/preferences", controller.UpdatePreferences)
+		notifications.POST("/devices", controller.RegisterDevice)
+		notifications.DELETE("/devices/:token", controller.UnregisterDevice)
+	}
+}
@@ -0,0 +1,311 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"text/template"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+)
+
+// ErrRecipientNotFound user of the notification doesn't exist
+var ErrRecipientNotFound = errors.New("notification recipient not found")
+
+// Notifier sends notification of the event to the user through every channel the user has enabled
+type Notifier interface {
+	Notify(ctx context.Context, userID uint32, event constants.NotificationEvent, data map[string]interface{}) error
+}
+
+// Service notification templates, preferences and devices
+type Service struct {
+	logger     config.Logger
+	repository Repository
+	channels   []Channel
+}
+
+// NewService creates new notification service
+func NewService(
+	logger config.Logger,
+	repository Repository,
+	channels []Channel,
+) Service {
+	return Service{
+		logger:     logger,
+		repository: repository,
+		channels:   channels,
+	}
+}
+
+// Notify renders template of the event in the user's locale and sends it to each enabled channel
+//
+// email is rendered from the files in templates/emails, other channels from notification_templates.
+// channels without template or address of the user are skipped,
+// failure of one channel doesn't stop the others and all errors are returned joined
+func (s Service) Notify(
+	ctx context.Context,
+	userID uint32,
+	event constants.NotificationEvent,
+	data map[string]interface{},
+) error {
+	return s.send(ctx, userID, event, constants.NotificationChannelList, data)
+}
+
+// send sends the notification through the given channels only
+func (s Service) send(
+	ctx context.Context,
+	userID uint32,
+	event constants.NotificationEvent,
+	channels []constants.NotificationChannel,
+	data map[string]interface{},
+) error {
+	user, err := s.repository.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("%w: %d", ErrRecipientNotFound, userID)
+	}
+
+	enabled, err := s.enabledChannels(ctx, userID, event)
+	if err != nil {
+		return err
+	}
+	templates, err := s.templates(ctx, event, user.Locale)
+	if err != nil {
+		return err
+	}
+
+	recipient := Recipient{User: *user}
+	if enabled[constants.NotificationChannels.Push] && slices.Contains(channels, constants.NotificationChannels.Push) {
+		if recipient.Devices, err = s.repository.GetDevices(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	// templates can use the recipient fields next to the event data
+	templateData := map[string]interface{}{
+		"FullName": user.FullName,
+		"Email":    user.Email,
+	}
+	for key, value := range data {
+		templateData[key] = value
+	}
+
+	var errs []error
+	for _, channel := range s.channels {
+		name := channel.Name()
+		if !enabled[name] || !slices.Contains(channels, name) || !channel.Reachable(recipient) {
+			continue
+		}
+
+		var message Message
+		if templated, ok := channel.(TemplatedChannel); ok {
+			if !templated.HasTemplate(event, user.Locale) {
+				continue
+			}
+			message = Message{Event: event, Locale: user.Locale, Data: templateData}
+		} else {
+			tmpl, ok := templates[name]
+			if !ok {
+				continue
+			}
+			if message, err = render(event, user.Locale, tmpl, templateData); err != nil {
+				errs = append(errs, fmt.Errorf("%s template of %s: %w", name, event, err))
+				continue
+			}
+		}
+		if err := channel.Send(ctx, recipient, message); err != nil {
+			s.logger.Error("Error sending ", name, " notification: ", err.Error())
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetPreferences preference of every event and channel, defaults to enabled
+func (s Service) GetPreferences(ctx context.Context, userID uint32) ([]Preference, *api_errors.ErrorResponse) {
+	saved, err := s.repository.GetPreferences(ctx, userID)
+	if err != nil {
+		s.logger.Error("Error getting notification preferences: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get preferences",
+		}
+	}
+
+	disabled := map[string]bool{}
+	for _, preference := range saved {
+		disabled[preference.Event+"|"+preference.Channel] = !preference.Enabled
+	}
+
+	preferences := make([]Preference, 0, len(constants.NotificationEventList)*len(constants.NotificationChannelList))
+	for _, event := range constants.NotificationEventList {
+		for _, channel := range constants.NotificationChannelList {
+			preferences = append(
+				preferences, Preference{
+					Event:   string(event),
+					Channel: string(channel),
+					Enabled: !disabled[string(event)+"|"+string(channel)],
+				},
+			)
+		}
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences saves given preferences, others are kept
+func (s Service) UpdatePreferences(
+	ctx context.Context,
+	userID uint32,
+	preferences []Preference,
+) *api_errors.ErrorResponse {
+	records := make([]dao.NotificationPreference, 0, len(preferences))
+	for _, preference := range preferences {
+		if !slices.Contains(constants.NotificationEventList, constants.NotificationEvent(preference.Event)) {
+			return &api_errors.ErrorResponse{
+				ErrorType: api_errors.BadRequest,
+				Message:   fmt.Sprintf("Unknown event %q", preference.Event),
+			}
+		}
+		records = append(
+			records, dao.NotificationPreference{
+				UserID:  userID,
+				Event:   preference.Event,
+				Channel: preference.Channel,
+				Enabled: preference.Enabled,
+			},
+		)
+	}
+
+	if err := s.repository.SavePreferences(ctx, records); err != nil {
+		s.logger.Error("Error saving notification preferences: ", err.Error())
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to update preferences",
+		}
+	}
+	return nil
+}
+
+// RegisterDevice registers push notification token of the user's device
+func (s Service) RegisterDevice(
+	ctx context.Context,
+	userID uint32,
+	request RegisterDeviceRequest,
+) *api_errors.ErrorResponse {
+	err := s.repository.SaveDevice(
+		ctx,
+		&dao.UserDevice{
+			UserID:   userID,
+			Token:    request.Token,
+			Platform: request.Platform,
+		},
+	)
+	if err != nil {
+		s.logger.Error("Error registering device: ", err.Error())
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to register device",
+		}
+	}
+	return nil
+}
+
+// UnregisterDevice removes the token, e.g. on logout
+func (s Service) UnregisterDevice(ctx context.Context, userID uint32, token string) *api_errors.ErrorResponse {
+	if err := s.repository.DeleteDevice(ctx, userID, token); err != nil {
+		s.logger.Error("Error unregistering device: ", err.Error())
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to unregister device",
+		}
+	}
+	return nil
+}
+
+// enabledChannels channels the user has not disabled for the event
+func (s Service) enabledChannels(
+	ctx context.Context,
+	userID uint32,
+	event constants.NotificationEvent,
+) (map[constants.NotificationChannel]bool, error) {
+	preferences, err := s.repository.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := map[constants.NotificationChannel]bool{}
+	for _, channel := range constants.NotificationChannelList {
+		enabled[channel] = true
+	}
+	for _, preference := range preferences {
+		if preference.Event == string(event) {
+			enabled[constants.NotificationChannel(preference.Channel)] = preference.Enabled
+		}
+	}
+	return enabled, nil
+}
+
+// templates template of each channel in the locale, falls back to the default locale per channel
+func (s Service) templates(
+	ctx context.Context,
+	event constants.NotificationEvent,
+	locale string,
+) (map[constants.NotificationChannel]dao.NotificationTemplate, error) {
+	records, err := s.repository.GetTemplates(ctx, string(event), []string{locale, constants.DefaultLocale})
+	if err != nil {
+		return nil, err
+	}
+
+	templates := map[constants.NotificationChannel]dao.NotificationTemplate{}
+	for _, record := range records {
+		channel := constants.NotificationChannel(record.Channel)
+		if _, ok := templates[channel]; ok && record.Locale != locale {
+			continue
+		}
+		templates[channel] = record
+	}
+	return templates, nil
+}
+
+// render executes subject and body templates with the data
+func render(
+	event constants.NotificationEvent,
+	locale string,
+	tmpl dao.NotificationTemplate,
+	data map[string]interface{},
+) (Message, error) {
+	subject, err := execute(tmpl.Subject, data)
+	if err != nil {
+		return Message{}, err
+	}
+	body, err := execute(tmpl.Body, data)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Event:   event,
+		Subject: subject,
+		Body:    body,
+		Locale:  locale,
+		Data:    data,
+	}, nil
+}
+
+func execute(text string, data map[string]interface{}) (string, error) {
+	t, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"context"
+	"testing"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/tests"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testChannel records sent messages, templates are the events it has templates of when it renders its own
+type testChannel struct {
+	name      constants.NotificationChannel
+	templates []constants.NotificationEvent
+	sent      *[]Message
+}
+
+func (c testChannel) Name() constants.NotificationChannel {
+	return c.name
+}
+
+func (c testChannel) Reachable(Recipient) bool {
+	return true
+}
+
+func (c testChannel) Send(_ context.Context, _ Recipient, message Message) error {
+	*c.sent = append(*c.sent, message)
+	return nil
+}
+
+type testTemplatedChannel struct {
+	testChannel
+}
+
+func (c testTemplatedChannel) HasTemplate(event constants.NotificationEvent, _ string) bool {
+	for _, e := range c.templates {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func TestServiceNotifyTemplateSource(t *testing.T) {
+	db := tests.NewDatabase(t)
+	user := dao.User{FullName: "Taro", Email: "taro@example.com", Phone: "9800000000", Locale: "en"}
+	assert.NoError(t, db.Create(&user).Error)
+
+	var emails, pushes []Message
+	email := testTemplatedChannel{
+		testChannel{
+			name:      constants.NotificationChannels.Email,
+			templates: []constants.NotificationEvent{constants.NotificationEvents.PaymentFailed},
+			sent:      &emails,
+		},
+	}
+	push := testChannel{name: constants.NotificationChannels.Push, sent: &pushes}
+	service := NewService(config.GetLogger(), NewRepository(db, config.GetLogger()), []Channel{email, push})
+
+	ctx := context.Background()
+	channels := []constants.NotificationChannel{constants.NotificationChannels.Email, constants.NotificationChannels.Push}
+	assert.NoError(t, service.send(ctx, user.ID, constants.NotificationEvents.PaymentFailed, channels, nil))
+	if assert.Len(t, emails, 1, "email has no notification_templates row, its own template is used") {
+		assert.Empty(t, emails[0].Subject)
+		assert.Equal(t, "Taro", emails[0].Data["FullName"])
+	}
+	if assert.Len(t, pushes, 1) {
+		assert.Equal(t, "Payment failed", pushes[0].Subject)
+	}
+
+	assert.NoError(t, service.send(ctx, user.ID, constants.NotificationEvents.SubscriptionCanceled, channels, nil))
+	assert.Len(t, emails, 1, "email without template of the event is skipped")
+	assert.Len(t, pushes, 2)
+}
+
+func TestRender(t *testing.T) {
+	tmpl := dao.NotificationTemplate{
+		Subject: "Payment of {{.Amount}} failed",
+		Body:    "Hi {{.FullName}}, please update your card.",
+	}
+
+	message, err := render(
+		constants.NotificationEvents.PaymentFailed,
+		"en",
+		tmpl,
+		map[string]interface{}{"FullName": "Taro", "Amount": "1,000 JPY"},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "Payment of 1,000 JPY failed", message.Subject)
+	assert.Equal(t, "Hi Taro, please update your card.", message.Body)
+
+	_, err = render(constants.NotificationEvents.PaymentFailed, "en", tmpl, map[string]interface{}{"FullName": "Taro"})
+	assert.Error(t, err, "missing data must not send a half rendered message")
+}
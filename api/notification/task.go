@@ -0,0 +1,26 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/scheduler"
+)
+
+// NewPurgeStaleDevicesTask deletes push tokens the app didn't register again within the retention
+func NewPurgeStaleDevicesTask(logger config.Logger, repository Repository) scheduler.Task {
+	return scheduler.NewTask(
+		constants.TaskNames.PurgeStaleDevices,
+		"@daily",
+		func(ctx context.Context) error {
+			deleted, err := repository.DeleteStaleDevices(ctx, time.Now().Add(-constants.DeviceTokenRetention))
+			if err != nil {
+				return err
+			}
+			logger.Info("Purged ", deleted, " stale device tokens")
+			return nil
+		},
+	)
+}
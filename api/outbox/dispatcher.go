@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/utils"
+)
+
+// Dispatcher polls the outbox and hands due messages to the handler of their topic
+type Dispatcher struct {
+	logger     config.Logger
+	repository Repository
+	handlers   map[constants.OutboxTopic]Handler
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates new dispatcher, it is started from the bootstrap lifecycle
+func NewDispatcher(logger config.Logger, repository Repository, handlers []Handler) *Dispatcher {
+	dispatcher := &Dispatcher{
+		logger:     logger,
+		repository: repository,
+		handlers:   map[constants.OutboxTopic]Handler{},
+	}
+	for _, handler := range handlers {
+		dispatcher.handlers[handler.Topic()] = handler
+	}
+	return dispatcher
+}
+
+// Start polls the outbox in background until Stop is called
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.wg.Add(1)
+
+	go func() {
+		defer d.wg.Done()
+		d.logger.Info("✅ Outbox dispatcher started")
+
+		ticker := time.NewTicker(constants.OutboxPollInterval)
+		defer ticker.Stop()
+		for {
+			// a full batch means more messages are due, dispatch again without waiting
+			dispatched, err := d.Dispatch(ctx)
+			if err != nil {
+				d.logger.Error("Error dispatching outbox: ", err.Error())
+			}
+			if err == nil && dispatched == constants.OutboxBatchSize {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops polling and waits for the batch in flight, unfinished messages are reclaimed after the lease
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	if d.cancel == nil {
+		return nil
+	}
+	d.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		d.logger.Info("Outbox dispatcher stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dispatch claims one batch of due messages and delivers them, returns the number of claimed messages
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	messages, err := d.repository.Claim(ctx, time.Now(), constants.OutboxBatchSize, constants.OutboxLease)
+	if err != nil {
+		return 0, err
+	}
+	for _, message := range messages {
+		d.deliver(ctx, message)
+	}
+	return len(messages), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, message dao.OutboxMessage) {
+	// the outcome is recorded even when Stop cancels the batch, otherwise the message waits for the lease
+	recordCtx := context.WithoutCancel(ctx)
+
+	handler, ok := d.handlers[constants.OutboxTopic(message.Topic)]
+	if !ok {
+		// deployments can publish topics before every dispatcher knows them, so it is retried
+		d.failed(recordCtx, message, fmt.Errorf("no handler for topic %q", message.Topic))
+		return
+	}
+
+	if err := handler.Handle(ctx, []byte(message.Payload)); err != nil {
+		d.failed(recordCtx, message, err)
+		return
+	}
+	if err := d.repository.MarkSent(recordCtx, message.ID, time.Now()); err != nil {
+		// the message is delivered again after the lease
+		d.logger.Error("Error marking outbox message ", message.ID, " sent: ", err.Error())
+	}
+}
+
+// failed schedules the next attempt or dead-letters the message
+func (d *Dispatcher) failed(ctx context.Context, message dao.OutboxMessage, err error) {
+	status := constants.OutboxStatuses.Pending
+	availableAt := time.Now().Add(utils.Backoff(message.Attempts, constants.OutboxBaseBackoff, constants.OutboxMaxBackoff))
+	if message.Attempts >= message.MaxAttempts || errors.Is(err, ErrPermanent) {
+		status = constants.OutboxStatuses.Dead
+		d.logger.Error("Outbox message ", message.ID, " (", message.Topic, ") is dead: ", err.Error())
+	} else {
+		d.logger.Info("Outbox message ", message.ID, " failed, retrying at ", availableAt, ": ", err.Error())
+	}
+
+	if err := d.repository.MarkFailed(ctx, message.ID, status, availableAt, err.Error()); err != nil {
+		d.logger.Error("Error marking outbox message ", message.ID, " failed: ", err.Error())
+	}
+}
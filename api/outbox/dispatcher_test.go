@@ -0,0 +1,152 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/utils"
+	"boilerplate-api/tests"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// fakeHandler answers each payload with its error, nil delivers it
+type fakeHandler struct {
+	topic     constants.OutboxTopic
+	errors    map[string]error
+	delivered []string
+}
+
+func (h *fakeHandler) Topic() constants.OutboxTopic {
+	return h.topic
+}
+
+func (h *fakeHandler) Handle(_ context.Context, payload []byte) error {
+	if err := h.errors[string(payload)]; err != nil {
+		return err
+	}
+	h.delivered = append(h.delivered, string(payload))
+	return nil
+}
+
+func TestRepositoryClaimSkipLocked(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{})
+	assert.NoError(t, err)
+	repository := NewRepository(&config.Database{DB: db}, config.GetLogger())
+	now := time.Now()
+
+	// rows locked by another dispatcher are skipped instead of waited for
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \\* FROM `outbox_messages` WHERE status IN \\(\\?,\\?\\) AND available_at <= \\? ORDER BY available_at LIMIT \\? FOR UPDATE SKIP LOCKED").
+		WithArgs(constants.OutboxStatuses.Pending, constants.OutboxStatuses.Processing, now, 2).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "topic", "payload", "status", "attempts", "max_attempts"}).
+				AddRow(1, "notification", "{}", "pending", 0, 8).
+				AddRow(2, "notification", "{}", "processing", 3, 8),
+		)
+	mock.ExpectExec("UPDATE `outbox_messages` SET `attempts`=attempts \\+ 1,.* WHERE id IN \\(\\?,\\?\\)").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	messages, err := repository.Claim(context.Background(), now, 2, constants.OutboxLease)
+	assert.NoError(t, err)
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, string(constants.OutboxStatuses.Processing), messages[0].Status)
+		assert.EqualValues(t, 1, messages[0].Attempts)
+		assert.EqualValues(t, 4, messages[1].Attempts, "expired lease is claimed again")
+		assert.Equal(t, now.Add(constants.OutboxLease), messages[1].AvailableAt)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatcherDispatch(t *testing.T) {
+	ctx := context.Background()
+	logger := config.GetLogger()
+	db := tests.NewDatabase(t)
+	repository := NewRepository(db, logger)
+	handler := &fakeHandler{
+		topic: constants.OutboxTopics.Notification,
+		errors: map[string]error{
+			`"flaky"`:   errors.New("smtp unavailable"),
+			`"invalid"`: Permanent(errors.New("unknown template")),
+			`"last"`:    errors.New("smtp unavailable"),
+		},
+	}
+	dispatcher := NewDispatcher(logger, repository, []Handler{handler})
+
+	publish := func(topic constants.OutboxTopic, payload string, attempts uint32) uint64 {
+		message := dao.OutboxMessage{
+			Topic:       string(topic),
+			Payload:     payload,
+			Status:      string(constants.OutboxStatuses.Pending),
+			Attempts:    attempts,
+			MaxAttempts: constants.OutboxMaxAttempts,
+			AvailableAt: time.Now().Add(-time.Second),
+		}
+		assert.NoError(t, repository.Create(ctx, &message))
+		return message.ID
+	}
+	sent := publish(constants.OutboxTopics.Notification, `"welcome"`, 0)
+	flaky := publish(constants.OutboxTopics.Notification, `"flaky"`, 0)
+	invalid := publish(constants.OutboxTopics.Notification, `"invalid"`, 0)
+	last := publish(constants.OutboxTopics.Notification, `"last"`, constants.OutboxMaxAttempts-1)
+	unknown := publish("unknown", `"later"`, 0)
+
+	dispatched, err := dispatcher.Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, dispatched)
+	assert.Equal(t, []string{`"welcome"`}, handler.delivered)
+
+	get := func(id uint64) (message dao.OutboxMessage) {
+		assert.NoError(t, db.First(&message, id).Error)
+		return message
+	}
+
+	message := get(sent)
+	assert.Equal(t, string(constants.OutboxStatuses.Sent), message.Status)
+	assert.NotNil(t, message.SentAt)
+
+	// failed attempts are retried after the backoff of the attempt
+	for _, id := range []uint64{flaky, unknown} {
+		message = get(id)
+		assert.Equal(t, string(constants.OutboxStatuses.Pending), message.Status)
+		assert.EqualValues(t, 1, message.Attempts)
+		assert.NotNil(t, message.LastError)
+		backoff := utils.Backoff(1, constants.OutboxBaseBackoff, constants.OutboxMaxBackoff)
+		assert.WithinDuration(t, time.Now().Add(backoff), message.AvailableAt, 5*time.Second)
+	}
+	assert.Contains(t, *get(unknown).LastError, "no handler")
+
+	// permanent errors and the last attempt are dead-lettered
+	for _, id := range []uint64{invalid, last} {
+		assert.Equal(t, string(constants.OutboxStatuses.Dead), get(id).Status)
+	}
+	assert.Contains(t, *get(invalid).LastError, "unknown template")
+
+	dispatched, err = dispatcher.Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, dispatched, "nothing is due before the backoff")
+
+	// the retry from the admin view gives the dead message fresh attempts
+	retried, err := repository.Retry(ctx, last)
+	assert.NoError(t, err)
+	assert.True(t, retried)
+	delete(handler.errors, `"last"`)
+
+	dispatched, err = dispatcher.Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, dispatched)
+	message = get(last)
+	assert.Equal(t, string(constants.OutboxStatuses.Sent), message.Status)
+	assert.EqualValues(t, 1, message.Attempts)
+}
@@ -0,0 +1,25 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"boilerplate-api/lib/constants"
+)
+
+// ErrPermanent retrying can't succeed, e.g. invalid payload, the message is dead-lettered right away
+var ErrPermanent = errors.New("permanent failure")
+
+// Handler delivers messages of the topic, handlers are provided to the `group:"outbox_handlers"`
+//
+// messages are delivered at least once, Handle can be called again after it succeeded
+type Handler interface {
+	Topic() constants.OutboxTopic
+	Handle(ctx context.Context, payload []byte) error
+}
+
+// Permanent marks the error as not retryable
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
+}
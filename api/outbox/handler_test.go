@@ -0,0 +1,15 @@
+package outbox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermanent(t *testing.T) {
+	cause := errors.New("invalid payload")
+	err := Permanent(cause)
+	assert.ErrorIs(t, err, ErrPermanent)
+	assert.ErrorIs(t, err, cause)
+}
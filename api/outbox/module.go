@@ -0,0 +1,20 @@
+package outbox
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"outbox",
+	fx.Options(
+		fx.Provide(
+			NewRepository,
+			NewService,
+			fx.Annotate(
+				NewDispatcher,
+				fx.ParamTags(``, ``, `group:"outbox_handlers"`),
+			),
+			fx.Annotate(NewPurgeTask, fx.ResultTags(`group:"tasks"`)),
+		),
+	),
+)
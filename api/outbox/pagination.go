@@ -0,0 +1,23 @@
+package outbox
+
+import (
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Pagination struct {
+	utils.Pagination
+	// Status defaults to dead, failed deliveries are the ones worth looking at
+	Status constants.OutboxStatus `form:"status"`
+	Topic  string                 `form:"topic"`
+}
+
+// Build builds the pagination
+func (m *Pagination) Build(c *gin.Context) {
+	m.Pagination.Build(c)
+	if m.Status == "" {
+		m.Status = constants.OutboxStatuses.Dead
+	}
+}
@@ -0,0 +1,154 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository database structure
+type Repository struct {
+	db     *config.Database
+	logger config.Logger
+}
+
+// NewRepository creates a new outbox repository
+func NewRepository(db *config.Database, logger config.Logger) Repository {
+	return Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates the message, it is dispatched once the transaction commits
+func (r Repository) Create(ctx context.Context, message *dao.OutboxMessage) error {
+	return r.db.Conn(ctx).Create(message).Error
+}
+
+// Claim locks due messages and hides them from other dispatchers for the lease
+//
+// processing messages whose lease expired are claimed again, e.g. after a crash
+func (r Repository) Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) (messages []dao.OutboxMessage, err error) {
+	err = r.db.Conn(ctx).Transaction(
+		func(tx *gorm.DB) error {
+			err := tx.
+				Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status IN ?", []constants.OutboxStatus{constants.OutboxStatuses.Pending, constants.OutboxStatuses.Processing}).
+				Where("available_at <= ?", now).
+				Order("available_at").
+				Limit(limit).
+				Find(&messages).
+				Error
+			if err != nil || len(messages) == 0 {
+				return err
+			}
+
+			ids := make([]uint64, len(messages))
+			for i := range messages {
+				ids[i] = messages[i].ID
+				messages[i].Status = string(constants.OutboxStatuses.Processing)
+				messages[i].Attempts++
+				messages[i].AvailableAt = now.Add(lease)
+			}
+			return tx.Model(&dao.OutboxMessage{}).
+				Where("id IN ?", ids).
+				Updates(
+					map[string]interface{}{
+						"status":       constants.OutboxStatuses.Processing,
+						"attempts":     gorm.Expr("attempts + 1"),
+						"available_at": now.Add(lease),
+						"updated_at":   now,
+					},
+				).
+				Error
+		},
+	)
+	return messages, err
+}
+
+// MarkSent marks the message delivered
+func (r Repository) MarkSent(ctx context.Context, id uint64, now time.Time) error {
+	return r.db.Conn(ctx).Model(&dao.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(
+			map[string]interface{}{
+				"status":     constants.OutboxStatuses.Sent,
+				"sent_at":    now,
+				"last_error": nil,
+				"updated_at": now,
+			},
+		).
+		Error
+}
+
+// MarkFailed records the error, the message is retried at availableAt unless it is dead
+func (r Repository) MarkFailed(
+	ctx context.Context,
+	id uint64,
+	status constants.OutboxStatus,
+	availableAt time.Time,
+	lastError string,
+) error {
+	return r.db.Conn(ctx).Model(&dao.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(
+			map[string]interface{}{
+				"status":       status,
+				"available_at": availableAt,
+				"last_error":   lastError,
+				"updated_at":   time.Now(),
+			},
+		).
+		Error
+}
+
+// GetMessages messages with the status, newest first
+func (r Repository) GetMessages(ctx context.Context, pagination Pagination) (
+	messages []dao.OutboxMessage,
+	count int64,
+	err error,
+) {
+	queryBuilder := r.db.Conn(ctx).Model(&dao.OutboxMessage{}).Where("status = ?", pagination.Status)
+	if pagination.Topic != "" {
+		queryBuilder = queryBuilder.Where("topic = ?", pagination.Topic)
+	}
+
+	return messages, count, queryBuilder.
+		Count(&count).
+		Order("id desc").
+		Limit(pagination.PageSize).
+		Offset(pagination.Offset).
+		Find(&messages).
+		Error
+}
+
+// Retry queues dead message again with fresh attempts, returns false when no dead message has the id
+func (r Repository) Retry(ctx context.Context, id uint64) (bool, error) {
+	result := r.db.Conn(ctx).Model(&dao.OutboxMessage{}).
+		Where("id = ?", id).
+		Where("status = ?", constants.OutboxStatuses.Dead).
+		Updates(
+			map[string]interface{}{
+				"status":       constants.OutboxStatuses.Pending,
+				"attempts":     0,
+				"available_at": time.Now(),
+				"updated_at":   time.Now(),
+			},
+		)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteSent removes messages delivered before the time
+func (r Repository) DeleteSent(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.Conn(ctx).
+		Where("status = ?", constants.OutboxStatuses.Sent).
+		Where("sent_at < ?", before).
+		Delete(&dao.OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
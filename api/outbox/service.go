@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+)
+
+// Service publishes messages to the outbox and manages failed deliveries
+type Service struct {
+	logger     config.Logger
+	repository Repository
+}
+
+// NewService creates new outbox service
+func NewService(logger config.Logger, repository Repository) Service {
+	return Service{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Publish stores the payload as json, the dispatcher hands it to the handler of the topic
+//
+// it joins the transaction of the context, so the message is dropped on rollback
+func (s Service) Publish(ctx context.Context, topic constants.OutboxTopic, payload interface{}) error {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("outbox payload of %s: %w", topic, err)
+	}
+	return s.repository.Create(
+		ctx,
+		&dao.OutboxMessage{
+			Topic:       string(topic),
+			Payload:     string(content),
+			Status:      string(constants.OutboxStatuses.Pending),
+			MaxAttempts: constants.OutboxMaxAttempts,
+		},
+	)
+}
+
+// GetMessages messages of the status, dead letters by default
+func (s Service) GetMessages(ctx context.Context, pagination Pagination) ([]dao.OutboxMessage, int64, *api_errors.ErrorResponse) {
+	messages, count, err := s.repository.GetMessages(ctx, pagination)
+	if err != nil {
+		s.logger.Error("Error getting outbox messages: ", err.Error())
+		return nil, 0, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get outbox messages",
+		}
+	}
+	return messages, count, nil
+}
+
+// Retry dispatches dead message again
+func (s Service) Retry(ctx context.Context, id uint64) *api_errors.ErrorResponse {
+	retried, err := s.repository.Retry(ctx, id)
+	if err != nil {
+		s.logger.Error("Error retrying outbox message: ", err.Error())
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to retry outbox message",
+		}
+	}
+	if !retried {
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.NotFound,
+			Message:   "Dead outbox message not found",
+		}
+	}
+	return nil
+}
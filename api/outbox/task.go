@@ -0,0 +1,26 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/scheduler"
+)
+
+// NewPurgeTask deletes sent messages after the retention, dead ones are kept for the admin
+func NewPurgeTask(logger config.Logger, repository Repository) scheduler.Task {
+	return scheduler.NewTask(
+		constants.TaskNames.PurgeOutbox,
+		"@daily",
+		func(ctx context.Context) error {
+			deleted, err := repository.DeleteSent(ctx, time.Now().Add(-constants.OutboxRetention))
+			if err != nil {
+				return err
+			}
+			logger.Info("Purged ", deleted, " sent outbox messages")
+			return nil
+		},
+	)
+}
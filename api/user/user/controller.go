@@ -8,6 +8,7 @@ import (
 	"boilerplate-api/lib/constants"
 	"boilerplate-api/lib/json_response"
 	"boilerplate-api/lib/request_validator"
+	"boilerplate-api/lib/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -38,14 +39,17 @@ func NewController(
 // @Description	get user profile
 // @Security		Bearer
 // @Produce		application/json
-// @Success		200	{object}	json_response.Data[CUser]
-// @Failure		500	{object}	json_response.Error[string]
+// @Param			If-None-Match	header		string	false	"ETag of the cached profile"
+// @Success		200				{object}	json_response.Data[CUser]
+// @Header			200				{string}	ETag	"ETag of the profile"
+// @Success		304				{string}	string	"Not modified"
+// @Failure		500				{object}	json_response.Error[string]
 // @Router			/api/v1/profile [get]
 // @Id				GetUserProfile
 func (cc Controller) GetUserProfile(c *gin.Context) {
 	userID := fmt.Sprintf("%v", c.MustGet(constants.UserID))
 
-	user, err := cc.userService.GetOneUser(userID)
+	user, err := cc.userService.GetOneUser(c.Request.Context(), userID)
 	if err != nil {
 		cc.logger.Error("Error finding user profile", err.Error())
 		c.JSON(
@@ -57,8 +61,8 @@ func (cc Controller) GetUserProfile(c *gin.Context) {
 		return
 	}
 
-	c.JSON(
-		http.StatusOK, json_response.Data[CUser]{
+	utils.DataWithETag(
+		c, json_response.Data[CUser]{
 			Data: user,
 		},
 	)
@@ -11,6 +11,11 @@ type CUser struct {
 	dao.User
 }
 
+// GetVersion version of the row, the ETag of the user
+func (u CUser) GetVersion() uint32 {
+	return u.Version
+}
+
 // BeforeCreate Runs before inserting a row into table
 func (u *CUser) BeforeCreate(db *gorm.DB) error {
 	var Zap *zap.SugaredLogger
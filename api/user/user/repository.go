@@ -1,8 +1,9 @@
 package user
 
 import (
+	"context"
+
 	"boilerplate-api/lib/config"
-	"gorm.io/gorm"
 )
 
 // Repository database structure
@@ -19,18 +20,8 @@ func NewRepository(db *config.Database, logger config.Logger) Repository {
 	}
 }
 
-// WithTrx enables repository with transaction
-func (c Repository) WithTrx(trxHandle *gorm.DB) Repository {
-	if trxHandle == nil {
-		c.logger.Error("Transaction Database not found in gin context. ")
-		return c
-	}
-	c.db.DB = trxHandle
-	return c
-}
-
-func (c Repository) GetOneUser(Id string) (userModel CUser, err error) {
-	return userModel, c.db.DB.
+func (c Repository) GetOneUser(ctx context.Context, Id string) (userModel CUser, err error) {
+	return userModel, c.db.Conn(ctx).
 		Model(&userModel).
 		Where("id = ?", Id).
 		First(&userModel).
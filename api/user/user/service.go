@@ -1,7 +1,7 @@
 package user
 
 import (
-	"gorm.io/gorm"
+	"context"
 )
 
 type Service struct {
@@ -15,13 +15,7 @@ func NewService(repository Repository) Service {
 	}
 }
 
-// WithTrx repository with transaction
-func (c Service) WithTrx(trxHandle *gorm.DB) Service {
-	c.repository = c.repository.WithTrx(trxHandle)
-	return c
-}
-
 // GetOneUser one user
-func (c Service) GetOneUser(Id string) (CUser, error) {
-	return c.repository.GetOneUser(Id)
+func (c Service) GetOneUser(ctx context.Context, Id string) (CUser, error) {
+	return c.repository.GetOneUser(ctx, Id)
 }
@@ -1,10 +1,13 @@
 package utility
 
 import (
+	"errors"
 	"net/http"
 	"path/filepath"
 
+	"boilerplate-api/lib/api_errors"
 	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
 	"boilerplate-api/lib/json_response"
 	"boilerplate-api/lib/utils"
 	"boilerplate-api/services/aws"
@@ -12,47 +15,68 @@ import (
 )
 
 type Controller struct {
-	logger   config.Logger
-	s3Bucket aws.S3BucketService
-	service  Service
+	logger         config.Logger
+	s3Bucket       aws.S3BucketService
+	service        Service
+	validator      UploadValidator
+	uploadSessions UploadSessionService
+	imageProxy     ImageProxyService
 }
 
 func NewController(
 	logger config.Logger,
 	s3Bucket aws.S3BucketService,
 	service Service,
+	validator UploadValidator,
+	uploadSessions UploadSessionService,
+	imageProxy ImageProxyService,
 ) Controller {
 	return Controller{
-		logger:   logger,
-		s3Bucket: s3Bucket,
-		service:  service,
+		logger:         logger,
+		s3Bucket:       s3Bucket,
+		service:        service,
+		validator:      validator,
+		uploadSessions: uploadSessions,
+		imageProxy:     imageProxy,
 	}
 }
 
 //	@Tags			UtilityApi
-//	@Summary		handles file upload
-//	@Description	handles file upload
+//	@Summary		handles image upload
+//	@Description	handles image upload, the original is stored and its thumbnail is created in the background
 //	@Security		Bearer
 //	@Produce		application/json
 //	@Param			file	formData	file		true	"Upload File"
 //	@Success		200		{object}	Response	"File Uploaded Successfully"
 //	@Failure		400		{object}	json_response.Error[string]
-//	@Router			/api/v1/utils/file-upload [post]
+//	@Failure		413		{object}	json_response.Error[string]
+//	@Failure		415		{object}	json_response.Error[string]
+//	@Failure		422		{object}	json_response.Error[string]
+//	@Router			/api/v1/utils/files/upload [post]
 //	@Id				FileUpload
 func (uc Controller) FileUploadHandler(ctx *gin.Context) {
+	limitRequestBody(ctx, ImageUploadPolicy)
 	file, uploadFile, err := ctx.Request.FormFile("file")
 	if err != nil {
 		uc.logger.Error("Error Get File from request :: ", err.Error())
+		formFileError(ctx, err)
+		return
+	}
+
+	defer file.Close()
+
+	contentType, errResponse := uc.validator.Validate(ctx.Request.Context(), file, uploadFile, ImageUploadPolicy)
+	if errResponse != nil {
 		ctx.JSON(
-			http.StatusBadRequest, json_response.Error[string]{
-				Error:   err.Error(),
-				Message: "Failed to get file from request",
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Invalid file",
 			},
 		)
 		return
 	}
 
-	message, response, err := uc.service.UploadImage(file, uploadFile)
+	message, response, err := uc.service.UploadImage(ctx.Request.Context(), file, uploadFile, contentType)
 	if err != nil {
 		uc.logger.Error("Error Upload File from request :: ", err.Error())
 		ctx.JSON(
@@ -69,36 +93,87 @@ func (uc Controller) FileUploadHandler(ctx *gin.Context) {
 
 //	@Tags			UtilityApi
 //	@Summary		GetSignedUrl
-//	@Description	generate signed url
+//	@Description	generate signed url, redirects to the signed url when redirect=true
 //	@Security		Bearer
 //	@Produce		application/json
-//	@Param			image_url	query		string	false	"Image Url"
-//	@Success		200			{object}	json_response.Data[string]
-//	@Failure		400			{object}	json_response.Error[string]
+//	@Param			query			query		SignedURLQuery	false	"query param"
+//	@Param			If-None-Match	header		string			false	"ETag of the cached url"
+//	@Success		200				{object}	json_response.Data[string]
+//	@Header			200				{string}	ETag	"ETag of the url"
+//	@Success		304				{string}	string	"Not modified"
+//	@Success		302
+//	@Failure		400	{object}	json_response.Error[string]
+//	@Failure		500	{object}	json_response.Error[string]
 //	@Router			/api/v1/utils/images/signed_url [get]
 //	@Id				GetSignedUrl
 func (uc Controller) GetSignedUrl(ctx *gin.Context) {
-	imageUrl := ctx.Query("image_url")
-	if imageUrl == "" {
+	var query SignedURLQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
 		ctx.JSON(
 			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
 				Message: "Image Url is invalid",
 			},
 		)
+		return
 	}
 
-	signedUrl, err := uc.service.GetSignedUrl(imageUrl)
+	signedUrl, err := uc.service.GetSignedUrl(ctx.Request.Context(), query.ImageUrl, query.ToOptions())
 	if err != nil {
 		uc.logger.Error("Error Failed to convert signed url:", err.Error())
 		ctx.JSON(
-			http.StatusOK, json_response.Error[string]{
+			http.StatusInternalServerError, json_response.Error[string]{
+				Error:   err.Error(),
 				Message: "Error Failed to convert signed url",
 			},
 		)
 		return
 	}
 
-	ctx.Redirect(http.StatusFound, signedUrl)
+	if query.Redirect {
+		ctx.Redirect(http.StatusFound, signedUrl)
+		return
+	}
+
+	utils.DataWithETag(ctx, json_response.Data[string]{Data: signedUrl})
+}
+
+//	@Tags			UtilityApi
+//	@Summary		GetSignedUrls
+//	@Description	generate signed urls of multiple objects, keyed by object path
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			data	body		SignedURLsRequest	true	"Enter JSON"
+//	@Success		200		{object}	json_response.Data[map[string]string]
+//	@Failure		400		{object}	json_response.Error[string]
+//	@Failure		500		{object}	json_response.Error[string]
+//	@Router			/api/v1/utils/signed-urls [post]
+//	@Id				GetSignedUrls
+func (uc Controller) GetSignedUrls(ctx *gin.Context) {
+	var request SignedURLsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to bind signed url request",
+			},
+		)
+		return
+	}
+
+	signedUrls, err := uc.service.GetSignedUrls(ctx.Request.Context(), request.Objects, request.ToOptions())
+	if err != nil {
+		uc.logger.Error("Error Failed to convert signed urls:", err.Error())
+		ctx.JSON(
+			http.StatusInternalServerError, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Error Failed to convert signed urls",
+			},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, json_response.Data[map[string]string]{Data: signedUrls})
 }
 
 // Input model
@@ -108,25 +183,45 @@ type Input struct {
 
 // FileUploadS3Handler handles aws s3 file upload
 func (uc Controller) FileUploadS3Handler(ctx *gin.Context) {
+	limitRequestBody(ctx, FileUploadPolicy)
 	file, fileHeader, err := ctx.Request.FormFile("file")
 	if err != nil {
 		uc.logger.Error("Error Get File from request: ", err.Error())
+		formFileError(ctx, err)
+		return
+	}
+	defer file.Close()
+
+	var input Input
+	err = ctx.ShouldBind(&input)
+	if err != nil {
+		uc.logger.Error("Error Failed to bind input:: ", err.Error())
 		ctx.JSON(
 			http.StatusBadRequest, json_response.Error[string]{
 				Error:   err.Error(),
-				Message: "Failed to get file from request",
+				Message: "Failed to bind",
 			},
 		)
 		return
 	}
-	var input Input
-	err = ctx.ShouldBind(&input)
+
+	uploadPath, err := utils.SanitizeUploadPath(*input.Path)
 	if err != nil {
-		uc.logger.Error("Error Failed to bind input:: ", err.Error())
 		ctx.JSON(
 			http.StatusBadRequest, json_response.Error[string]{
 				Error:   err.Error(),
-				Message: "Failed to bind",
+				Message: "Invalid upload path",
+			},
+		)
+		return
+	}
+
+	contentType, errResponse := uc.validator.Validate(ctx.Request.Context(), file, fileHeader, FileUploadPolicy)
+	if errResponse != nil {
+		ctx.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Invalid file",
 			},
 		)
 		return
@@ -134,9 +229,9 @@ func (uc Controller) FileUploadS3Handler(ctx *gin.Context) {
 
 	fileExtension := filepath.Ext(fileHeader.Filename)
 	fileName := utils.GenerateRandomFileName() + fileExtension
-	originalFileNamePath := *input.Path + "/" + fileName
+	originalFileNamePath := uploadPath + "/" + fileName
 
-	uploadedFileURL, err := uc.s3Bucket.UploadToS3(file, fileHeader, originalFileNamePath)
+	uploadedFileURL, err := uc.s3Bucket.UploadToS3(ctx.Request.Context(), file, contentType, originalFileNamePath)
 	if err != nil {
 		uc.logger.Error("Error Failed to upload File:: ", err.Error())
 		ctx.JSON(
@@ -156,3 +251,25 @@ func (uc Controller) FileUploadS3Handler(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, response)
 }
+
+// limitRequestBody caps the request body so oversized uploads are cut off
+// before multipart parsing buffers them
+func limitRequestBody(ctx *gin.Context, policy UploadPolicy) {
+	// leave room for multipart boundaries and other form fields
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, policy.MaxSize+constants.MB)
+}
+
+// formFileError 413 when the body was cut off by limitRequestBody, 400 otherwise
+func formFileError(ctx *gin.Context, err error) {
+	status := http.StatusBadRequest
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		status = api_errors.PayloadTooLarge.ToInt()
+	}
+	ctx.JSON(
+		status, json_response.Error[string]{
+			Error:   err.Error(),
+			Message: "Failed to get file from request",
+		},
+	)
+}
@@ -0,0 +1,62 @@
+package utility
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileUploadHandlerTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	controller := Controller{logger: config.GetLogger()}
+	engine := gin.New()
+	engine.POST("/upload", controller.FileUploadHandler)
+	engine.POST("/s3-upload", controller.FileUploadS3Handler)
+
+	upload := func(path string, size int64) int {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "photo.png")
+		_, _ = part.Write(make([]byte, size))
+		_ = writer.Close()
+
+		request := httptest.NewRequest(http.MethodPost, path, body)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// the image endpoint is capped by ImageUploadPolicy, the s3 one by FileUploadPolicy
+	assert.Equal(t, http.StatusRequestEntityTooLarge, upload("/upload", ImageUploadPolicy.MaxSize+2*constants.MB))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, upload("/s3-upload", FileUploadPolicy.MaxSize+2*constants.MB))
+
+	request := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader([]byte("not multipart")))
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestGetImageProxyURLSizes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	controller := Controller{
+		logger:     config.GetLogger(),
+		imageProxy: ImageProxyService{signer: NewImageURLSigner(config.Env{ImageProxySecret: "secret"})},
+	}
+	engine := gin.New()
+	engine.GET("/proxy_url", controller.GetImageProxyURL)
+
+	for _, query := range []string{"w=300", "h=4000", "w=256&h=100"} {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/proxy_url?path=images/a.png&"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, "sizes outside the presets are rejected: %s", query)
+	}
+}
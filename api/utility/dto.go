@@ -0,0 +1,103 @@
+package utility
+
+import (
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/services"
+)
+
+// SignedURLQuery query params of the signed url request
+type SignedURLQuery struct {
+	ImageUrl    string               `form:"image_url" binding:"required"`
+	Storage     constants.Storage    `form:"storage" binding:"omitempty,oneof=gcs s3"`
+	ExpiresIn   int64                `form:"expires_in" binding:"omitempty,min=1,max=604800"` // seconds
+	Disposition services.Disposition `form:"disposition" binding:"omitempty,oneof=inline attachment"`
+	FileName    string               `form:"file_name"`
+	Redirect    bool                 `form:"redirect"`
+}
+
+// ToOptions converts query to signed url options
+func (q SignedURLQuery) ToOptions() services.SignedURLOptions {
+	return services.SignedURLOptions{
+		Storage:     q.Storage,
+		Expiry:      time.Duration(q.ExpiresIn) * time.Second,
+		Disposition: q.Disposition,
+		FileName:    q.FileName,
+	}
+}
+
+// SignedURLsRequest request to sign urls of multiple objects
+type SignedURLsRequest struct {
+	Objects     []string             `json:"objects" binding:"required,max=100"`
+	Storage     constants.Storage    `json:"storage" binding:"omitempty,oneof=gcs s3"`
+	ExpiresIn   int64                `json:"expires_in" binding:"omitempty,min=1,max=604800"` // seconds
+	Disposition services.Disposition `json:"disposition" binding:"omitempty,oneof=inline attachment"`
+}
+
+// ToOptions converts request to signed url options
+func (r SignedURLsRequest) ToOptions() services.SignedURLOptions {
+	return services.SignedURLOptions{
+		Storage:     r.Storage,
+		Expiry:      time.Duration(r.ExpiresIn) * time.Second,
+		Disposition: r.Disposition,
+	}
+}
+
+// ImageProxyURLQuery query params of the image proxy url request
+//
+// sizes are limited to presets so the number of derivatives of an image stays bounded
+type ImageProxyURLQuery struct {
+	Path    string            `form:"path" binding:"required"`
+	Width   uint              `form:"w" binding:"omitempty,oneof=64 128 256 512 1024 2048"`
+	Height  uint              `form:"h" binding:"omitempty,oneof=64 128 256 512 1024 2048"`
+	Fit     ImageFit          `form:"fit" binding:"omitempty,oneof=contain cover fill"`
+	Format  string            `form:"fmt" binding:"omitempty,oneof=png jpeg gif webp"`
+	Storage constants.Storage `form:"storage" binding:"omitempty,oneof=gcs s3"`
+}
+
+// ToParams converts query to transformation params, version is set by the service
+func (q ImageProxyURLQuery) ToParams() ImageTransformParams {
+	return ImageTransformParams{
+		Width:   q.Width,
+		Height:  q.Height,
+		Fit:     q.Fit,
+		Format:  q.Format,
+		Storage: q.Storage,
+	}
+}
+
+// CreateUploadSessionRequest request to initiate resumable upload
+type CreateUploadSessionRequest struct {
+	FileName string            `json:"file_name" binding:"required"`
+	Size     uint64            `json:"size" binding:"required"`
+	Path     string            `json:"path"`
+	Storage  constants.Storage `json:"storage"`
+}
+
+// UploadSessionResponse state of resumable upload
+type UploadSessionResponse struct {
+	ID        string    `json:"id"`
+	FileName  string    `json:"file_name"`
+	Size      uint64    `json:"size"`
+	Offset    uint64    `json:"offset"`
+	ChunkSize int64     `json:"chunk_size"`
+	Status    string    `json:"status"`
+	Location  *string   `json:"location"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewUploadSessionResponse maps upload session model to response
+func NewUploadSessionResponse(session dao.UploadSession) UploadSessionResponse {
+	return UploadSessionResponse{
+		ID:        session.ID,
+		FileName:  session.FileName,
+		Size:      session.Size,
+		Offset:    session.ReceivedBytes,
+		ChunkSize: constants.ChunkedUploadChunkSize,
+		Status:    session.Status,
+		Location:  session.Location,
+		ExpiresAt: session.ExpiresAt,
+	}
+}
@@ -0,0 +1,153 @@
+package utility
+
+import (
+	"net/http"
+
+	"boilerplate-api/lib/json_response"
+	"boilerplate-api/lib/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+//	@Tags			UtilityApi
+//	@Summary		Transform image
+//	@Description	resizes/converts stored image, params must be signed with IMAGE_PROXY_SECRET
+//	@Produce		image/png,image/jpeg,image/gif,image/webp
+//	@Param			path	path	string					true	"Object path"
+//	@Param			query	query	ImageTransformParams	false	"query param"
+//	@Success		200
+//	@Success		304
+//	@Failure		403	{object}	json_response.Error[string]
+//	@Failure		404	{object}	json_response.Error[string]
+//	@Router			/api/v1/images/{path} [get]
+//	@Id				TransformImage
+func (uc Controller) TransformImage(ctx *gin.Context) {
+	signer := uc.imageProxy.Signer()
+	if !signer.Enabled() {
+		ctx.JSON(
+			http.StatusNotFound, json_response.Error[string]{
+				Message: "Image proxy is not configured",
+			},
+		)
+		return
+	}
+
+	objectPath, err := utils.SanitizeUploadPath(ctx.Param("path"))
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Invalid image path",
+			},
+		)
+		return
+	}
+
+	var params ImageTransformParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Invalid transformation params",
+			},
+		)
+		return
+	}
+
+	if !signer.Verify(objectPath, params) {
+		ctx.JSON(
+			http.StatusForbidden, json_response.Error[string]{
+				Message: "Invalid signature",
+			},
+		)
+		return
+	}
+
+	// derivatives are content addressed by path, params and source version so versioned urls never change
+	etag := `"` + uc.imageProxy.DerivativeKey(objectPath, params) + `"`
+	ctx.Header("ETag", etag)
+	if params.Version != "" {
+		ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		ctx.Header("Cache-Control", "public, no-cache")
+	}
+	if ifNoneMatch := ctx.GetHeader("If-None-Match"); ifNoneMatch != "" && utils.ETagMatches(ifNoneMatch, etag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	transformed, errResponse := uc.imageProxy.Transform(ctx.Request.Context(), objectPath, params)
+	if errResponse != nil {
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to transform image",
+			},
+		)
+		return
+	}
+
+	ctx.Data(http.StatusOK, transformed.ContentType, transformed.Data)
+}
+
+//	@Tags			UtilityApi
+//	@Summary		Get image proxy url
+//	@Description	signed url of the image proxy, the url changes when the source image is overwritten, w and h are one of 64, 128, 256, 512, 1024 or 2048
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			query			query		ImageProxyURLQuery	false	"query param"
+//	@Param			If-None-Match	header		string				false	"ETag of the cached url"
+//	@Success		200				{object}	json_response.Data[string]
+//	@Header			200				{string}	ETag	"ETag of the url"
+//	@Success		304				{string}	string	"Not modified"
+//	@Failure		400				{object}	json_response.Error[string]
+//	@Failure		401				{object}	json_response.Error[string]
+//	@Failure		404				{object}	json_response.Error[string]
+//	@Router			/api/v1/utils/images/proxy_url [get]
+//	@Id				GetImageProxyURL
+func (uc Controller) GetImageProxyURL(ctx *gin.Context) {
+	if !uc.imageProxy.Signer().Enabled() {
+		ctx.JSON(
+			http.StatusNotFound, json_response.Error[string]{
+				Message: "Image proxy is not configured",
+			},
+		)
+		return
+	}
+
+	var query ImageProxyURLQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Invalid transformation params",
+			},
+		)
+		return
+	}
+
+	objectPath, err := utils.SanitizeUploadPath(query.Path)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Invalid image path",
+			},
+		)
+		return
+	}
+
+	signedURL, errResponse := uc.imageProxy.SignedURL(ctx.Request.Context(), objectPath, query.ToParams())
+	if errResponse != nil {
+		ctx.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to sign image url",
+			},
+		)
+		return
+	}
+
+	utils.DataWithETag(ctx, json_response.Data[string]{Data: signedURL})
+}
@@ -0,0 +1,293 @@
+package utility
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/utils"
+	"boilerplate-api/services"
+	"boilerplate-api/services/aws"
+)
+
+const (
+	// imageProxyMaxSourceSize originals larger than this are not transformed
+	imageProxyMaxSourceSize = 50 * constants.MB
+
+	// imageProxyMaxPixels guards against decompression bombs
+	imageProxyMaxPixels = 50_000_000
+
+	// derivedImagePath storage prefix of the derivative cache
+	derivedImagePath = "images/derived/"
+)
+
+// TransformedImage transformed image ready to be served
+type TransformedImage struct {
+	Data        []byte
+	ContentType string
+}
+
+// ImageProxyService resizes and converts stored images, results are cached in storage
+type ImageProxyService struct {
+	logger     config.Logger
+	signer     ImageURLSigner
+	bucket     GcpStorageBucketService
+	s3Bucket   aws.S3BucketService
+	signedURL  services.SignedURLService
+	httpClient *http.Client
+}
+
+// NewImageProxyService creates new image proxy service
+func NewImageProxyService(
+	logger config.Logger,
+	signer ImageURLSigner,
+	bucket GcpStorageBucketService,
+	s3Bucket aws.S3BucketService,
+	signedURL services.SignedURLService,
+) ImageProxyService {
+	return ImageProxyService{
+		logger:     logger,
+		signer:     signer,
+		bucket:     bucket,
+		s3Bucket:   s3Bucket,
+		signedURL:  signedURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Signer signer of the image proxy urls
+func (s ImageProxyService) Signer() ImageURLSigner {
+	return s.signer
+}
+
+// DerivativeKey content address of the derivative, also used as ETag
+//
+// params include the source version so an overwritten source gets a new key
+func (s ImageProxyService) DerivativeKey(objectPath string, params ImageTransformParams) string {
+	sum := sha256.Sum256([]byte(objectPath + "?" + params.Query().Encode()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Transform gets derivative from the cache or transforms the original and stores the result
+func (s ImageProxyService) Transform(
+	ctx context.Context,
+	objectPath string,
+	params ImageTransformParams,
+) (*TransformedImage, *api_errors.ErrorResponse) {
+	if params.Storage == "" {
+		params.Storage = constants.Storages.GCS
+	}
+	derivativeName := derivedImagePath + s.DerivativeKey(objectPath, params)
+
+	cached, _, err := s.fetch(ctx, params.Storage, derivativeName)
+	if err != nil {
+		// cache read failure should not block serving the image
+		s.logger.Error("Error reading image derivative: ", err.Error())
+	}
+	if cached != nil {
+		contentType, _ := utils.DetectContentType(bytes.NewReader(cached))
+		return &TransformedImage{Data: cached, ContentType: contentType}, nil
+	}
+
+	original, version, err := s.fetch(ctx, params.Storage, objectPath)
+	if err != nil {
+		s.logger.Error("Error fetching original image: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to fetch image",
+		}
+	}
+	if original == nil {
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.NotFound,
+			Message:   "Image not found",
+		}
+	}
+	// the source was overwritten after the url was signed, its derivative would be cached under the old version
+	if params.Version != "" && params.Version != version {
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.NotFound,
+			Message:   "Image version not found",
+		}
+	}
+
+	transformed, errResponse := s.transform(original, params)
+	if errResponse != nil {
+		return nil, errResponse
+	}
+
+	switch params.Storage {
+	case constants.Storages.S3:
+		_, err = s.s3Bucket.UploadFile(ctx, bytes.NewReader(transformed.Data), derivativeName, transformed.ContentType)
+	default:
+		_, err = s.bucket.UploadFile(ctx, bytes.NewReader(transformed.Data), derivativeName)
+	}
+	if err != nil {
+		s.logger.Error("Error storing image derivative: ", err.Error())
+	}
+
+	return transformed, nil
+}
+
+func (s ImageProxyService) transform(
+	original []byte,
+	params ImageTransformParams,
+) (*TransformedImage, *api_errors.ErrorResponse) {
+	source := bytes.NewReader(original)
+	sourceType, err := utils.DetectContentType(source)
+	if err != nil || !slices.Contains(constants.ImageContentTypes, sourceType) {
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.UnsupportedMediaType,
+			Message:   "Object is not a supported image",
+		}
+	}
+
+	imageConfig, _, err := image.DecodeConfig(source)
+	if err != nil || imageConfig.Width*imageConfig.Height > imageProxyMaxPixels {
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.UnprocessableEntity,
+			Message:   "Image is too large to transform",
+		}
+	}
+
+	img, err := utils.DecodeImage(source, sourceType)
+	if err != nil {
+		s.logger.Error("Error decoding image: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.UnprocessableEntity,
+			Message:   "Failed to decode image",
+		}
+	}
+
+	targetType := sourceType
+	if params.Format != "" {
+		targetType = imageFormats[params.Format]
+	}
+
+	encoded, err := utils.EncodeImage(TransformImage(img, params.Width, params.Height, params.Fit), targetType)
+	if err != nil {
+		s.logger.Error("Error encoding image: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to encode image",
+		}
+	}
+
+	return &TransformedImage{Data: encoded.Bytes(), ContentType: targetType}, nil
+}
+
+// SignedURL signed proxy url of the object, the url carries the current version of the source
+func (s ImageProxyService) SignedURL(
+	ctx context.Context,
+	objectPath string,
+	params ImageTransformParams,
+) (string, *api_errors.ErrorResponse) {
+	if params.Storage == "" {
+		params.Storage = constants.Storages.GCS
+	}
+
+	response, err := s.open(ctx, params.Storage, objectPath, "bytes=0-0")
+	if err != nil {
+		s.logger.Error("Error getting image version: ", err.Error())
+		return "", &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get image",
+		}
+	}
+	if response == nil {
+		return "", &api_errors.ErrorResponse{
+			ErrorType: api_errors.NotFound,
+			Message:   "Image not found",
+		}
+	}
+	response.Body.Close()
+
+	params.Version = objectVersion(response)
+	return s.signer.SignedURL(objectPath, params), nil
+}
+
+// Get downloads the object, nil data is returned when the object doesn't exist
+func (s ImageProxyService) Get(ctx context.Context, storage constants.Storage, object string) ([]byte, error) {
+	data, _, err := s.fetch(ctx, storage, object)
+	return data, err
+}
+
+// fetch downloads object through a signed url with its version, nil data is returned when the object doesn't exist
+func (s ImageProxyService) fetch(
+	ctx context.Context,
+	storage constants.Storage,
+	object string,
+) ([]byte, string, error) {
+	response, err := s.open(ctx, storage, object, "")
+	if err != nil || response == nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, imageProxyMaxSourceSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > imageProxyMaxSourceSize {
+		return nil, "", fmt.Errorf("object exceeds %d bytes", imageProxyMaxSourceSize)
+	}
+	return data, objectVersion(response), nil
+}
+
+// open requests object through a signed url, byteRange is sent as Range header when not empty
+//
+// nil response is returned when the object doesn't exist
+func (s ImageProxyService) open(
+	ctx context.Context,
+	storage constants.Storage,
+	object string,
+	byteRange string,
+) (*http.Response, error) {
+	signedURL, err := s.signedURL.Sign(ctx, object, services.SignedURLOptions{Storage: storage})
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, signedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if byteRange != "" {
+		request.Header.Set("Range", byteRange)
+	}
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		return response, nil
+	case http.StatusNotFound:
+		response.Body.Close()
+		return nil, nil
+	default:
+		response.Body.Close()
+		return nil, fmt.Errorf("unexpected storage response: %s", response.Status)
+	}
+}
+
+// objectVersion GCS generation of the object, s3 has no generation so its etag is used
+func objectVersion(response *http.Response) string {
+	if generation := response.Header.Get("X-Goog-Generation"); generation != "" {
+		return generation
+	}
+	return strings.Trim(response.Header.Get("ETag"), `"`)
+}
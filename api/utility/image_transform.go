@@ -0,0 +1,148 @@
+package utility
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/draw"
+	"net/url"
+	"strconv"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"github.com/nfnt/resize"
+)
+
+// ImageFit how the image is fitted into the requested box
+type ImageFit string
+
+var ImageFits = struct {
+	Contain ImageFit
+	Cover   ImageFit
+	Fill    ImageFit
+}{
+	Contain: "contain",
+	Cover:   "cover",
+	Fill:    "fill",
+}
+
+// imageFormats fmt query value => content type
+var imageFormats = map[string]string{
+	"png":  "image/png",
+	"jpeg": "image/jpeg",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+}
+
+// ImageTransformParams query params of the image proxy, `s` is the HMAC signature
+//
+// `v` is the generation or etag of the source object, overwriting the source changes the url and the derivative
+type ImageTransformParams struct {
+	Width     uint              `form:"w" binding:"omitempty,max=4000"`
+	Height    uint              `form:"h" binding:"omitempty,max=4000"`
+	Fit       ImageFit          `form:"fit" binding:"omitempty,oneof=contain cover fill"`
+	Format    string            `form:"fmt" binding:"omitempty,oneof=png jpeg gif webp"`
+	Storage   constants.Storage `form:"storage" binding:"omitempty,oneof=gcs s3"`
+	Version   string            `form:"v"`
+	Signature string            `form:"s"`
+}
+
+// Query canonical query string of the params without signature, keys are sorted
+func (p ImageTransformParams) Query() url.Values {
+	query := url.Values{}
+	if p.Width > 0 {
+		query.Set("w", strconv.FormatUint(uint64(p.Width), 10))
+	}
+	if p.Height > 0 {
+		query.Set("h", strconv.FormatUint(uint64(p.Height), 10))
+	}
+	if p.Fit != "" {
+		query.Set("fit", string(p.Fit))
+	}
+	if p.Format != "" {
+		query.Set("fmt", p.Format)
+	}
+	if p.Storage != "" {
+		query.Set("storage", p.Storage.ToString())
+	}
+	if p.Version != "" {
+		query.Set("v", p.Version)
+	}
+	return query
+}
+
+// ImageURLSigner signs image proxy urls so only urls generated by the backend are served
+type ImageURLSigner struct {
+	secret []byte
+}
+
+// NewImageURLSigner creates new image url signer
+func NewImageURLSigner(env config.Env) ImageURLSigner {
+	return ImageURLSigner{secret: []byte(env.ImageProxySecret)}
+}
+
+// Enabled signing secret is configured
+func (s ImageURLSigner) Enabled() bool {
+	return len(s.secret) > 0
+}
+
+// Sign signature of the object path and params
+func (s ImageURLSigner) Sign(objectPath string, params ImageTransformParams) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(objectPath + "?" + params.Query().Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks params signature in constant time
+func (s ImageURLSigner) Verify(objectPath string, params ImageTransformParams) bool {
+	signature, err := hex.DecodeString(params.Signature)
+	if err != nil || !s.Enabled() {
+		return false
+	}
+	expected, _ := hex.DecodeString(s.Sign(objectPath, params))
+	return hmac.Equal(signature, expected)
+}
+
+// SignedURL relative proxy url of the object with transformation params
+func (s ImageURLSigner) SignedURL(objectPath string, params ImageTransformParams) string {
+	query := params.Query()
+	query.Set("s", s.Sign(objectPath, params))
+	return "/api/v1/images/" + objectPath + "?" + query.Encode()
+}
+
+// TransformImage resizes image into width x height box with the given fit
+//
+// zero width or height keeps the aspect ratio
+func TransformImage(img image.Image, width, height uint, fit ImageFit) image.Image {
+	if width == 0 && height == 0 {
+		return img
+	}
+	if width == 0 || height == 0 || fit == ImageFits.Fill {
+		return resize.Resize(width, height, img, resize.Lanczos3)
+	}
+	if fit != ImageFits.Cover {
+		return resize.Thumbnail(width, height, img, resize.Lanczos3)
+	}
+
+	// cover: scale to fill the box then crop the center
+	bounds := img.Bounds()
+	scaleX := float64(width) / float64(bounds.Dx())
+	scaleY := float64(height) / float64(bounds.Dy())
+	var resized image.Image
+	if scaleX > scaleY {
+		resized = resize.Resize(width, 0, img, resize.Lanczos3)
+	} else {
+		resized = resize.Resize(0, height, img, resize.Lanczos3)
+	}
+
+	resizedBounds := resized.Bounds()
+	offset := image.Pt(
+		resizedBounds.Min.X+(resizedBounds.Dx()-int(width))/2,
+		resizedBounds.Min.Y+(resizedBounds.Dy()-int(height))/2,
+	)
+	cropped := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	draw.Draw(cropped, cropped.Bounds(), resized, offset, draw.Src)
+	return cropped
+}
@@ -0,0 +1,73 @@
+package utility
+
+import (
+	"image"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageURLSigner(t *testing.T) {
+	signer := ImageURLSigner{secret: []byte("secret")}
+	params := ImageTransformParams{Width: 200, Height: 100, Fit: ImageFits.Cover, Format: "webp"}
+
+	signedURL := signer.SignedURL("images/original/1.png", params)
+	assert.True(t, strings.HasPrefix(signedURL, "/api/v1/images/images/original/1.png?"))
+
+	parsed, err := url.Parse(signedURL)
+	assert.NoError(t, err)
+	params.Signature = parsed.Query().Get("s")
+	assert.True(t, signer.Verify("images/original/1.png", params))
+
+	tampered := params
+	tampered.Width = 4000
+	assert.False(t, signer.Verify("images/original/1.png", tampered))
+	assert.False(t, signer.Verify("images/original/2.png", params))
+
+	assert.False(t, ImageURLSigner{}.Verify("images/original/1.png", params), "disabled without secret")
+}
+
+func TestImageURLSignerVersion(t *testing.T) {
+	signer := ImageURLSigner{secret: []byte("secret")}
+	proxy := ImageProxyService{signer: signer}
+	params := ImageTransformParams{Width: 200, Version: "1"}
+
+	parsed, err := url.Parse(signer.SignedURL("images/original/1.png", params))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", parsed.Query().Get("v"))
+	params.Signature = parsed.Query().Get("s")
+	assert.True(t, signer.Verify("images/original/1.png", params))
+
+	// the source was overwritten, the old url is not valid for the new version and the derivative key changes
+	overwritten := params
+	overwritten.Version = "2"
+	assert.False(t, signer.Verify("images/original/1.png", overwritten))
+	assert.NotEqual(
+		t,
+		proxy.DerivativeKey("images/original/1.png", params),
+		proxy.DerivativeKey("images/original/1.png", overwritten),
+	)
+}
+
+func TestTransformImage(t *testing.T) {
+	source := image.NewRGBA(image.Rect(0, 0, 400, 200))
+
+	cases := []struct {
+		fit           ImageFit
+		width, height uint
+		expected      image.Point
+	}{
+		{ImageFits.Contain, 100, 100, image.Pt(100, 50)},
+		{ImageFits.Cover, 100, 100, image.Pt(100, 100)},
+		{ImageFits.Fill, 100, 100, image.Pt(100, 100)},
+		{ImageFits.Contain, 100, 0, image.Pt(100, 50)},
+		{ImageFits.Contain, 0, 0, image.Pt(400, 200)},
+	}
+
+	for _, c := range cases {
+		transformed := TransformImage(source, c.width, c.height, c.fit)
+		assert.Equal(t, c.expected, transformed.Bounds().Size(), c.fit)
+	}
+}
@@ -0,0 +1,30 @@
+package utility
+
+import (
+	"context"
+	"errors"
+
+	"boilerplate-api/jobs"
+	"boilerplate-api/lib/constants"
+)
+
+// CreateThumbnailPayload payload of the create thumbnail job
+type CreateThumbnailPayload struct {
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+}
+
+// NewCreateThumbnailJob resizes the uploaded image into images/thumbnail, queued by UploadImage
+func NewCreateThumbnailJob(service Service) jobs.Handler {
+	return jobs.NewHandler(
+		constants.JobNames.CreateThumbnail,
+		func(ctx context.Context, payload CreateThumbnailPayload) error {
+			if payload.FileName == "" || payload.ContentType == "" {
+				return jobs.Permanent(errors.New("file name and content type are required"))
+			}
+			return service.CreateThumbnail(ctx, payload.FileName, payload.ContentType)
+		},
+		// decoding and resizing holds the whole image in memory
+		jobs.WithConcurrency(2),
+	)
+}
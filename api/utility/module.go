@@ -7,8 +7,15 @@ import (
 var Module = fx.Module(
 	"utility",
 	fx.Options(
+		fx.Provide(NewRepository),
 		fx.Provide(NewService),
+		fx.Provide(NewUploadValidator),
+		fx.Provide(NewUploadSessionService),
+		fx.Provide(NewImageURLSigner),
+		fx.Provide(NewImageProxyService),
 		fx.Provide(NewController),
+		fx.Provide(fx.Annotate(NewCleanupUploadSessionsTask, fx.ResultTags(`group:"tasks"`))),
+		fx.Provide(fx.Annotate(NewCreateThumbnailJob, fx.ResultTags(`group:"jobs"`))),
 		fx.Invoke(SetupRoutes),
 	),
 )
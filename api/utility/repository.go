@@ -0,0 +1,68 @@
+package utility
+
+import (
+	"context"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+)
+
+// Repository database structure
+type Repository struct {
+	db     *config.Database
+	logger config.Logger
+}
+
+// NewRepository creates a new utility repository
+func NewRepository(db *config.Database, logger config.Logger) Repository {
+	return Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateUploadSession creates resumable upload session
+func (r Repository) CreateUploadSession(ctx context.Context, session *dao.UploadSession) error {
+	return r.db.Conn(ctx).Create(session).Error
+}
+
+// GetUploadSession gets upload session by id
+func (r Repository) GetUploadSession(ctx context.Context, id string) (session dao.UploadSession, err error) {
+	return session, r.db.Conn(ctx).
+		Where("id = ?", id).
+		First(&session).
+		Error
+}
+
+// AdvanceUploadSession moves received bytes forward only if nobody else did it first
+func (r Repository) AdvanceUploadSession(ctx context.Context, id string, from, to uint64) (bool, error) {
+	query := r.db.Conn(ctx).Model(&dao.UploadSession{}).
+		Where("id = ? AND received_bytes = ? AND status = ?", id, from, constants.UploadSessionStatuses.Pending).
+		Updates(map[string]interface{}{
+			"received_bytes": to,
+			"updated_at":     time.Now(),
+		})
+	return query.RowsAffected == 1, query.Error
+}
+
+// UpdateUploadSession updates the given columns of the upload session
+func (r Repository) UpdateUploadSession(ctx context.Context, id string, values map[string]interface{}) error {
+	values["updated_at"] = time.Now()
+	return r.db.Conn(ctx).Model(&dao.UploadSession{}).
+		Where("id = ?", id).
+		Updates(values).
+		Error
+}
+
+// GetExpiredUploadSessions pending sessions which expired before given time
+func (r Repository) GetExpiredUploadSessions(ctx context.Context, before time.Time) (
+	sessions []dao.UploadSession,
+	err error,
+) {
+	return sessions, r.db.Conn(ctx).
+		Where("status = ? AND expires_at < ?", constants.UploadSessionStatuses.Pending, before).
+		Find(&sessions).
+		Error
+}
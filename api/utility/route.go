@@ -1,6 +1,8 @@
 package utility
 
 import (
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/middlewares"
 	"boilerplate-api/lib/router"
 )
 
@@ -8,11 +10,27 @@ import (
 func SetupRoutes(
 	router router.Router,
 	utilityController Controller,
+	timeoutMiddleware middlewares.TimeoutMiddleware,
+	jwtMiddleware middlewares.JWTAuthMiddleWare,
 ) {
+	uploadTimeout := timeoutMiddleware.HandleTimeout(constants.UploadTimeout)
+
 	utils := router.V1.Group("/utils")
 	{
-		utils.POST("/files/upload", utilityController.FileUploadHandler)
+		utils.POST("/files/upload", uploadTimeout, utilityController.FileUploadHandler)
 		utils.GET("/images/signed_url", utilityController.GetSignedUrl)
-		utils.POST("/s3-file-upload", utilityController.FileUploadS3Handler)
+		utils.GET("/images/proxy_url", jwtMiddleware.Handle(), utilityController.GetImageProxyURL)
+		utils.POST("/signed-urls", utilityController.GetSignedUrls)
+		utils.POST("/s3-file-upload", uploadTimeout, utilityController.FileUploadS3Handler)
+
+		uploads := utils.Group("/files/uploads").Use(jwtMiddleware.Handle())
+		uploads.POST("", utilityController.CreateUploadSession)
+		uploads.GET("/:id", utilityController.GetUploadSession)
+		uploads.HEAD("/:id", utilityController.GetUploadSession)
+		uploads.PATCH("/:id", uploadTimeout, utilityController.UploadChunk)
+		uploads.POST("/:id/complete", uploadTimeout, utilityController.CompleteUploadSession)
+		uploads.DELETE("/:id", utilityController.AbortUploadSession)
 	}
+
+	router.V1.GET("/images/*path", utilityController.TransformImage)
 }
@@ -1,15 +1,26 @@
 package utility
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"path/filepath"
+	"strings"
 
+	"boilerplate-api/jobs"
 	"boilerplate-api/lib/config"
 	"boilerplate-api/lib/constants"
 	"boilerplate-api/lib/utils"
+	"boilerplate-api/services"
+)
+
+const (
+	originalImagePath  = "images/original/"
+	thumbnailImagePath = "images/thumbnail/"
+	thumbnailWidth     = 200
 )
 
 type GcpStorageBucketService interface {
@@ -31,128 +42,134 @@ type Response struct {
 }
 
 type Service struct {
-	logger config.Logger
-	env    config.Env
-	bucket GcpStorageBucketService
+	logger     config.Logger
+	env        config.Env
+	bucket     GcpStorageBucketService
+	signedURL  services.SignedURLService
+	imageProxy ImageProxyService
+	jobs       jobs.Client
 }
 
 func NewService(
 	logger config.Logger,
 	env config.Env,
 	bucket GcpStorageBucketService,
+	signedURL services.SignedURLService,
+	imageProxy ImageProxyService,
+	client jobs.Client,
 ) Service {
 	return Service{
-		logger: logger,
-		env:    env,
-		bucket: bucket,
+		logger:     logger,
+		env:        env,
+		bucket:     bucket,
+		signedURL:  signedURL,
+		imageProxy: imageProxy,
+		jobs:       client,
 	}
 }
 
-func (s Service) UploadImage(file multipart.File, uploadFile *multipart.FileHeader) (UploadResponse, *Response, error) {
-	fileType := uploadFile.Header.Get("Content-Type")
+// UploadImage uploads image to the bucket, thumbnail is created by a job
+//
+// the thumbnail path is returned right away, the object exists once the job has run.
+//
+// fileType must be the server side detected content type, validated with ImageUploadPolicy
+func (s Service) UploadImage(
+	ctx context.Context,
+	file multipart.File,
+	uploadFile *multipart.FileHeader,
+	fileType string,
+) (UploadResponse, *Response, error) {
 	fileExtension := filepath.Ext(uploadFile.Filename)
 	fileName := utils.GenerateRandomFileName() + fileExtension
 
-	originalFileName := "images/original/" + fileName
-	switch fileType {
-	case "image/png",
-		"image/jpg",
-		"image/jpeg",
-		"image/gif",
-		"image/webp":
-		{
-			uploadedOriginalURL, errs := s.bucket.UploadFile(context.Background(), file, originalFileName)
-			if errs != nil {
-				s.logger.Error("Error Failed to upload File::", errs.Error())
-				return UploadResponse{
-					StatusCode: http.StatusBadRequest,
-					Message:    "Failed to upload File",
-				}, nil, errs
-			}
-
-			//upload thumbnail
-			thumbnailFileName := "images/thumbnail/" + fileName
-
-			thumbnailImg, err := utils.CreateThumbnail(file, fileType, 200, 0)
-			if err != nil {
-				s.logger.Error("Error Failed create thumbnail", err.Error())
-				return UploadResponse{
-					Message:    "Failed to create thumbnail",
-					StatusCode: http.StatusBadRequest,
-				}, nil, err
-			}
-
-			uploadThumbnailUrl, err := s.bucket.UploadFile(context.Background(), thumbnailImg, thumbnailFileName)
-			if err != nil {
-				s.logger.Error("Error Failed to upload File::", err.Error())
-				return UploadResponse{
-					Message:    "Failed to upload thumbnail File",
-					StatusCode: http.StatusBadRequest,
-				}, nil, err
-			}
-
-			signedURL, err := utils.GetObjectSignedURL(s.env.StorageBucketName, uploadedOriginalURL)
-			if err != nil {
-				s.logger.Error("Error Failed to convert signed url:", err.Error())
-				return UploadResponse{
-					Message:    "Failed to convert signed url",
-					StatusCode: http.StatusBadRequest,
-				}, nil, err
-			}
-
-			return UploadResponse{
-					Message:    "Uploaded Successfully",
-					StatusCode: http.StatusOK,
-				}, &Response{
-					Success: true,
-					Message: "Uploaded Successfully",
-					Data:    signedURL,
-					Path:    uploadedOriginalURL,
-					Value: map[string]string{
-						"original_image_url":   constants.STORAGE_URL + s.env.StorageBucketName + uploadedOriginalURL,
-						"original_image_path":  uploadedOriginalURL,
-						"thumbnail_image_url":  constants.STORAGE_URL + s.env.StorageBucketName + uploadThumbnailUrl,
-						"thumbnail_image_path": uploadThumbnailUrl,
-					},
-				}, nil
-		}
-	default:
-		originalFileName = "files/" + fileName
-		uploadedFileURL, err := s.bucket.UploadFile(context.Background(), file, originalFileName)
-		if err != nil {
-			s.logger.Error("Error Failed to upload File::", err.Error())
-			return UploadResponse{
-				StatusCode: http.StatusBadRequest,
-				Message:    "Failed to upload File",
-			}, nil, err
-		}
-
-		response := &Response{
-			Success: true,
-			Message: "Uploaded Successfully",
-			Data:    constants.STORAGE_URL + s.env.StorageBucketName + "/" + uploadedFileURL,
-			Path:    uploadedFileURL,
-		}
+	originalFileName := originalImagePath + fileName
+	uploadedOriginalURL, err := s.bucket.UploadFile(ctx, file, originalFileName)
+	if err != nil {
+		s.logger.Error("Error Failed to upload File::", err.Error())
+		return UploadResponse{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Failed to upload File",
+		}, nil, err
+	}
+
+	_, err = s.jobs.Enqueue(
+		ctx,
+		constants.JobNames.CreateThumbnail,
+		CreateThumbnailPayload{FileName: fileName, ContentType: fileType},
+	)
+	if err != nil {
+		s.logger.Error("Error Failed to queue thumbnail: ", err.Error())
+		return UploadResponse{
+			Message:    "Failed to create thumbnail",
+			StatusCode: http.StatusInternalServerError,
+		}, nil, err
+	}
+	uploadThumbnailUrl := strings.Replace(uploadedOriginalURL, originalFileName, thumbnailImagePath+fileName, 1)
 
+	signedURL, err := s.signedURL.Sign(ctx, uploadedOriginalURL, services.SignedURLOptions{})
+	if err != nil {
+		s.logger.Error("Error Failed to convert signed url:", err.Error())
 		return UploadResponse{
-			StatusCode: http.StatusOK,
-			Message:    "Uploaded Successfully",
-		}, response, nil
+			Message:    "Failed to convert signed url",
+			StatusCode: http.StatusBadRequest,
+		}, nil, err
 	}
+
+	return UploadResponse{
+		Message:    "Uploaded Successfully",
+		StatusCode: http.StatusOK,
+	}, &Response{
+		Success: true,
+		Message: "Uploaded Successfully",
+		Data:    signedURL,
+		Path:    uploadedOriginalURL,
+		Value: map[string]string{
+			"original_image_url":   constants.STORAGE_URL + s.env.StorageBucketName + uploadedOriginalURL,
+			"original_image_path":  uploadedOriginalURL,
+			"thumbnail_image_url":  constants.STORAGE_URL + s.env.StorageBucketName + uploadThumbnailUrl,
+			"thumbnail_image_path": uploadThumbnailUrl,
+		},
+	}, nil
 }
 
-// GetSignedUrl generates a signed URL for accessing the specified image in the storage bucket.
-// It logs an error and returns an empty string if the URL conversion fails.
+// CreateThumbnail resizes the original image of the file name and stores it in images/thumbnail
+//
+// fileType must be the content type detected when the original was uploaded
+func (s Service) CreateThumbnail(ctx context.Context, fileName string, fileType string) error {
+	original, err := s.imageProxy.Get(ctx, constants.Storages.GCS, originalImagePath+fileName)
+	if err != nil {
+		return err
+	}
+	if original == nil {
+		return jobs.Permanent(errors.New("original image not found: " + fileName))
+	}
+
+	thumbnail, err := utils.CreateThumbnail(bytes.NewReader(original), fileType, thumbnailWidth, 0)
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+
+	_, err = s.bucket.UploadFile(ctx, thumbnail, thumbnailImagePath+fileName)
+	return err
+}
+
+// GetSignedUrl generates a signed URL for accessing the specified object in the storage bucket.
 //
 // Parameters:
-//   - imageUrl: The path of the image for which the signed URL is to be generated.
+//   - imageUrl: The path of the object for which the signed URL is to be generated.
+//   - opts: storage, expiry and Content-Disposition of the signed URL.
 //
 // Returns:
-//   - A signed URL string that allows access to the specified image, or an empty string if an error occurs.
-func (s Service) GetSignedUrl(imageUrl string) (string, error) {
-	signedURL, err := utils.GetObjectSignedURL(s.env.StorageBucketName, imageUrl)
-	if err != nil {
-		return "", err
-	}
-	return signedURL, nil
+//   - A signed URL string that allows access to the specified object, or an error if signing fails.
+func (s Service) GetSignedUrl(ctx context.Context, imageUrl string, opts services.SignedURLOptions) (string, error) {
+	return s.signedURL.Sign(ctx, imageUrl, opts)
+}
+
+// GetSignedUrls signs all objects with the same options, keyed by object path
+func (s Service) GetSignedUrls(
+	ctx context.Context,
+	objects []string,
+	opts services.SignedURLOptions,
+) (map[string]string, error) {
+	return s.signedURL.SignBatch(ctx, objects, opts)
 }
@@ -0,0 +1,25 @@
+package utility
+
+import (
+	"context"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/scheduler"
+)
+
+// NewCleanupUploadSessionsTask aborts expired upload sessions and removes their staged chunks
+func NewCleanupUploadSessionsTask(logger config.Logger, service UploadSessionService) scheduler.Task {
+	return scheduler.NewTask(
+		constants.TaskNames.CleanupUploadSessions,
+		"@hourly",
+		func(ctx context.Context) error {
+			cleaned, err := service.CleanupExpired(ctx)
+			if err != nil {
+				return err
+			}
+			logger.Info("Cleaned up ", cleaned, " expired upload sessions")
+			return nil
+		},
+	)
+}
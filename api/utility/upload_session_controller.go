@@ -0,0 +1,241 @@
+package utility
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/json_response"
+	"boilerplate-api/lib/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// UploadOffsetHeader offset of the chunk / bytes received so far
+	UploadOffsetHeader = "Upload-Offset"
+	// UploadLengthHeader declared size of the upload
+	UploadLengthHeader = "Upload-Length"
+)
+
+//	@Tags			UtilityApi
+//	@Summary		Initiate resumable upload
+//	@Description	creates upload session, chunks are sent with PATCH at the returned offset
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			data	body		CreateUploadSessionRequest	true	"Enter JSON"
+//	@Success		201		{object}	json_response.Data[UploadSessionResponse]
+//	@Failure		400		{object}	json_response.Error[string]
+//	@Failure		401		{object}	json_response.Error[string]
+//	@Failure		413		{object}	json_response.Error[string]
+//	@Router			/api/v1/utils/files/uploads [post]
+//	@Id				CreateUploadSession
+func (uc Controller) CreateUploadSession(ctx *gin.Context) {
+	var request CreateUploadSessionRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		uc.logger.Error("Error [CreateUploadSession] (ShouldBindJson) : ", err.Error())
+		ctx.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to bind upload session data",
+			},
+		)
+		return
+	}
+
+	userID, errResponse := getUserID(ctx)
+	if errResponse != nil {
+		uploadSessionError(ctx, errResponse)
+		return
+	}
+
+	session, errResponse := uc.uploadSessions.Create(ctx.Request.Context(), userID, request)
+	if errResponse != nil {
+		uploadSessionError(ctx, errResponse)
+		return
+	}
+
+	setUploadHeaders(ctx, session)
+	ctx.JSON(
+		http.StatusCreated, json_response.Data[UploadSessionResponse]{
+			Data: NewUploadSessionResponse(session),
+		},
+	)
+}
+
+//	@Tags			UtilityApi
+//	@Summary		Resumable upload status
+//	@Description	returns received offset of the upload session, also available with HEAD
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			id				path		string	true	"Upload session id"
+//	@Param			If-None-Match	header		string	false	"ETag of the cached session"
+//	@Success		200				{object}	json_response.Data[UploadSessionResponse]
+//	@Header			200				{string}	ETag	"ETag of the session"
+//	@Success		304				{string}	string	"Not modified"
+//	@Failure		404				{object}	json_response.Error[string]
+//	@Router			/api/v1/utils/files/uploads/{id} [get]
+//	@Id				GetUploadSession
+func (uc Controller) GetUploadSession(ctx *gin.Context) {
+	userID, errResponse := getUserID(ctx)
+	if errResponse != nil {
+		uploadSessionError(ctx, errResponse)
+		return
+	}
+
+	session, errResponse := uc.uploadSessions.Get(ctx.Request.Context(), userID, ctx.Param("id"))
+	if errResponse != nil {
+		uploadSessionError(ctx, errResponse)
+		return
+	}
+
+	setUploadHeaders(ctx, session)
+	ctx.Header("Cache-Control", "no-store")
+	if ctx.Request.Method == http.MethodHead {
+		ctx.Status(http.StatusOK)
+		return
+	}
+
+	utils.DataWithETag(
+		ctx, json_response.Data[UploadSessionResponse]{
+			Data: NewUploadSessionResponse(session),
+		},
+	)
+}
+
+//	@Tags			UtilityApi
+//	@Summary		Upload chunk
+//	@Description	appends raw request body at Upload-Offset, offset must equal the received bytes. Chunks are staged on the instance which created the session, others answer 421
+//	@Security		Bearer
+//	@Accept			application/offset+octet-stream
+//	@Produce		application/json
+//	@Param			id				path		string	true	"Upload session id"
+//	@Param			Upload-Offset	header		int		true	"Chunk offset"
+//	@Success		200				{object}	json_response.Data[UploadSessionResponse]
+//	@Failure		404				{object}	json_response.Error[string]
+//	@Failure		409				{object}	json_response.Error[string]
+//	@Failure		421				{object}	json_response.Error[string]
+//	@Router			/api/v1/utils/files/uploads/{id} [patch]
+//	@Id				UploadChunk
+func (uc Controller) UploadChunk(ctx *gin.Context) {
+	offset, err := strconv.ParseUint(ctx.GetHeader(UploadOffsetHeader), 10, 64)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   "invalid " + UploadOffsetHeader + " header",
+				Message: "Failed to upload chunk",
+			},
+		)
+		return
+	}
+
+	userID, errResponse := getUserID(ctx)
+	if errResponse != nil {
+		uploadSessionError(ctx, errResponse)
+		return
+	}
+
+	session, errResponse := uc.uploadSessions.WriteChunk(
+		ctx.Request.Context(), userID, ctx.Param("id"), offset, ctx.Request.Body,
+	)
+	if session.ID != "" {
+		setUploadHeaders(ctx, session)
+	}
+	if errResponse != nil {
+		uploadSessionError(ctx, errResponse)
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK, json_response.Data[UploadSessionResponse]{
+			Data: NewUploadSessionResponse(session),
+		},
+	)
+}
+
+//	@Tags			UtilityApi
+//	@Summary		Complete resumable upload
+//	@Description	validates assembled file and stores it in the storage backend
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			id	path		string	true	"Upload session id"
+//	@Success		200	{object}	json_response.Data[UploadSessionResponse]
+//	@Failure		404	{object}	json_response.Error[string]
+//	@Failure		409	{object}	json_response.Error[string]
+//	@Failure		415	{object}	json_response.Error[string]
+//	@Failure		421	{object}	json_response.Error[string]
+//	@Router			/api/v1/utils/files/uploads/{id}/complete [post]
+//	@Id				CompleteUploadSession
+func (uc Controller) CompleteUploadSession(ctx *gin.Context) {
+	userID, errResponse := getUserID(ctx)
+	if errResponse != nil {
+		uploadSessionError(ctx, errResponse)
+		return
+	}
+
+	session, errResponse := uc.uploadSessions.Complete(ctx.Request.Context(), userID, ctx.Param("id"))
+	if errResponse != nil {
+		uploadSessionError(ctx, errResponse)
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK, json_response.Data[UploadSessionResponse]{
+			Data: NewUploadSessionResponse(session),
+		},
+	)
+}
+
+//	@Tags			UtilityApi
+//	@Summary		Abort resumable upload
+//	@Description	aborts upload session and discards received chunks
+//	@Security		Bearer
+//	@Produce		application/json
+//	@Param			id	path		string	true	"Upload session id"
+//	@Success		200	{object}	json_response.Message
+//	@Failure		404	{object}	json_response.Error[string]
+//	@Failure		409	{object}	json_response.Error[string]
+//	@Failure		421	{object}	json_response.Error[string]
+//	@Router			/api/v1/utils/files/uploads/{id} [delete]
+//	@Id				AbortUploadSession
+func (uc Controller) AbortUploadSession(ctx *gin.Context) {
+	userID, errResponse := getUserID(ctx)
+	if errResponse != nil {
+		uploadSessionError(ctx, errResponse)
+		return
+	}
+
+	if errResponse := uc.uploadSessions.Abort(ctx.Request.Context(), userID, ctx.Param("id")); errResponse != nil {
+		uploadSessionError(ctx, errResponse)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, json_response.Message{Msg: "Upload aborted"})
+}
+
+func setUploadHeaders(ctx *gin.Context, session dao.UploadSession) {
+	ctx.Header(UploadOffsetHeader, strconv.FormatUint(session.ReceivedBytes, 10))
+	ctx.Header(UploadLengthHeader, strconv.FormatUint(session.Size, 10))
+}
+
+// getUserID id of the authenticated user
+func getUserID(ctx *gin.Context) (uint32, *api_errors.ErrorResponse) {
+	userID, errResponse := utils.StringToInt64(fmt.Sprintf("%v", ctx.MustGet(constants.UserID)))
+	if errResponse != nil {
+		errResponse.ErrorType = api_errors.Unauthorized
+		return 0, errResponse
+	}
+	return uint32(userID), nil
+}
+
+func uploadSessionError(ctx *gin.Context, errResponse *api_errors.ErrorResponse) {
+	ctx.JSON(
+		errResponse.ErrorType.ToInt(), json_response.Error[string]{
+			Error:   errResponse.Message,
+			Message: "Upload session request failed",
+		},
+	)
+}
@@ -0,0 +1,515 @@
+package utility
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/utils"
+	"boilerplate-api/services/aws"
+
+	"gorm.io/gorm"
+)
+
+// ChunkedUploadPolicy policy for resumable uploads
+var ChunkedUploadPolicy = UploadPolicy{
+	AllowedTypes: FileUploadPolicy.AllowedTypes,
+	MaxSize:      constants.ChunkedUploadMaxSize,
+}
+
+// UploadSessionService handles resumable chunked uploads
+//
+// Session state is persisted in `upload_sessions`, chunks are staged in UPLOAD_TMP_DIR
+// and streamed to the storage backend once the upload is completed. Staging is on local
+// disk, the session records its host and requests writing to it on another host are refused,
+// so instances behind a load balancer need session affinity on the session id.
+type UploadSessionService struct {
+	logger     config.Logger
+	host       string
+	env        config.Env
+	repository Repository
+	bucket     GcpStorageBucketService
+	s3Bucket   aws.S3BucketService
+	validator  UploadValidator
+	locks      *sync.Map
+}
+
+// NewUploadSessionService creates new upload session service
+func NewUploadSessionService(
+	logger config.Logger,
+	env config.Env,
+	repository Repository,
+	bucket GcpStorageBucketService,
+	s3Bucket aws.S3BucketService,
+	validator UploadValidator,
+) UploadSessionService {
+	host, _ := os.Hostname()
+	return UploadSessionService{
+		logger:     logger,
+		host:       host,
+		env:        env,
+		repository: repository,
+		bucket:     bucket,
+		s3Bucket:   s3Bucket,
+		validator:  validator,
+		locks:      &sync.Map{},
+	}
+}
+
+// Create initiates the upload session of the user
+func (s UploadSessionService) Create(
+	ctx context.Context,
+	userID uint32,
+	request CreateUploadSessionRequest,
+) (dao.UploadSession, *api_errors.ErrorResponse) {
+	if request.Size > uint64(ChunkedUploadPolicy.MaxSize) {
+		return dao.UploadSession{}, &api_errors.ErrorResponse{
+			ErrorType: api_errors.PayloadTooLarge,
+			Message:   fmt.Sprintf("File size exceeds the limit of %d bytes", ChunkedUploadPolicy.MaxSize),
+		}
+	}
+
+	if request.Storage == "" {
+		request.Storage = constants.Storages.GCS
+	}
+	if !request.Storage.IsValid() {
+		return dao.UploadSession{}, &api_errors.ErrorResponse{
+			ErrorType: api_errors.BadRequest,
+			Message:   "Invalid storage",
+		}
+	}
+
+	objectPath := "files"
+	if request.Path != "" {
+		sanitized, err := utils.SanitizeUploadPath(request.Path)
+		if err != nil {
+			return dao.UploadSession{}, &api_errors.ErrorResponse{
+				ErrorType: api_errors.BadRequest,
+				Message:   "Invalid upload path",
+			}
+		}
+		objectPath = sanitized
+	}
+
+	session := dao.UploadSession{
+		ID:         utils.GenerateRandomHex(16),
+		UserID:     &userID,
+		FileName:   filepath.Base(request.FileName),
+		ObjectPath: objectPath,
+		Storage:    request.Storage.ToString(),
+		Host:       &s.host,
+		Size:       request.Size,
+		Status:     constants.UploadSessionStatuses.Pending.ToString(),
+		ExpiresAt:  time.Now().Add(s.env.UploadSessionTTL),
+	}
+
+	if err := os.MkdirAll(s.env.UploadTmpDir, 0o700); err != nil {
+		s.logger.Error("Error creating upload staging dir: ", err.Error())
+		return dao.UploadSession{}, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to create upload session",
+		}
+	}
+
+	if err := s.repository.CreateUploadSession(ctx, &session); err != nil {
+		s.logger.Error("Error creating upload session: ", err.Error())
+		return dao.UploadSession{}, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to create upload session",
+		}
+	}
+
+	return session, nil
+}
+
+// Get gets upload session of the user, sessions of other users are not found
+func (s UploadSessionService) Get(
+	ctx context.Context,
+	userID uint32,
+	id string,
+) (dao.UploadSession, *api_errors.ErrorResponse) {
+	session, errResponse := s.get(ctx, id)
+	if errResponse != nil {
+		return session, errResponse
+	}
+	if session.UserID == nil || *session.UserID != userID {
+		return dao.UploadSession{}, &api_errors.ErrorResponse{
+			ErrorType: api_errors.NotFound,
+			Message:   "Upload session not found",
+		}
+	}
+	return session, nil
+}
+
+func (s UploadSessionService) get(ctx context.Context, id string) (dao.UploadSession, *api_errors.ErrorResponse) {
+	session, err := s.repository.GetUploadSession(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return session, &api_errors.ErrorResponse{
+			ErrorType: api_errors.NotFound,
+			Message:   "Upload session not found",
+		}
+	}
+	if err != nil {
+		s.logger.Error("Error getting upload session: ", err.Error())
+		return session, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get upload session",
+		}
+	}
+	return session, nil
+}
+
+// WriteChunk writes chunk at the given offset, offset must match the received bytes
+//
+// Bytes read before a client disconnect are kept so the client can resume from the new offset.
+func (s UploadSessionService) WriteChunk(
+	ctx context.Context,
+	userID uint32,
+	id string,
+	offset uint64,
+	chunk io.Reader,
+) (dao.UploadSession, *api_errors.ErrorResponse) {
+	unlock, ok := s.lock(id)
+	if !ok {
+		return dao.UploadSession{}, &api_errors.ErrorResponse{
+			ErrorType: api_errors.Conflict,
+			Message:   "Another chunk is being uploaded for this session",
+		}
+	}
+	defer unlock()
+
+	session, errResponse := s.getActive(ctx, userID, id)
+	if errResponse != nil {
+		return session, errResponse
+	}
+
+	if offset != session.ReceivedBytes {
+		return session, &api_errors.ErrorResponse{
+			ErrorType: api_errors.Conflict,
+			Message:   fmt.Sprintf("Offset mismatch, expected %d", session.ReceivedBytes),
+		}
+	}
+
+	file, err := os.OpenFile(s.stagingPath(id), os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		s.logger.Error("Error opening staged upload: ", err.Error())
+		return session, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to write chunk",
+		}
+	}
+	defer file.Close()
+
+	remaining := int64(session.Size - session.ReceivedBytes)
+	written, copyErr := io.Copy(
+		io.NewOffsetWriter(file, int64(offset)),
+		io.LimitReader(chunk, remaining),
+	)
+	// one more byte in the chunk overflows the declared size, nothing past it reaches the staged file
+	if copyErr == nil && written == remaining {
+		if n, _ := io.ReadFull(chunk, make([]byte, 1)); n > 0 {
+			return session, &api_errors.ErrorResponse{
+				ErrorType: api_errors.PayloadTooLarge,
+				Message:   "Chunk exceeds declared upload size",
+			}
+		}
+	}
+
+	if written > 0 {
+		// recorded even when the client disconnected, the written bytes are kept for the resume
+		advanced, err := s.repository.AdvanceUploadSession(
+			context.WithoutCancel(ctx),
+			id,
+			offset,
+			offset+uint64(written),
+		)
+		if err != nil {
+			s.logger.Error("Error advancing upload session: ", err.Error())
+			return session, &api_errors.ErrorResponse{
+				ErrorType: api_errors.InternalError,
+				Message:   "Failed to record chunk, query upload status and resume",
+			}
+		}
+		if !advanced {
+			s.logger.Warn("Upload session moved on while the chunk was written: ", id)
+			return session, &api_errors.ErrorResponse{
+				ErrorType: api_errors.Conflict,
+				Message:   "Failed to record chunk, query upload status and resume",
+			}
+		}
+		session.ReceivedBytes = offset + uint64(written)
+	}
+
+	if copyErr != nil {
+		s.logger.Error("Error reading chunk: ", copyErr.Error())
+		return session, &api_errors.ErrorResponse{
+			ErrorType: api_errors.BadRequest,
+			Message:   "Chunk upload interrupted",
+		}
+	}
+
+	return session, nil
+}
+
+// Complete validates the assembled file and uploads it to the storage backend
+func (s UploadSessionService) Complete(
+	ctx context.Context,
+	userID uint32,
+	id string,
+) (dao.UploadSession, *api_errors.ErrorResponse) {
+	unlock, ok := s.lock(id)
+	if !ok {
+		return dao.UploadSession{}, &api_errors.ErrorResponse{
+			ErrorType: api_errors.Conflict,
+			Message:   "A chunk is still being uploaded for this session",
+		}
+	}
+	defer unlock()
+
+	session, errResponse := s.getActive(ctx, userID, id)
+	if errResponse != nil {
+		return session, errResponse
+	}
+
+	if session.ReceivedBytes != session.Size {
+		return session, &api_errors.ErrorResponse{
+			ErrorType: api_errors.Conflict,
+			Message:   fmt.Sprintf("Upload incomplete, received %d of %d bytes", session.ReceivedBytes, session.Size),
+		}
+	}
+
+	file, err := os.Open(s.stagingPath(id))
+	if err != nil {
+		s.logger.Error("Error opening staged upload: ", err.Error())
+		return session, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to read uploaded file",
+		}
+	}
+	defer file.Close()
+
+	contentType, errResponse := s.validator.Validate(
+		ctx, file, &multipart.FileHeader{
+			Filename: session.FileName,
+			Size:     int64(session.Size),
+		}, ChunkedUploadPolicy,
+	)
+	if errResponse != nil {
+		return session, errResponse
+	}
+
+	// named after the session, a retried completion overwrites the object instead of leaving an orphan
+	objectName := session.ObjectPath + "/" + session.ID + filepath.Ext(session.FileName)
+
+	var location string
+	switch constants.Storage(session.Storage) {
+	case constants.Storages.S3:
+		location, err = s.s3Bucket.UploadFile(ctx, file, objectName, contentType)
+	default:
+		location, err = s.bucket.UploadFile(ctx, file, objectName)
+	}
+	if err != nil {
+		s.logger.Error("Error uploading assembled file: ", err.Error())
+		return session, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to upload file",
+		}
+	}
+
+	session.ContentType = &contentType
+	session.Location = &location
+	session.Status = constants.UploadSessionStatuses.Completed.ToString()
+	if err := s.repository.UpdateUploadSession(
+		ctx, id, map[string]interface{}{
+			"content_type": contentType,
+			"location":     location,
+			"status":       session.Status,
+		},
+	); err != nil {
+		s.logger.Error("Error completing upload session: ", err.Error())
+		return session, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to complete upload session",
+		}
+	}
+
+	s.removeStaged(id)
+	return session, nil
+}
+
+// Abort aborts the upload session and discards received chunks
+func (s UploadSessionService) Abort(ctx context.Context, userID uint32, id string) *api_errors.ErrorResponse {
+	unlock, ok := s.lock(id)
+	if !ok {
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.Conflict,
+			Message:   "A chunk is still being uploaded for this session",
+		}
+	}
+	defer unlock()
+
+	session, errResponse := s.Get(ctx, userID, id)
+	if errResponse != nil {
+		return errResponse
+	}
+	if errResponse = s.local(session); errResponse != nil {
+		return errResponse
+	}
+	return s.abort(ctx, session)
+}
+
+// abort marks the pending session aborted, the caller holds the session lock
+func (s UploadSessionService) abort(ctx context.Context, session dao.UploadSession) *api_errors.ErrorResponse {
+	if errResponse := pending(session); errResponse != nil {
+		return errResponse
+	}
+
+	if err := s.repository.UpdateUploadSession(
+		ctx, session.ID, map[string]interface{}{
+			"status": constants.UploadSessionStatuses.Aborted.ToString(),
+		},
+	); err != nil {
+		s.logger.Error("Error aborting upload session: ", err.Error())
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to abort upload session",
+		}
+	}
+
+	s.removeStaged(session.ID)
+	return nil
+}
+
+// CleanupExpired aborts pending sessions past their expiry and removes staged chunks
+//
+// chunks staged on other hosts are removed when they run the cleanup, by their age
+func (s UploadSessionService) CleanupExpired(ctx context.Context) (int, error) {
+	sessions, err := s.repository.GetExpiredUploadSessions(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, session := range sessions {
+		if errResponse := s.abortExpired(ctx, session.ID); errResponse != nil {
+			s.logger.Error("Error cleaning up upload session: ", session.ID, errResponse.Message)
+		}
+	}
+	s.removeStale()
+	return len(sessions), nil
+}
+
+func (s UploadSessionService) abortExpired(ctx context.Context, id string) *api_errors.ErrorResponse {
+	unlock, ok := s.lock(id)
+	if !ok {
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.Conflict,
+			Message:   "A chunk is still being uploaded for this session",
+		}
+	}
+	defer unlock()
+
+	// the session is read again under the lock, it may have been completed since it was listed
+	session, errResponse := s.get(ctx, id)
+	if errResponse != nil {
+		return errResponse
+	}
+	return s.abort(ctx, session)
+}
+
+func pending(session dao.UploadSession) *api_errors.ErrorResponse {
+	if session.Status != constants.UploadSessionStatuses.Pending.ToString() {
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.Conflict,
+			Message:   "Upload session is no longer active",
+		}
+	}
+	return nil
+}
+
+// getActive gets pending session of the user which has not expired yet
+func (s UploadSessionService) getActive(
+	ctx context.Context,
+	userID uint32,
+	id string,
+) (dao.UploadSession, *api_errors.ErrorResponse) {
+	session, errResponse := s.Get(ctx, userID, id)
+	if errResponse != nil {
+		return session, errResponse
+	}
+	if errResponse = pending(session); errResponse != nil {
+		return session, errResponse
+	}
+	if errResponse = s.local(session); errResponse != nil {
+		return session, errResponse
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return session, &api_errors.ErrorResponse{
+			ErrorType: api_errors.Conflict,
+			Message:   "Upload session has expired",
+		}
+	}
+	return session, nil
+}
+
+// local checks the chunks of the session are staged on this host, sessions created before hosts were recorded pass
+func (s UploadSessionService) local(session dao.UploadSession) *api_errors.ErrorResponse {
+	if session.Host != nil && *session.Host != s.host {
+		s.logger.Warn("Upload session request on another host: ", session.ID)
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.MisdirectedRequest,
+			Message:   "Upload session is staged on another instance",
+		}
+	}
+	return nil
+}
+
+// lock guards a session against concurrent chunk writes within this instance
+func (s UploadSessionService) lock(id string) (func(), bool) {
+	mu, _ := s.locks.LoadOrStore(id, &sync.Mutex{})
+	if !mu.(*sync.Mutex).TryLock() {
+		return nil, false
+	}
+	return mu.(*sync.Mutex).Unlock, true
+}
+
+func (s UploadSessionService) stagingPath(id string) string {
+	return filepath.Join(s.env.UploadTmpDir, id)
+}
+
+func (s UploadSessionService) removeStaged(id string) {
+	if err := os.Remove(s.stagingPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.logger.Error("Error removing staged upload: ", err.Error())
+	}
+	s.locks.Delete(id)
+}
+
+// removeStale removes staged chunks untouched for the session ttl, their sessions have expired
+func (s UploadSessionService) removeStale() {
+	entries, err := os.ReadDir(s.env.UploadTmpDir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			s.logger.Error("Error reading upload staging dir: ", err.Error())
+		}
+		return
+	}
+
+	staleBefore := time.Now().Add(-s.env.UploadSessionTTL)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.ModTime().After(staleBefore) {
+			continue
+		}
+		s.removeStaged(entry.Name())
+	}
+}
@@ -0,0 +1,226 @@
+package utility
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/services/aws"
+	"boilerplate-api/tests"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testUserID owner of the upload sessions created in the tests
+const testUserID uint32 = 1
+
+// fakeBucket keeps the uploaded files in memory
+type fakeBucket struct {
+	files map[string][]byte
+}
+
+func (b *fakeBucket) UploadFile(_ context.Context, file io.Reader, fileName string) (string, error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	b.files[fileName] = content
+	return "/" + fileName, nil
+}
+
+func newUploadSessionService(t *testing.T) (UploadSessionService, Repository, *fakeBucket) {
+	logger := config.GetLogger()
+	env := tests.NewEnv(t)
+	env.UploadTmpDir = t.TempDir()
+	env.UploadSessionTTL = time.Hour
+
+	repository := NewRepository(tests.NewDatabase(t), logger)
+	bucket := &fakeBucket{files: map[string][]byte{}}
+	service := NewUploadSessionService(
+		logger, env, repository, bucket, aws.S3BucketService{}, NewUploadValidator(logger, &fakeScanner{}),
+	)
+	return service, repository, bucket
+}
+
+func TestUploadSessionWriteChunk(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newUploadSessionService(t)
+	content := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{1}, 16)...)
+
+	session, errResponse := service.Create(ctx, testUserID, CreateUploadSessionRequest{FileName: "photo.png", Size: uint64(len(content))})
+	assert.Nil(t, errResponse)
+
+	session, errResponse = service.WriteChunk(ctx, testUserID, session.ID, 8, bytes.NewReader(content[8:16]))
+	if assert.NotNil(t, errResponse, "chunks are written in order") {
+		assert.Equal(t, api_errors.Conflict, errResponse.ErrorType)
+	}
+	assert.Zero(t, session.ReceivedBytes)
+
+	session, errResponse = service.WriteChunk(ctx, testUserID, session.ID, 0, bytes.NewReader(content[:16]))
+	assert.Nil(t, errResponse)
+	assert.EqualValues(t, 16, session.ReceivedBytes)
+
+	session, errResponse = service.WriteChunk(ctx, testUserID, session.ID, 0, bytes.NewReader(content[:16]))
+	if assert.NotNil(t, errResponse, "duplicate chunk") {
+		assert.Equal(t, api_errors.Conflict, errResponse.ErrorType)
+	}
+	assert.EqualValues(t, 16, session.ReceivedBytes)
+
+	session, errResponse = service.WriteChunk(ctx, testUserID, session.ID, 16, bytes.NewReader(append(content[16:], 0)))
+	if assert.NotNil(t, errResponse, "chunk past the declared size") {
+		assert.Equal(t, api_errors.PayloadTooLarge, errResponse.ErrorType)
+	}
+	assert.EqualValues(t, 16, session.ReceivedBytes)
+
+	session, errResponse = service.WriteChunk(ctx, testUserID, session.ID, uint64(len(content)), bytes.NewReader(content[16:]))
+	if assert.NotNil(t, errResponse, "offset past the received bytes") {
+		assert.Equal(t, api_errors.Conflict, errResponse.ErrorType)
+	}
+
+	session, errResponse = service.WriteChunk(ctx, testUserID, session.ID, 16, bytes.NewReader(content[16:]))
+	assert.Nil(t, errResponse)
+	assert.EqualValues(t, len(content), session.ReceivedBytes)
+
+	staged, _ := os.ReadFile(service.stagingPath(session.ID))
+	assert.Equal(t, content, staged)
+}
+
+func TestUploadSessionComplete(t *testing.T) {
+	ctx := context.Background()
+	service, repository, bucket := newUploadSessionService(t)
+	content := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{1}, 16)...)
+
+	session, errResponse := service.Create(
+		ctx, testUserID, CreateUploadSessionRequest{FileName: "photo.png", Size: uint64(len(content)), Path: "avatars"},
+	)
+	assert.Nil(t, errResponse)
+	_, errResponse = service.WriteChunk(ctx, testUserID, session.ID, 0, bytes.NewReader(content[:16]))
+	assert.Nil(t, errResponse)
+
+	_, errResponse = service.Complete(ctx, testUserID, session.ID)
+	if assert.NotNil(t, errResponse, "upload is incomplete") {
+		assert.Equal(t, api_errors.Conflict, errResponse.ErrorType)
+	}
+
+	_, errResponse = service.WriteChunk(ctx, testUserID, session.ID, 16, bytes.NewReader(content[16:]))
+	assert.Nil(t, errResponse)
+	session, errResponse = service.Complete(ctx, testUserID, session.ID)
+	assert.Nil(t, errResponse)
+
+	assert.Equal(t, constants.UploadSessionStatuses.Completed.ToString(), session.Status)
+	assert.Equal(t, "image/png", *session.ContentType)
+	assert.Equal(t, content, bucket.files[(*session.Location)[1:]])
+	assert.Equal(t, "/avatars/"+session.ID+".png", *session.Location, "object is named after the session")
+	_, err := os.Stat(service.stagingPath(session.ID))
+	assert.ErrorIs(t, err, os.ErrNotExist, "staged chunks are removed")
+
+	stored, err := repository.GetUploadSession(ctx, session.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, session.Status, stored.Status)
+
+	_, errResponse = service.WriteChunk(ctx, testUserID, session.ID, uint64(len(content)), bytes.NewReader(content))
+	if assert.NotNil(t, errResponse, "completed session takes no more chunks") {
+		assert.Equal(t, api_errors.Conflict, errResponse.ErrorType)
+	}
+}
+
+func TestUploadSessionExpiry(t *testing.T) {
+	ctx := context.Background()
+	service, repository, _ := newUploadSessionService(t)
+
+	session, errResponse := service.Create(ctx, testUserID, CreateUploadSessionRequest{FileName: "photo.png", Size: 32})
+	assert.Nil(t, errResponse)
+	_, errResponse = service.WriteChunk(ctx, testUserID, session.ID, 0, bytes.NewReader(pngHeader))
+	assert.Nil(t, errResponse)
+
+	assert.NoError(
+		t, repository.UpdateUploadSession(
+			ctx, session.ID, map[string]interface{}{"expires_at": time.Now().Add(-time.Minute)},
+		),
+	)
+
+	_, errResponse = service.WriteChunk(ctx, testUserID, session.ID, uint64(len(pngHeader)), bytes.NewReader(pngHeader))
+	if assert.NotNil(t, errResponse, "expired session takes no more chunks") {
+		assert.Equal(t, api_errors.Conflict, errResponse.ErrorType)
+	}
+
+	cleaned, err := service.CleanupExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cleaned)
+
+	stored, err := repository.GetUploadSession(ctx, session.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.UploadSessionStatuses.Aborted.ToString(), stored.Status)
+	_, err = os.Stat(service.stagingPath(session.ID))
+	assert.ErrorIs(t, err, os.ErrNotExist, "staged chunks are removed")
+}
+
+func TestUploadSessionOwner(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newUploadSessionService(t)
+	otherUserID := testUserID + 1
+
+	session, errResponse := service.Create(ctx, testUserID, CreateUploadSessionRequest{FileName: "photo.png", Size: 32})
+	assert.Nil(t, errResponse)
+
+	_, errResponse = service.Get(ctx, otherUserID, session.ID)
+	if assert.NotNil(t, errResponse, "session of another user") {
+		assert.Equal(t, api_errors.NotFound, errResponse.ErrorType)
+	}
+	_, errResponse = service.WriteChunk(ctx, otherUserID, session.ID, 0, bytes.NewReader(pngHeader))
+	if assert.NotNil(t, errResponse, "chunk to the session of another user") {
+		assert.Equal(t, api_errors.NotFound, errResponse.ErrorType)
+	}
+	_, errResponse = service.Complete(ctx, otherUserID, session.ID)
+	if assert.NotNil(t, errResponse, "completing the session of another user") {
+		assert.Equal(t, api_errors.NotFound, errResponse.ErrorType)
+	}
+	errResponse = service.Abort(ctx, otherUserID, session.ID)
+	if assert.NotNil(t, errResponse, "aborting the session of another user") {
+		assert.Equal(t, api_errors.NotFound, errResponse.ErrorType)
+	}
+
+	session, errResponse = service.Get(ctx, testUserID, session.ID)
+	assert.Nil(t, errResponse)
+	assert.Equal(t, constants.UploadSessionStatuses.Pending.ToString(), session.Status)
+	assert.Nil(t, service.Abort(ctx, testUserID, session.ID))
+}
+
+func TestUploadSessionHost(t *testing.T) {
+	ctx := context.Background()
+	service, repository, _ := newUploadSessionService(t)
+
+	session, errResponse := service.Create(ctx, testUserID, CreateUploadSessionRequest{FileName: "photo.png", Size: 32})
+	assert.Nil(t, errResponse)
+	assert.Equal(t, service.host, *session.Host)
+
+	assert.NoError(t, repository.UpdateUploadSession(ctx, session.ID, map[string]interface{}{"host": "other-host"}))
+	_, errResponse = service.WriteChunk(ctx, testUserID, session.ID, 0, bytes.NewReader(pngHeader))
+	if assert.NotNil(t, errResponse, "chunks are staged on the host which created the session") {
+		assert.Equal(t, api_errors.MisdirectedRequest, errResponse.ErrorType)
+	}
+	errResponse = service.Abort(ctx, testUserID, session.ID)
+	if assert.NotNil(t, errResponse) {
+		assert.Equal(t, api_errors.MisdirectedRequest, errResponse.ErrorType)
+	}
+
+	// chunks of sessions which expired on another host are removed by their age
+	stale := service.stagingPath("stale")
+	assert.NoError(t, os.WriteFile(stale, pngHeader, 0o600))
+	assert.NoError(t, os.Chtimes(stale, time.Now(), time.Now().Add(-2*time.Hour)))
+	fresh := service.stagingPath("fresh")
+	assert.NoError(t, os.WriteFile(fresh, pngHeader, 0o600))
+
+	_, err := service.CleanupExpired(ctx)
+	assert.NoError(t, err)
+	_, err = os.Stat(stale)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = os.Stat(fresh)
+	assert.NoError(t, err)
+}
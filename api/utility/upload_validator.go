@@ -0,0 +1,107 @@
+package utility
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"slices"
+
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/utils"
+	"boilerplate-api/services"
+)
+
+// UploadPolicy allowed content types and size cap of an upload endpoint
+type UploadPolicy struct {
+	AllowedTypes []string
+	MaxSize      int64
+}
+
+var (
+	// ImageUploadPolicy policy for image only uploads
+	ImageUploadPolicy = UploadPolicy{
+		AllowedTypes: constants.ImageContentTypes,
+		MaxSize:      constants.ImageUploadMaxSize,
+	}
+
+	// FileUploadPolicy policy for image and document uploads
+	FileUploadPolicy = UploadPolicy{
+		AllowedTypes: slices.Concat(constants.ImageContentTypes, constants.DocumentContentTypes),
+		MaxSize:      constants.FileUploadMaxSize,
+	}
+)
+
+// UploadValidator validates uploaded files before they are persisted
+type UploadValidator struct {
+	logger  config.Logger
+	scanner services.FileScanner
+}
+
+// NewUploadValidator creates new upload validator
+func NewUploadValidator(
+	logger config.Logger,
+	scanner services.FileScanner,
+) UploadValidator {
+	return UploadValidator{
+		logger:  logger,
+		scanner: scanner,
+	}
+}
+
+// Validate checks size, sniffs the content type from magic bytes and scans the file
+//
+// Returns the detected content type, file is rewound to the beginning.
+func (v UploadValidator) Validate(
+	ctx context.Context,
+	file multipart.File,
+	fileHeader *multipart.FileHeader,
+	policy UploadPolicy,
+) (string, *api_errors.ErrorResponse) {
+	if fileHeader.Size > policy.MaxSize {
+		return "", &api_errors.ErrorResponse{
+			ErrorType: api_errors.PayloadTooLarge,
+			Message:   fmt.Sprintf("File size exceeds the limit of %d bytes", policy.MaxSize),
+		}
+	}
+
+	contentType, err := utils.DetectContentType(file)
+	if err != nil {
+		v.logger.Error("Error detecting content type: ", err.Error())
+		return "", &api_errors.ErrorResponse{
+			ErrorType: api_errors.BadRequest,
+			Message:   "Failed to read uploaded file",
+		}
+	}
+
+	if !slices.Contains(policy.AllowedTypes, contentType) {
+		return "", &api_errors.ErrorResponse{
+			ErrorType: api_errors.UnsupportedMediaType,
+			Message:   fmt.Sprintf("File type %s is not allowed", contentType),
+		}
+	}
+
+	result, err := v.scanner.Scan(ctx, file)
+	if _, seekErr := file.Seek(0, io.SeekStart); err == nil && seekErr != nil {
+		err = seekErr
+	}
+	if err != nil {
+		v.logger.Error("Error scanning file: ", err.Error())
+		return "", &api_errors.ErrorResponse{
+			ErrorType: api_errors.Unavailable,
+			Message:   "Failed to scan uploaded file",
+		}
+	}
+
+	if result.Infected {
+		v.logger.Error("Infected file rejected: ", result.Signature)
+		return "", &api_errors.ErrorResponse{
+			ErrorType: api_errors.UnprocessableEntity,
+			Message:   "File rejected by malware scan",
+		}
+	}
+
+	return contentType, nil
+}
@@ -0,0 +1,143 @@
+package utility
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"testing"
+
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/utils"
+	"boilerplate-api/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeScanner records the scanned content and returns a fixed verdict
+type fakeScanner struct {
+	result  services.ScanResult
+	err     error
+	scanned []byte
+}
+
+func (f *fakeScanner) Scan(_ context.Context, file io.Reader) (services.ScanResult, error) {
+	f.scanned, _ = io.ReadAll(file)
+	return f.result, f.err
+}
+
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error { return nil }
+
+func newUpload(content []byte) (multipart.File, *multipart.FileHeader) {
+	return memoryFile{bytes.NewReader(content)}, &multipart.FileHeader{
+		Filename: "upload.png",
+		Size:     int64(len(content)),
+	}
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestUploadValidatorValidate(t *testing.T) {
+	logger := config.GetLogger()
+
+	t.Run(
+		"detects type from content and rewinds file", func(t *testing.T) {
+			scanner := &fakeScanner{}
+			validator := NewUploadValidator(logger, scanner)
+			file, header := newUpload(pngHeader)
+
+			contentType, errResponse := validator.Validate(context.Background(), file, header, ImageUploadPolicy)
+
+			assert.Nil(t, errResponse)
+			assert.Equal(t, "image/png", contentType)
+			assert.Equal(t, pngHeader, scanner.scanned)
+			rest, _ := io.ReadAll(file)
+			assert.Equal(t, pngHeader, rest)
+		},
+	)
+
+	t.Run(
+		"rejects spoofed content type", func(t *testing.T) {
+			validator := NewUploadValidator(logger, &fakeScanner{})
+			file, header := newUpload([]byte("#!/bin/sh\nrm -rf /\n"))
+			header.Header = map[string][]string{"Content-Type": {"image/png"}}
+
+			_, errResponse := validator.Validate(context.Background(), file, header, ImageUploadPolicy)
+
+			assert.NotNil(t, errResponse)
+			assert.Equal(t, api_errors.UnsupportedMediaType, errResponse.ErrorType)
+		},
+	)
+
+	t.Run(
+		"rejects files over the size cap", func(t *testing.T) {
+			scanner := &fakeScanner{}
+			validator := NewUploadValidator(logger, scanner)
+			file, header := newUpload(pngHeader)
+
+			_, errResponse := validator.Validate(
+				context.Background(), file, header, UploadPolicy{
+					AllowedTypes: ImageUploadPolicy.AllowedTypes,
+					MaxSize:      4,
+				},
+			)
+
+			assert.NotNil(t, errResponse)
+			assert.Equal(t, api_errors.PayloadTooLarge, errResponse.ErrorType)
+			assert.Nil(t, scanner.scanned)
+		},
+	)
+
+	t.Run(
+		"rejects infected files", func(t *testing.T) {
+			validator := NewUploadValidator(
+				logger, &fakeScanner{
+					result: services.ScanResult{Infected: true, Signature: "Eicar-Test-Signature"},
+				},
+			)
+			file, header := newUpload(pngHeader)
+
+			_, errResponse := validator.Validate(context.Background(), file, header, ImageUploadPolicy)
+
+			assert.NotNil(t, errResponse)
+			assert.Equal(t, api_errors.UnprocessableEntity, errResponse.ErrorType)
+		},
+	)
+
+	t.Run(
+		"fails closed when scanner is unavailable", func(t *testing.T) {
+			validator := NewUploadValidator(logger, &fakeScanner{err: errors.New("connection refused")})
+			file, header := newUpload(pngHeader)
+
+			_, errResponse := validator.Validate(context.Background(), file, header, ImageUploadPolicy)
+
+			assert.NotNil(t, errResponse)
+			assert.Equal(t, api_errors.Unavailable, errResponse.ErrorType)
+		},
+	)
+}
+
+func TestSanitizeUploadPath(t *testing.T) {
+	valid := map[string]string{
+		"users/avatars":    "users/avatars",
+		"/users//avatars/": "users/avatars",
+		`users\avatars`:    "users/avatars",
+		"users/./avatars":  "users/avatars",
+	}
+	for input, expected := range valid {
+		sanitized, err := utils.SanitizeUploadPath(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, sanitized)
+	}
+
+	for _, input := range []string{"", "/", "../etc", "users/../../etc", `..\windows`} {
+		_, err := utils.SanitizeUploadPath(input)
+		assert.ErrorIs(t, err, utils.ErrInvalidPath, input)
+	}
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/json_response"
+	"boilerplate-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stripeWebhookMaxBodySize events embedding objects with many items, e.g. invoices, go past a few hundred KB
+const stripeWebhookMaxBodySize = constants.MB
+
+type Controller struct {
+	logger  config.Logger
+	service Service
+}
+
+// NewController creates new webhook controller
+func NewController(
+	logger config.Logger,
+	service Service,
+) Controller {
+	return Controller{
+		logger:  logger,
+		service: service,
+	}
+}
+
+//	@Tags			WebhookApi
+//	@Summary		Stripe webhook
+//	@Description	Verifies Stripe-Signature header and processes each event once
+//	@Accept			application/json
+//	@Produce		application/json
+//	@Param			Stripe-Signature	header		string	true	"Stripe signature"
+//	@Success		200					{object}	json_response.Message
+//	@Failure		400					{object}	json_response.Error[string]
+//	@Failure		500					{object}	json_response.Error[string]
+//	@Router			/api/v1/webhooks/stripe [post]
+//	@Id				HandleStripeWebhook
+func (cc Controller) HandleStripeWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, stripeWebhookMaxBodySize))
+	if err != nil {
+		cc.logger.Error("Error reading stripe webhook body: ", err.Error())
+		c.JSON(
+			http.StatusBadRequest, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to read request body",
+			},
+		)
+		return
+	}
+
+	event, err := cc.service.ConstructStripeEvent(payload, c.GetHeader("Stripe-Signature"))
+	if err != nil {
+		cc.logger.Error("Error verifying stripe webhook: ", err.Error())
+		errResponse := services.ToErrorResponse(err, "Invalid webhook")
+		c.JSON(
+			errResponse.ErrorType.ToInt(), json_response.Error[string]{
+				Error:   errResponse.Message,
+				Message: "Failed to verify webhook",
+			},
+		)
+		return
+	}
+
+	processed, err := cc.service.HandleStripeEvent(c.Request.Context(), event)
+	if err != nil {
+		cc.logger.Error("Error processing stripe event ", event.ID, ": ", err.Error())
+		c.JSON(
+			http.StatusInternalServerError, json_response.Error[string]{
+				Error:   err.Error(),
+				Message: "Failed to process event",
+			},
+		)
+		return
+	}
+
+	message := "Event processed"
+	if !processed {
+		message = "Event already processed"
+	}
+	c.JSON(http.StatusOK, json_response.Message{Msg: message})
+}
@@ -0,0 +1,17 @@
+package webhook
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"webhook",
+	fx.Options(
+		fx.Provide(
+			NewRepository,
+			NewService,
+			NewController,
+		),
+		fx.Invoke(SetupRoutes),
+	),
+)
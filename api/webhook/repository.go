@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"context"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+
+	"gorm.io/gorm/clause"
+)
+
+// Repository database structure
+type Repository struct {
+	db     *config.Database
+	logger config.Logger
+}
+
+// NewRepository creates a new webhook repository
+func NewRepository(db *config.Database, logger config.Logger) Repository {
+	return Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateStripeEvent records the event, returns false when the event was already recorded
+//
+// concurrent deliveries of the same event wait on the row lock until the first one finishes
+func (r Repository) CreateStripeEvent(ctx context.Context, event *dao.StripeEvent) (bool, error) {
+	query := r.db.Conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	return query.RowsAffected == 1, query.Error
+}
@@ -0,0 +1,19 @@
+package webhook
+
+import (
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/router"
+)
+
+// SetupRoutes webhook routes, authenticated by the provider signatures
+func SetupRoutes(
+	logger config.Logger,
+	router router.Router,
+	controller Controller,
+) {
+	logger.Info(" Setting up webhook routes")
+	webhooks := router.V1.Group("/webhooks")
+	{
+		webhooks.POST("/stripe", controller.HandleStripeWebhook)
+	}
+}
@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"boilerplate-api/api/billing"
+	"boilerplate-api/api/notification"
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/services"
+
+	"github.com/stripe/stripe-go/v76"
+)
+
+// Service processes incoming webhooks
+type Service struct {
+	logger     config.Logger
+	repository Repository
+	stripe     services.StripeService
+	billing    billing.Service
+	unitOfWork config.UnitOfWork
+	// notifications are published in the event transaction, a rolled back event sends nothing
+	notifications notification.Queue
+}
+
+// NewService creates new webhook service
+func NewService(
+	logger config.Logger,
+	repository Repository,
+	stripe services.StripeService,
+	billing billing.Service,
+	unitOfWork config.UnitOfWork,
+	notifications notification.Queue,
+) Service {
+	return Service{
+		logger:        logger,
+		repository:    repository,
+		stripe:        stripe,
+		billing:       billing,
+		unitOfWork:    unitOfWork,
+		notifications: notifications,
+	}
+}
+
+// ConstructStripeEvent verifies signature of the stripe webhook payload
+func (s Service) ConstructStripeEvent(payload []byte, signature string) (stripe.Event, error) {
+	return s.stripe.ConstructWebhookEvent(payload, signature)
+}
+
+// HandleStripeEvent processes the event once, returns false when it was already processed
+//
+// event id is recorded in the same transaction as the local changes,
+// a failed handler rolls both back so stripe can retry the delivery.
+// stripe is called before the transaction so no connection is held during the request
+func (s Service) HandleStripeEvent(ctx context.Context, event stripe.Event) (bool, error) {
+	subscription, err := s.invoiceSubscription(ctx, event)
+	if err != nil {
+		return false, err
+	}
+
+	processed := false
+	err = s.unitOfWork.Do(
+		ctx, func(ctx context.Context) error {
+			created, err := s.repository.CreateStripeEvent(
+				ctx,
+				&dao.StripeEvent{
+					ID:   event.ID,
+					Type: string(event.Type),
+				},
+			)
+			if err != nil || !created {
+				return err
+			}
+			processed = true
+
+			handlers := services.StripeWebhookHandlers{
+				InvoicePaid: func(ctx context.Context, event stripe.Event, invoice *stripe.Invoice) error {
+					_, err := s.syncInvoice(ctx, event, invoice, subscription)
+					return err
+				},
+				InvoicePaymentFailed: func(ctx context.Context, event stripe.Event, invoice *stripe.Invoice) error {
+					return s.onInvoicePaymentFailed(ctx, event, invoice, subscription)
+				},
+				SubscriptionCreated: s.onSubscription,
+				SubscriptionUpdated: s.onSubscription,
+				SubscriptionDeleted: s.onSubscriptionDeleted,
+			}
+			return handlers.Dispatch(ctx, event)
+		},
+	)
+	return processed, err
+}
+
+// invoiceSubscription invoice events only reference the subscription, its current state is fetched from stripe
+func (s Service) invoiceSubscription(ctx context.Context, event stripe.Event) (*stripe.Subscription, error) {
+	var subscription *stripe.Subscription
+	fetch := func(ctx context.Context, _ stripe.Event, invoice *stripe.Invoice) (err error) {
+		if invoice.Subscription == nil {
+			// one-off invoice
+			return nil
+		}
+		subscription, err = s.stripe.GetSubscription(ctx, invoice.Subscription.ID)
+		return err
+	}
+
+	handlers := services.StripeWebhookHandlers{
+		InvoicePaid:          fetch,
+		InvoicePaymentFailed: fetch,
+	}
+	return subscription, handlers.Dispatch(ctx, event)
+}
+
+// onInvoicePaymentFailed asks the user to update the payment method
+func (s Service) onInvoicePaymentFailed(
+	ctx context.Context,
+	event stripe.Event,
+	invoice *stripe.Invoice,
+	subscription *stripe.Subscription,
+) error {
+	local, err := s.syncInvoice(ctx, event, invoice, subscription)
+	if err != nil {
+		return err
+	}
+	return s.notify(ctx, local, constants.NotificationEvents.PaymentFailed)
+}
+
+func (s Service) onSubscription(ctx context.Context, event stripe.Event, subscription *stripe.Subscription) error {
+	_, err := s.billing.SyncSubscription(ctx, time.Unix(event.Created, 0), subscription, nil)
+	return err
+}
+
+func (s Service) onSubscriptionDeleted(ctx context.Context, event stripe.Event, subscription *stripe.Subscription) error {
+	local, err := s.billing.SyncSubscription(ctx, time.Unix(event.Created, 0), subscription, nil)
+	if err != nil {
+		return err
+	}
+	return s.notify(ctx, local, constants.NotificationEvents.SubscriptionCanceled)
+}
+
+// syncInvoice mirrors the subscription of the invoice, nothing is synced for one-off invoices
+func (s Service) syncInvoice(
+	ctx context.Context,
+	event stripe.Event,
+	invoice *stripe.Invoice,
+	subscription *stripe.Subscription,
+) (*dao.Subscription, error) {
+	if subscription == nil {
+		return nil, nil
+	}
+	return s.billing.SyncSubscription(ctx, time.Unix(event.Created, 0), subscription, invoice)
+}
+
+// notify queues notification to owner of the subscription, it is sent once the event is committed
+func (s Service) notify(ctx context.Context, subscription *dao.Subscription, event constants.NotificationEvent) error {
+	if subscription == nil || subscription.UserID == nil {
+		return nil
+	}
+	return s.notifications.Notify(ctx, *subscription.UserID, event, nil)
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"boilerplate-api/api/billing"
+	"boilerplate-api/api/notification"
+	"boilerplate-api/api/outbox"
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/services"
+	"boilerplate-api/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v76"
+)
+
+func TestServiceHandleInvoiceEvent(t *testing.T) {
+	logger := config.GetLogger()
+	db := tests.NewDatabase(t)
+	backend := new(tests.StripeBackendMock)
+	stripeService := services.NewStripeService(
+		services.NewStripeTestConfig("prod_1", backend.Backends(), logger.SugaredLogger),
+	)
+	unitOfWork := config.NewUnitOfWork(db)
+	service := NewService(
+		logger,
+		NewRepository(db, logger),
+		stripeService,
+		billing.NewService(logger, billing.NewRepository(db, logger), stripeService, unitOfWork),
+		unitOfWork,
+		notification.NewQueue(outbox.NewService(logger, outbox.NewRepository(db, logger))),
+	)
+
+	backend.On("Call", "GET", "/v1/subscriptions/sub_1", "sk_test", mock.Anything, mock.Anything).Run(
+		func(args mock.Arguments) {
+			*args.Get(4).(*stripe.Subscription) = stripe.Subscription{
+				ID:     "sub_1",
+				Status: stripe.SubscriptionStatusActive,
+			}
+		},
+	).Return(nil)
+
+	newEvent := func(id string, invoice string) stripe.Event {
+		return stripe.Event{
+			ID:      id,
+			Type:    stripe.EventTypeInvoicePaid,
+			Created: time.Now().Unix(),
+			Data:    &stripe.EventData{Raw: []byte(invoice)},
+		}
+	}
+
+	processed, err := service.HandleStripeEvent(
+		context.Background(), newEvent("evt_1", `{"id":"in_1","status":"paid","subscription":"sub_1"}`),
+	)
+	require.NoError(t, err)
+	assert.True(t, processed)
+
+	var subscription dao.Subscription
+	require.NoError(t, db.Where("stripe_subscription_id = ?", "sub_1").First(&subscription).Error)
+	assert.Equal(t, string(stripe.SubscriptionStatusActive), subscription.Status)
+	assert.Equal(t, "in_1", *subscription.LatestInvoiceID)
+
+	processed, err = service.HandleStripeEvent(
+		context.Background(), newEvent("evt_1", `{"id":"in_1","status":"paid","subscription":"sub_1"}`),
+	)
+	require.NoError(t, err)
+	assert.False(t, processed, "redelivered event is skipped")
+
+	// one-off invoices have no subscription to fetch
+	processed, err = service.HandleStripeEvent(context.Background(), newEvent("evt_2", `{"id":"in_2","status":"paid"}`))
+	require.NoError(t, err)
+	assert.True(t, processed)
+	backend.AssertNumberOfCalls(t, "Call", 2)
+}
@@ -2,15 +2,21 @@ package bootstrap
 
 import (
 	"context"
+	"os"
 
 	"boilerplate-api/api"
+	"boilerplate-api/api/outbox"
 	"boilerplate-api/cli"
 	"boilerplate-api/database/seeds"
+	"boilerplate-api/jobs"
 	"boilerplate-api/lib"
 	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
 	"boilerplate-api/lib/router"
 	"boilerplate-api/lib/utils"
+	"boilerplate-api/scheduler"
 	"boilerplate-api/services"
+	"boilerplate-api/services/aws"
 	"boilerplate-api/swagger"
 	"go.uber.org/fx"
 )
@@ -21,6 +27,9 @@ var Module = fx.Options(
 	seeds.Module,
 	cli.Module,
 	services.Module,
+	aws.Module,
+	jobs.Module,
+	scheduler.Module,
 	api.Module,
 	fx.Supply(config.EnvPath(".env")),
 	fx.Invoke(bootstrap),
@@ -34,12 +43,22 @@ func bootstrap(
 	database *config.Database,
 	cliApp cli.Application,
 	migrations *config.Migrations,
+	dispatcher *outbox.Dispatcher,
+	worker *jobs.Worker,
+	taskScheduler *scheduler.Scheduler,
+	seeder *seeds.Seeder,
+	shutdowner fx.Shutdowner,
 ) {
 
 	appStop := func(context.Context) error {
 		logger.Info("Stopping Application")
-		conn, _ := database.DB.DB()
-		_ = conn.Close()
+		// nil when the connection failed
+		if database.DB == nil {
+			return nil
+		}
+		if conn, err := database.DB.DB(); err == nil {
+			_ = conn.Close()
+		}
 		return nil
 	}
 
@@ -49,7 +68,8 @@ func bootstrap(
 				OnStart: func(context.Context) error {
 					logger.Info("Starting cli Application")
 					logger.Info("------- (CLI) ------")
-					go cliApp.Start()
+					// the command stops the application with its exit code
+					go cliApp.Start(os.Args[2:])
 					return nil
 				},
 				OnStop: appStop,
@@ -59,6 +79,37 @@ func bootstrap(
 		return
 	}
 
+	if utils.IsWorker() {
+		lifecycle.Append(
+			fx.Hook{
+				OnStart: func(ctx context.Context) error {
+					logger.Info("Starting worker Application")
+					logger.Info("------- (Worker) ------")
+					if database.ConnectionError != nil {
+						logger.Error(*database.ConnectionError)
+					} else if err := migrations.CheckCleanLocked(ctx); err != nil {
+						return err
+					}
+					worker.Start()
+					taskScheduler.Start()
+					return nil
+				},
+				OnStop: func(ctx context.Context) error {
+					// running jobs finish within the fx stop timeout, the rest are claimed again after the lease
+					if err := worker.Stop(ctx); err != nil {
+						logger.Error("Error stopping worker: ", err.Error())
+					}
+					if err := taskScheduler.Stop(ctx); err != nil {
+						logger.Error("Error stopping scheduler: ", err.Error())
+					}
+					return appStop(ctx)
+				},
+			},
+		)
+
+		return
+	}
+
 	lifecycle.Append(
 		fx.Hook{
 			OnStart: func(context.Context) error {
@@ -75,12 +126,25 @@ func bootstrap(
 
 					if database.ConnectionError != nil {
 						logger.Error(*database.ConnectionError)
+					} else if err := migrate(env, logger, migrations); err != nil {
+						// serving a dirty or half migrated schema corrupts data, the instance exits instead
+						logger.Error("Error in migrations: ", err.Error())
+						_ = shutdowner.Shutdown(fx.ExitCode(1))
+						return
+					} else if err := seeder.Run(context.Background()); err != nil {
+						// recorded seeds are skipped, the failed one runs again on the next start
+						logger.Error("Error in seeds: ", err.Error())
+						// a broken seed stops local servers, deployed ones keep serving without its data
+						switch constants.Environment(env.Environment) {
+						case constants.Environments.Local, constants.Environments.Development:
+							_ = shutdowner.Shutdown(fx.ExitCode(1))
+							return
+						}
 					}
-
-					if env.Environment == "development" || env.Environment == "production" {
-						logger.Info("Migrating DB schema...")
-						migrations.MigrateUp()
-					}
+					// started after the migrations so the outbox and task tables exist,
+					// every instance schedules the tasks and the lock lets one of them run each
+					dispatcher.Start()
+					taskScheduler.Start()
 					if env.ServerPort == "" {
 						_ = handler.Run()
 					} else {
@@ -89,7 +153,24 @@ func bootstrap(
 				}()
 				return nil
 			},
-			OnStop: appStop,
+			OnStop: func(ctx context.Context) error {
+				if err := dispatcher.Stop(ctx); err != nil {
+					logger.Error("Error stopping outbox dispatcher: ", err.Error())
+				}
+				if err := taskScheduler.Stop(ctx); err != nil {
+					logger.Error("Error stopping scheduler: ", err.Error())
+				}
+				return appStop(ctx)
+			},
 		},
 	)
 }
+
+// migrate applies the migrations when MIGRATE_ON_START is set, otherwise only checks the database isn't dirty
+func migrate(env config.Env, logger config.Logger, migrations *config.Migrations) error {
+	if !env.MigrateOnStart {
+		return migrations.CheckCleanLocked(context.Background())
+	}
+	logger.Info("Migrating DB schema...")
+	return migrations.MigrateUp(context.Background())
+}
@@ -1,66 +1,185 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"boilerplate-api/lib/config"
+
 	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+)
+
+// Exit codes of the cli
+const (
+	ExitOK = 0
+	// ExitError command ran and failed
+	ExitError = 1
+	// ExitUsage unknown command, invalid flags or arguments
+	ExitUsage = 2
 )
 
-// Command has a command
+const exitCommand = "exit"
+
+// Command has a command, provided to the `group:"commands"`
 type Command interface {
-	Run()
-	Name() string
+	// Command creates the cobra command with its subcommands,
+	// it is created for every run so flags don't keep the values of the previous run
+	Command() *cobra.Command
+}
+
+// usageError invalid command line, prints the usage of the command
+type usageError struct {
+	error
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return usageError{fmt.Errorf(format, args...)}
+}
+
+// usageArgs reports argument validation errors as usage errors
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return usageError{err}
+		}
+		return nil
+	}
+}
+
+// group makes the command a parent of subcommands,
+// cobra prints the help and succeeds for unknown subcommands otherwise
+func group(cmd *cobra.Command) *cobra.Command {
+	cmd.Args = cobra.ArbitraryArgs
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			return usageErrorf("unknown command %q for %q", args[0], cmd.CommandPath())
+		}
+		return usageErrorf("%q requires a subcommand", cmd.CommandPath())
+	}
+	return cmd
 }
 
 // Application cli application
 type Application struct {
-	logger   config.Logger
-	commands []Command
+	logger     config.Logger
+	commands   []Command
+	shutdowner fx.Shutdowner
 }
 
 // NewApplication creates new cli application
 func NewApplication(
 	logger config.Logger,
-	createSeedData CreateSeedData,
+	shutdowner fx.Shutdowner,
+	commands []Command,
 ) Application {
 	return Application{
-		logger: logger,
-		commands: []Command{
-			createSeedData,
-		},
+		logger:     logger,
+		commands:   commands,
+		shutdowner: shutdowner,
 	}
 }
 
-// Start starts cli application
-func (c Application) Start() {
+// Start runs the command of the arguments, the picker without arguments,
+// and stops the application with the exit code
+func (c Application) Start(args []string) {
 	c.logger.Info("⛑  Start CLI...")
-	var names []string
-	commandMap := map[string]Command{}
-
-	for _, command := range c.commands {
-		names = append(names, command.Name())
-		commandMap[command.Name()] = command
+	var code int
+	if len(args) == 0 {
+		code = c.interactive()
+	} else {
+		code = c.Execute(args)
 	}
-
-	names = append(names, "EXIT_APPLICATION")
-
-	prompt := promptui.Select{
-		Label: "Select the command to run",
-		Items: names,
+	if err := c.shutdowner.Shutdown(fx.ExitCode(code)); err != nil {
+		c.logger.Error("Error stopping cli application: ", err.Error())
 	}
+}
 
-	_, result, err := prompt.Run()
+// Execute runs the command of the arguments and returns the exit code
+func (c Application) Execute(args []string) int {
+	root := c.root()
+	root.SetArgs(args)
+	cmd, err := root.ExecuteC()
+	if err == nil {
+		return ExitOK
+	}
 
-	if err != nil {
-		c.logger.Error("prompt failed")
+	root.PrintErrln("Error:", err.Error())
+	if errors.As(err, &usageError{}) {
+		root.PrintErrln(cmd.UsageString())
+		return ExitUsage
 	}
+	return ExitError
+}
 
-	if result == "EXIT_APPLICATION" {
-		c.logger.Info("CLI Application Exited")
-		return
+func (c Application) root() *cobra.Command {
+	root := group(
+		&cobra.Command{
+			Use:   "cli",
+			Short: "Runs maintenance commands, pick one interactively by running without arguments",
+			// errors are printed by Execute with the usage of usage errors only
+			SilenceErrors: true,
+			SilenceUsage:  true,
+		},
+	)
+	root.SetFlagErrorFunc(
+		func(_ *cobra.Command, err error) error {
+			return usageError{err}
+		},
+	)
+	root.CompletionOptions.DisableDefaultCmd = true
+	for _, command := range c.commands {
+		root.AddCommand(command.Command())
 	}
+	return root
+}
 
-	commandMap[result].Run()
+// interactive picks the commands to run until exit is selected
+func (c Application) interactive() int {
+	names := append(leaves(c.root()), exitCommand)
+	code := ExitOK
+	for {
+		_, name, err := (&promptui.Select{
+			Label: "Select the command to run",
+			Items: names,
+			Size:  len(names),
+		}).Run()
+		if err != nil {
+			c.logger.Error("prompt failed: ", err.Error())
+			return ExitError
+		}
+		if name == exitCommand {
+			c.logger.Info("CLI Application Exited")
+			return code
+		}
 
-	c.Start()
+		arguments, err := (&promptui.Prompt{
+			Label: fmt.Sprintf("Arguments of %q (--help for usage)", name),
+		}).Run()
+		if err != nil {
+			c.logger.Error("prompt failed: ", err.Error())
+			return ExitError
+		}
+		code = c.Execute(append(strings.Fields(name), strings.Fields(arguments)...))
+	}
+}
 
+// leaves paths of the runnable commands without subcommands
+//
+//	migrate up, migrate down, routes...
+func leaves(cmd *cobra.Command) []string {
+	var names []string
+	for _, child := range cmd.Commands() {
+		if !child.IsAvailableCommand() {
+			continue
+		}
+		if child.HasAvailableSubCommands() {
+			names = append(names, leaves(child)...)
+			continue
+		}
+		names = append(names, strings.TrimPrefix(child.CommandPath(), cmd.Root().Name()+" "))
+	}
+	return names
 }
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCommand struct {
+	run func(args []string) error
+}
+
+func (c testCommand) Command() *cobra.Command {
+	cmd := group(&cobra.Command{Use: "test"})
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:  "run <name>",
+			Args: usageArgs(cobra.ExactArgs(1)),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return c.run(args)
+			},
+		},
+	)
+	return cmd
+}
+
+func TestApplicationExecute(t *testing.T) {
+	var ran []string
+	app := Application{
+		commands: []Command{
+			testCommand{
+				run: func(args []string) error {
+					ran = append(ran, args...)
+					if args[0] == "fail" {
+						return errors.New("failed")
+					}
+					return nil
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, ExitOK, app.Execute([]string{"test", "run", "ok"}))
+	assert.Equal(t, ExitError, app.Execute([]string{"test", "run", "fail"}))
+	assert.Equal(t, []string{"ok", "fail"}, ran)
+
+	assert.Equal(t, ExitOK, app.Execute([]string{"--help"}))
+	assert.Equal(t, ExitOK, app.Execute([]string{"test", "run", "--help"}))
+
+	assert.Equal(t, ExitUsage, app.Execute([]string{"unknown"}))
+	assert.Equal(t, ExitUsage, app.Execute([]string{"test"}))
+	assert.Equal(t, ExitUsage, app.Execute([]string{"test", "unknown"}))
+	assert.Equal(t, ExitUsage, app.Execute([]string{"test", "run"}))
+	assert.Equal(t, ExitUsage, app.Execute([]string{"test", "run", "ok", "--unknown"}))
+	assert.Equal(t, []string{"ok", "fail"}, ran)
+}
+
+func TestLeaves(t *testing.T) {
+	app := Application{commands: []Command{testCommand{}}}
+	assert.Equal(t, []string{"test run"}, leaves(app.root()))
+}
@@ -6,6 +6,32 @@ import (
 
 // Module exports modules
 var Module = fx.Options(
-	fx.Provide(NewCreateSeedData),
-	fx.Provide(NewApplication),
+	fx.Provide(
+		fx.Annotate(
+			NewSeedCommand,
+			fx.ResultTags(`group:"commands"`),
+		),
+		fx.Annotate(
+			NewMigrateCommand,
+			fx.ResultTags(`group:"commands"`),
+		),
+		fx.Annotate(
+			NewUserCommand,
+			fx.ResultTags(`group:"commands"`),
+		),
+		fx.Annotate(
+			NewRoutesCommand,
+			fx.ResultTags(`group:"commands"`),
+		),
+		fx.Annotate(
+			NewConfigCommand,
+			fx.ResultTags(`group:"commands"`),
+		),
+	),
+	fx.Provide(
+		fx.Annotate(
+			NewApplication,
+			fx.ParamTags(``, ``, `group:"commands"`),
+		),
+	),
 )
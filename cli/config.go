@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"github.com/spf13/cobra"
+)
+
+// ConfigCommand validates the configuration of the environment
+type ConfigCommand struct {
+	env        config.Env
+	database   *config.Database
+	migrations *config.Migrations
+}
+
+// NewConfigCommand creates config command
+func NewConfigCommand(
+	env config.Env,
+	database *config.Database,
+	migrations *config.Migrations,
+) Command {
+	return ConfigCommand{
+		env:        env,
+		database:   database,
+		migrations: migrations,
+	}
+}
+
+type configCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// Command creates the config command and its subcommands
+func (c ConfigCommand) Command() *cobra.Command {
+	cmd := group(
+		&cobra.Command{
+			Use:   "config",
+			Short: "Inspects the configuration",
+		},
+	)
+	cmd.AddCommand(c.check())
+	return cmd
+}
+
+func (c ConfigCommand) check() *cobra.Command {
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Checks the environment variables, database connection and migrations, fails when any of them is invalid",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			failed := 0
+			out := cmd.OutOrStdout()
+			for _, check := range c.checks() {
+				if err := check.check(ctx); err != nil {
+					failed++
+					_, _ = fmt.Fprintf(out, "✘ %s: %s\n", check.name, err.Error())
+					continue
+				}
+				_, _ = fmt.Fprintf(out, "✔ %s\n", check.name)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d config checks failed", failed)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "timeout of the connection checks")
+	return cmd
+}
+
+func (c ConfigCommand) checks() []configCheck {
+	return []configCheck{
+		{
+			name: "environment variables",
+			check: func(context.Context) error {
+				required := map[string]string{
+					"DB_NAME":            c.env.DBName,
+					"JWT_ACCESS_SECRET":  c.env.JwtAccessSecret,
+					"JWT_REFRESH_SECRET": c.env.JwtRefreshSecret,
+				}
+				// sqlite database is a local file
+				if c.env.DBType != config.DBTypeSqlite.ToString() {
+					required["DB_USERNAME"] = c.env.DBUsername
+					required["DB_HOST"] = c.env.DBHost
+					required["DB_PORT"] = c.env.DBPort
+				}
+				return requireEnv(required)
+			},
+		},
+		{
+			name: "time zone",
+			check: func(context.Context) error {
+				_, err := time.LoadLocation(c.env.TimeZone)
+				return err
+			},
+		},
+		{
+			name: "mail driver",
+			check: func(context.Context) error {
+				switch constants.MailDriver(c.env.MailDriver) {
+				case constants.MailDrivers.Gmail, "":
+					return requireEnv(
+						map[string]string{
+							"MAIL_CLIENT_ID":     c.env.MailClientID,
+							"MAIL_CLIENT_SECRET": c.env.MailClientSecret,
+							"MAIL_REFRESH_TOKEN": c.env.MailRefreshToken,
+						},
+					)
+				case constants.MailDrivers.SMTP:
+					return requireEnv(map[string]string{"MAIL_SMTP_HOST": c.env.MailSMTPHost})
+				case constants.MailDrivers.SendGrid:
+					return requireEnv(map[string]string{"MAIL_API_KEY": c.env.MailAPIKey})
+				case constants.MailDrivers.Capture:
+					return nil
+				}
+				return fmt.Errorf("unknown MAIL_DRIVER %q", c.env.MailDriver)
+			},
+		},
+		{
+			name: "queue driver",
+			check: func(context.Context) error {
+				switch constants.QueueDriver(c.env.QueueDriver) {
+				case constants.QueueDrivers.MySQL, "":
+					return nil
+				case constants.QueueDrivers.Redis:
+					return requireEnv(map[string]string{"REDIS_ADDR": c.env.RedisAddr})
+				}
+				return fmt.Errorf("unknown QUEUE_DRIVER %q", c.env.QueueDriver)
+			},
+		},
+		{
+			name: "database connection",
+			check: func(ctx context.Context) error {
+				if c.database.ConnectionError != nil {
+					return *c.database.ConnectionError
+				}
+				sqlDB, err := c.database.DB.DB()
+				if err != nil {
+					return err
+				}
+				return sqlDB.PingContext(ctx)
+			},
+		},
+		{
+			name: "migrations",
+			check: func(context.Context) error {
+				status, err := c.migrations.Status()
+				if err != nil {
+					return err
+				}
+				if status.Dirty {
+					return fmt.Errorf("dirty at version %d", status.Version)
+				}
+				if len(status.Pending) > 0 {
+					return fmt.Errorf("%d pending, run `cli migrate up`", len(status.Pending))
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// requireEnv fails with the names of the empty variables
+func requireEnv(variables map[string]string) error {
+	var missing []string
+	for name, value := range variables {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	slices.Sort(missing)
+	return errors.New("missing " + strings.Join(missing, ", "))
+}
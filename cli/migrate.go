@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"boilerplate-api/lib/config"
+
+	"github.com/spf13/cobra"
+)
+
+// MigrateCommand applies and rolls back the migrations of database/migration
+type MigrateCommand struct {
+	logger     config.Logger
+	migrations *config.Migrations
+}
+
+// NewMigrateCommand creates migrate command
+func NewMigrateCommand(
+	logger config.Logger,
+	migrations *config.Migrations,
+) Command {
+	return MigrateCommand{
+		logger:     logger,
+		migrations: migrations,
+	}
+}
+
+// Command creates the migrate command and its subcommands
+func (c MigrateCommand) Command() *cobra.Command {
+	cmd := group(
+		&cobra.Command{
+			Use:   "migrate",
+			Short: "Applies, rolls back and inspects the database migrations",
+		},
+	)
+	cmd.AddCommand(c.up(), c.down(), c.goTo(), c.version(), c.status(), c.force())
+	return cmd
+}
+
+func (c MigrateCommand) up() *cobra.Command {
+	var steps int
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Applies the pending migrations",
+		Example: `  cli migrate up
+  cli migrate up --steps 1`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if steps < 0 {
+				return usageErrorf("--steps must not be negative")
+			}
+			c.logger.Info("--- Running Migration Up ---")
+			var err error
+			if steps > 0 {
+				err = c.migrations.Steps(cmd.Context(), steps)
+			} else {
+				err = c.migrations.Up(cmd.Context())
+			}
+			if err != nil {
+				return err
+			}
+			return c.printStatus(cmd)
+		},
+	}
+	cmd.Flags().IntVarP(&steps, "steps", "n", 0, "number of migrations to apply, all of them when 0")
+	return cmd
+}
+
+func (c MigrateCommand) down() *cobra.Command {
+	var steps int
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Rolls back the last migration",
+		Example: `  cli migrate down
+  cli migrate down --steps 3
+  cli migrate down --all`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all && cmd.Flags().Changed("steps") {
+				return usageErrorf("--steps and --all can't be used together")
+			}
+			if steps < 1 {
+				return usageErrorf("--steps must be greater than 0")
+			}
+			c.logger.Info("--- Running Migration Down ---")
+			var err error
+			if all {
+				err = c.migrations.Down(cmd.Context())
+			} else {
+				err = c.migrations.Steps(cmd.Context(), -steps)
+			}
+			if err != nil {
+				return err
+			}
+			return c.printStatus(cmd)
+		},
+	}
+	cmd.Flags().IntVarP(&steps, "steps", "n", 1, "number of migrations to roll back")
+	cmd.Flags().BoolVar(&all, "all", false, "roll back every migration")
+	return cmd
+}
+
+func (c MigrateCommand) goTo() *cobra.Command {
+	return &cobra.Command{
+		Use:     "goto <version>",
+		Short:   "Applies or rolls back the migrations until the version",
+		Example: "  cli migrate goto 20261019130000",
+		Args:    usageArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return usageErrorf("invalid version %q", args[0])
+			}
+			c.logger.Info("--- Running Migration Goto ", version, " ---")
+			if err := c.migrations.Goto(cmd.Context(), uint(version)); err != nil {
+				return err
+			}
+			return c.printStatus(cmd)
+		},
+	}
+}
+
+func (c MigrateCommand) version() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Prints the applied version, fails when the database is dirty",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, dirty, err := c.migrations.Version()
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), version)
+			if dirty {
+				return config.ErrMigrationsDirty
+			}
+			return nil
+		},
+	}
+}
+
+func (c MigrateCommand) status() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Prints the applied version and the pending migrations, fails when the database is dirty",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.printStatus(cmd)
+		},
+	}
+}
+
+func (c MigrateCommand) force() *cobra.Command {
+	return &cobra.Command{
+		Use:   "force <version>",
+		Short: "Sets the version without running migrations, clears the dirty flag after a failed migration",
+		Long: `Sets the version without running migrations, clears the dirty flag after a failed migration.
+Fix the schema by hand first, then force the last version that is fully applied, -1 for none.`,
+		Example: "  cli migrate force 20261019130200",
+		Args:    usageArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, err := strconv.Atoi(args[0])
+			if err != nil || version < -1 {
+				return usageErrorf("invalid version %q", args[0])
+			}
+			if err := c.migrations.Force(version); err != nil {
+				return err
+			}
+			return c.printStatus(cmd)
+		},
+	}
+}
+
+func (c MigrateCommand) printStatus(cmd *cobra.Command) error {
+	status, err := c.migrations.Status()
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "version: %d\n", status.Version)
+	_, _ = fmt.Fprintf(out, "dirty:   %t\n", status.Dirty)
+	_, _ = fmt.Fprintf(out, "pending: %d\n", len(status.Pending))
+	for _, version := range status.Pending {
+		_, _ = fmt.Fprintf(out, "  %d\n", version)
+	}
+	if status.Dirty {
+		return fmt.Errorf("%w: version %d", config.ErrMigrationsDirty, status.Version)
+	}
+	return nil
+}
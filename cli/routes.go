@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"boilerplate-api/lib/router"
+
+	"github.com/spf13/cobra"
+)
+
+// RoutesCommand lists the registered http routes
+type RoutesCommand struct {
+	router router.Router
+}
+
+// NewRoutesCommand creates routes command
+func NewRoutesCommand(router router.Router) Command {
+	return RoutesCommand{
+		router: router,
+	}
+}
+
+// Command creates the routes command
+func (c RoutesCommand) Command() *cobra.Command {
+	var method string
+	cmd := &cobra.Command{
+		Use:   "routes",
+		Short: "Lists the http routes and their handlers",
+		Example: `  cli routes
+  cli routes --method POST`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			routes := c.router.Routes()
+			sort.Slice(
+				routes, func(i, j int) bool {
+					if routes[i].Path == routes[j].Path {
+						return routes[i].Method < routes[j].Method
+					}
+					return routes[i].Path < routes[j].Path
+				},
+			)
+
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			_, _ = fmt.Fprintln(writer, "METHOD\tPATH\tHANDLER")
+			for _, route := range routes {
+				if method != "" && !strings.EqualFold(route.Method, method) {
+					continue
+				}
+				_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\n", route.Method, route.Path, route.Handler)
+			}
+			return writer.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&method, "method", "", "list only the routes of the http method")
+	return cmd
+}
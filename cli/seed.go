@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"boilerplate-api/database/seeds"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"github.com/spf13/cobra"
+)
+
+// SeedCommand runs, reruns and unseeds the seeds
+type SeedCommand struct {
+	env    config.Env
+	seeder *seeds.Seeder
+}
+
+// NewSeedCommand creates seed command
+func NewSeedCommand(
+	env config.Env,
+	seeder *seeds.Seeder,
+) Command {
+	return SeedCommand{
+		env:    env,
+		seeder: seeder,
+	}
+}
+
+// Command creates the seed command and its subcommands
+func (c SeedCommand) Command() *cobra.Command {
+	cmd := group(
+		&cobra.Command{
+			Use:   "seed",
+			Short: "Runs, reruns and unseeds the seeds, runs are recorded in seed_runs",
+		},
+	)
+	cmd.AddCommand(c.run(), c.rerun(), c.unseed(), c.status())
+	return cmd
+}
+
+func (c SeedCommand) run() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run [seed...]",
+		Short: "Runs the seeds of the environment that haven't run, all of them without names",
+		Example: `  cli seed run
+  cli seed run fake_users`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return seedError(c.seeder.Run(cmd.Context(), seedNames(args)...))
+		},
+	}
+}
+
+func (c SeedCommand) rerun() *cobra.Command {
+	return &cobra.Command{
+		Use:     "rerun <seed...>",
+		Short:   "Runs the seeds again, their dependencies run only when they haven't",
+		Example: "  cli seed rerun admin",
+		Args:    usageArgs(cobra.MinimumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return seedError(c.seeder.Rerun(cmd.Context(), seedNames(args)...))
+		},
+	}
+}
+
+func (c SeedCommand) unseed() *cobra.Command {
+	var all, force bool
+	cmd := &cobra.Command{
+		Use:   "unseed <seed...>",
+		Short: "Removes data of the seeds and the seeds depending on them",
+		Example: `  cli seed unseed fake_users
+  cli seed unseed --all`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return usageErrorf("either seed names or --all is required")
+			}
+			if constants.Environment(c.env.Environment) == constants.Environments.Production && !force {
+				return usageErrorf("unseeding in production requires --force")
+			}
+			return seedError(c.seeder.Unseed(cmd.Context(), seedNames(args)...))
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "unseed every seed")
+	cmd.Flags().BoolVar(&force, "force", false, "allow unseeding in production")
+	return cmd
+}
+
+func (c SeedCommand) status() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Lists the seeds in the run order with their last run",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			statuses, err := c.seeder.Status(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			_, _ = fmt.Fprintln(writer, "SEED\tDEPENDS ON\tENVIRONMENTS\tUNSEED\tRAN AT")
+			for _, status := range statuses {
+				environments := "all"
+				if len(status.Environments) > 0 {
+					environments = joinNames(status.Environments)
+				}
+				if !status.Enabled {
+					environments += " (skipped)"
+				}
+				ranAt := "-"
+				if status.RanAt != nil {
+					ranAt = status.RanAt.Format(time.DateTime)
+				}
+				_, _ = fmt.Fprintf(
+					writer, "%s\t%s\t%s\t%t\t%s\n",
+					status.Name, joinNames(status.DependsOn), environments, status.Unseedable, ranAt,
+				)
+			}
+			return writer.Flush()
+		},
+	}
+}
+
+// seedError unknown and disabled seeds are usage errors
+func seedError(err error) error {
+	if errors.Is(err, seeds.ErrSeedNotFound) || errors.Is(err, seeds.ErrSeedDisabled) {
+		return usageError{err}
+	}
+	return err
+}
+
+func seedNames(args []string) []constants.SeedName {
+	names := make([]constants.SeedName, len(args))
+	for i, arg := range args {
+		names[i] = constants.SeedName(arg)
+	}
+	return names
+}
+
+func joinNames[T ~string](names []T) string {
+	if len(names) == 0 {
+		return "-"
+	}
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = string(name)
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"errors"
+
+	adminUser "boilerplate-api/api/admin/user"
+	"boilerplate-api/api/user/user"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/request_validator"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// UserCommand manages the users
+type UserCommand struct {
+	logger      config.Logger
+	userService adminUser.Service
+	validator   request_validator.Validator
+}
+
+// NewUserCommand creates user command
+func NewUserCommand(
+	logger config.Logger,
+	userService adminUser.Service,
+	validator request_validator.Validator,
+) Command {
+	return UserCommand{
+		logger:      logger,
+		userService: userService,
+		validator:   validator,
+	}
+}
+
+// Command creates the user command and its subcommands
+func (c UserCommand) Command() *cobra.Command {
+	cmd := group(
+		&cobra.Command{
+			Use:   "user",
+			Short: "Manages the users",
+		},
+	)
+	cmd.AddCommand(c.create())
+	return cmd
+}
+
+func (c UserCommand) create() *cobra.Command {
+	newUser := user.CUser{}
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Creates a user, prompts for the password when it isn't given",
+		Example: `  cli user create --email admin@example.com --name Admin --phone 9800000000
+  cli user create --email admin@example.com --name Admin --phone 9800000000 --password secret`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, field := range []struct{ flag, value, rule string }{
+				{"email", newUser.Email, "required,email"},
+				{"name", newUser.FullName, "required,max=45"},
+				{"phone", newUser.Phone, "required,phone,max=15"},
+				{"gender", newUser.Gender, "omitempty,gender"},
+			} {
+				if err := c.validator.Var(field.value, field.rule); err != nil {
+					return usageErrorf("invalid --%s: %v", field.flag, err)
+				}
+			}
+			if newUser.Password == "" {
+				password, err := (&promptui.Prompt{Label: "Password", Mask: '*'}).Run()
+				if err != nil || password == "" {
+					return usageErrorf("--password is required")
+				}
+				newUser.Password = password
+			}
+
+			if _, err := c.userService.GetOneUserWithEmail(cmd.Context(), newUser.Email); !errors.Is(err, gorm.ErrRecordNotFound) {
+				if err != nil {
+					return err
+				}
+				return errors.New("user with this email already exists")
+			}
+			if _, err := c.userService.GetOneUserWithPhone(cmd.Context(), newUser.Phone); !errors.Is(err, gorm.ErrRecordNotFound) {
+				if err != nil {
+					return err
+				}
+				return errors.New("user with this phone already exists")
+			}
+
+			if err := c.userService.CreateUser(cmd.Context(), newUser); err != nil {
+				return err
+			}
+			c.logger.Info("User created, email: ", newUser.Email)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&newUser.Email, "email", "", "email of the user (required)")
+	cmd.Flags().StringVar(&newUser.FullName, "name", "", "full name of the user (required)")
+	cmd.Flags().StringVar(&newUser.Phone, "phone", "", "phone number of the user (required)")
+	cmd.Flags().StringVar(&newUser.Password, "password", "", "password of the user, prompted when empty")
+	cmd.Flags().StringVar(&newUser.Gender, "gender", "", "gender of the user")
+	cmd.Flags().StringVar(&newUser.Locale, "locale", "en", "locale of the emails sent to the user")
+	return cmd
+}
@@ -20,6 +20,9 @@ type User struct {
 	Gender    string         `gorm:"column:gender;type:varchar(15);not null" json:"gender"`
 	Email     string         `gorm:"column:email;type:varchar(100);not null;uniqueIndex:UQ_user_email,priority:1" json:"email"`
 	Password  string         `gorm:"column:password;type:varchar(100);not null" json:"password"`
+	Status    string         `gorm:"column:status;type:varchar(30);not null;default:unverified-email" json:"status"`
+	Locale    string         `gorm:"column:locale;type:varchar(10);not null;default:en" json:"locale"`
+	Version   uint32         `gorm:"column:version;type:int unsigned;not null;default:1" json:"version"`
 	CreatedAt time.Time      `gorm:"column:created_at;type:datetime;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time      `gorm:"column:updated_at;type:datetime;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;type:datetime" json:"deleted_at"`
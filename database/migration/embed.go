@@ -0,0 +1,9 @@
+package migration
+
+import "embed"
+
+// FS sql migrations embedded in the binary, applied without the source tree.
+// Each dialect keeps its own folder named after DB_TYPE
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var FS embed.FS
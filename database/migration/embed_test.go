@@ -0,0 +1,38 @@
+package migration
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMigrations every embedded migration parses and can be rolled back
+func TestMigrations(t *testing.T) {
+	versions := map[string][]uint{}
+	for _, dialect := range []string{"mysql", "postgres", "sqlite"} {
+		driver, err := iofs.New(FS, dialect)
+		assert.NoError(t, err)
+
+		version, err := driver.First()
+		for err == nil {
+			versions[dialect] = append(versions[dialect], version)
+			up, _, upErr := driver.ReadUp(version)
+			assert.NoError(t, upErr, "%s up migration of %d", dialect, version)
+			down, _, downErr := driver.ReadDown(version)
+			assert.NoError(t, downErr, "%s down migration of %d", dialect, version)
+			for _, file := range []interface{ Close() error }{up, down} {
+				if file != nil {
+					_ = file.Close()
+				}
+			}
+			version, err = driver.Next(version)
+		}
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+		assert.NotEmpty(t, versions[dialect], dialect)
+	}
+	assert.Equal(t, versions["mysql"], versions["postgres"], "every migration is written for each dialect")
+	assert.Equal(t, versions["mysql"], versions["sqlite"], "every migration is written for each dialect")
+}
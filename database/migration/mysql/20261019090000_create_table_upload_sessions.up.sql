@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS `upload_sessions`
+(
+    `id`             VARCHAR(32)     NOT NULL,
+    `file_name`      VARCHAR(255)    NOT NULL,
+    `object_path`    VARCHAR(255)    NOT NULL,
+    `storage`        VARCHAR(10)     NOT NULL,
+    `content_type`   VARCHAR(100)    NULL,
+    `size`           BIGINT UNSIGNED NOT NULL,
+    `received_bytes` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    `status`         VARCHAR(20)     NOT NULL,
+    `location`       VARCHAR(500)    NULL,
+    `expires_at`     DATETIME        NOT NULL,
+    `created_at`     DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`     DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX `IDX_upload_sessions_status_expires_at` (`status`, `expires_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS `subscriptions`
+(
+    `id`                     INT UNSIGNED AUTO_INCREMENT NOT NULL,
+    `stripe_subscription_id` VARCHAR(255)                NOT NULL,
+    `stripe_customer_id`     VARCHAR(255)                NOT NULL,
+    `stripe_price_id`        VARCHAR(255)                NULL,
+    `status`                 VARCHAR(30)                 NOT NULL,
+    `cancel_at_period_end`   TINYINT(1)                  NOT NULL DEFAULT 0,
+    `current_period_end`     DATETIME                    NULL,
+    `canceled_at`            DATETIME                    NULL,
+    `latest_invoice_id`      VARCHAR(255)                NULL,
+    `latest_invoice_status`  VARCHAR(30)                 NULL,
+    `stripe_event_at`        DATETIME                    NOT NULL,
+    `created_at`             DATETIME                    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`             DATETIME                    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT `UQ_subscriptions_stripe_subscription_id` UNIQUE (`stripe_subscription_id`),
+    INDEX `IDX_subscriptions_stripe_customer_id` (`stripe_customer_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,2 @@
+ALTER TABLE `users`
+    ADD COLUMN `status` VARCHAR(30) NOT NULL DEFAULT 'unverified-email' AFTER `password`;
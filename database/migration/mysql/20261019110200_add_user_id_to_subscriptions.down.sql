@@ -0,0 +1,4 @@
+ALTER TABLE `subscriptions`
+    DROP FOREIGN KEY `FK_subscriptions_user_id`,
+    DROP INDEX `IDX_subscriptions_user_id`,
+    DROP COLUMN `user_id`;
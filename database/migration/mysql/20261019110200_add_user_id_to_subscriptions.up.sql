@@ -0,0 +1,4 @@
+ALTER TABLE `subscriptions`
+    ADD COLUMN `user_id` INT UNSIGNED NULL AFTER `id`,
+    ADD INDEX `IDX_subscriptions_user_id` (`user_id`),
+    ADD CONSTRAINT `FK_subscriptions_user_id` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`);
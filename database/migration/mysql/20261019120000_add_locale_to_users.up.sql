@@ -0,0 +1,2 @@
+ALTER TABLE `users`
+    ADD COLUMN `locale` VARCHAR(10) NOT NULL DEFAULT 'en' AFTER `status`;
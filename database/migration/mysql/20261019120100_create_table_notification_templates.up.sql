@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS `notification_templates`
+(
+    `id`         INT UNSIGNED AUTO_INCREMENT NOT NULL,
+    `event`      VARCHAR(100)                NOT NULL,
+    `channel`    VARCHAR(20)                 NOT NULL,
+    `locale`     VARCHAR(10)                 NOT NULL,
+    `subject`    VARCHAR(255)                NOT NULL DEFAULT '',
+    `body`       TEXT                        NOT NULL,
+    `created_at` DATETIME                    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` DATETIME                    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT `UQ_notification_templates_event_channel_locale` UNIQUE (`event`, `channel`, `locale`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+INSERT INTO `notification_templates` (`event`, `channel`, `locale`, `subject`, `body`)
+VALUES ('payment_failed', 'email', 'en', 'Your payment failed',
+        'Hi {{.FullName}},\n\nWe could not charge your card for your subscription. Please update your payment method to keep your plan active.'),
+       ('payment_failed', 'sms', 'en', '',
+        'Your payment failed. Please update your payment method to keep your plan active.'),
+       ('payment_failed', 'push', 'en', 'Payment failed',
+        'Please update your payment method to keep your plan active.'),
+       ('subscription_canceled', 'email', 'en', 'Your subscription was canceled',
+        'Hi {{.FullName}},\n\nYour subscription has ended. You can subscribe again at any time.'),
+       ('subscription_canceled', 'push', 'en', 'Subscription canceled',
+        'Your subscription has ended.');
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `user_devices`
+(
+    `id`         INT UNSIGNED AUTO_INCREMENT NOT NULL,
+    `user_id`    INT UNSIGNED                NOT NULL,
+    `token`      VARCHAR(255)                NOT NULL,
+    `platform`   VARCHAR(20)                 NOT NULL,
+    `created_at` DATETIME                    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` DATETIME                    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT `UQ_user_devices_token` UNIQUE (`token`),
+    INDEX `IDX_user_devices_user_id` (`user_id`),
+    CONSTRAINT `FK_user_devices_user_id` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS `outbox_messages`
+(
+    `id`           BIGINT UNSIGNED AUTO_INCREMENT NOT NULL,
+    `topic`        VARCHAR(100)                   NOT NULL,
+    `payload`      JSON                           NOT NULL,
+    `status`       VARCHAR(20)                    NOT NULL DEFAULT 'pending',
+    `attempts`     INT UNSIGNED                   NOT NULL DEFAULT 0,
+    `max_attempts` INT UNSIGNED                   NOT NULL,
+    `available_at` DATETIME                       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `last_error`   TEXT                           NULL,
+    `sent_at`      DATETIME                       NULL,
+    `created_at`   DATETIME                       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   DATETIME                       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX `IDX_outbox_messages_status_available_at` (`status`, `available_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS `jobs`
+(
+    `id`           BIGINT UNSIGNED AUTO_INCREMENT NOT NULL,
+    `name`         VARCHAR(100)                   NOT NULL,
+    `payload`      JSON                           NOT NULL,
+    `status`       VARCHAR(20)                    NOT NULL DEFAULT 'pending',
+    `attempts`     INT UNSIGNED                   NOT NULL DEFAULT 0,
+    `max_attempts` INT UNSIGNED                   NOT NULL,
+    `run_at`       DATETIME                       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `last_error`   TEXT                           NULL,
+    `created_at`   DATETIME                       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at`   DATETIME                       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX `IDX_jobs_status_run_at` (`status`, `run_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
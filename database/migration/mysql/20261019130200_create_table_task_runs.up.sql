@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `task_runs`
+(
+    `id`           BIGINT UNSIGNED AUTO_INCREMENT NOT NULL,
+    `task`         VARCHAR(100)                   NOT NULL,
+    `triggered_by` VARCHAR(20)                    NOT NULL,
+    `status`       VARCHAR(20)                    NOT NULL,
+    `host`         VARCHAR(255)                   NOT NULL DEFAULT '',
+    `error`        TEXT                           NULL,
+    `started_at`   DATETIME                       NOT NULL,
+    `finished_at`  DATETIME                       NULL,
+    PRIMARY KEY (id),
+    INDEX `IDX_task_runs_task_id` (`task`, `id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `seed_runs`
+(
+    `id`     INT UNSIGNED AUTO_INCREMENT NOT NULL,
+    `seed`   VARCHAR(100)                NOT NULL,
+    `ran_at` DATETIME                    NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT `UQ_seed_runs_seed` UNIQUE (`seed`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,5 @@
+INSERT INTO `notification_templates` (`event`, `channel`, `locale`, `subject`, `body`)
+VALUES ('payment_failed', 'email', 'en', 'Your payment failed',
+        'Hi {{.FullName}},\n\nWe could not charge your card for your subscription. Please update your payment method to keep your plan active.'),
+       ('subscription_canceled', 'email', 'en', 'Your subscription was canceled',
+        'Hi {{.FullName}},\n\nYour subscription has ended. You can subscribe again at any time.');
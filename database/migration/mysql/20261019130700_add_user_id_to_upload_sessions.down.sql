@@ -0,0 +1,3 @@
+ALTER TABLE `upload_sessions`
+    DROP INDEX `IDX_upload_sessions_user_id`,
+    DROP COLUMN `user_id`;
@@ -0,0 +1,3 @@
+ALTER TABLE `upload_sessions`
+    ADD COLUMN `user_id` INT UNSIGNED NULL AFTER `id`,
+    ADD INDEX `IDX_upload_sessions_user_id` (`user_id`);
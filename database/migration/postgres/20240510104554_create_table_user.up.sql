@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS users
+(
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    full_name  VARCHAR(45)                              NOT NULL,
+    phone      VARCHAR(15)                              NOT NULL,
+    gender     VARCHAR(15)                              NOT NULL,
+    email      VARCHAR(100)                             NOT NULL,
+    password   VARCHAR(100)                             NOT NULL,
+    created_at TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP                                NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT UQ_user_email UNIQUE (email),
+    CONSTRAINT UQ_user_phone UNIQUE (phone)
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS upload_sessions
+(
+    id             VARCHAR(32)  NOT NULL,
+    file_name      VARCHAR(255) NOT NULL,
+    object_path    VARCHAR(255) NOT NULL,
+    storage        VARCHAR(10)  NOT NULL,
+    content_type   VARCHAR(100) NULL,
+    size           BIGINT       NOT NULL,
+    received_bytes BIGINT       NOT NULL DEFAULT 0,
+    status         VARCHAR(20)  NOT NULL,
+    location       VARCHAR(500) NULL,
+    expires_at     TIMESTAMP    NOT NULL,
+    created_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_upload_sessions_status_expires_at ON upload_sessions (status, expires_at);
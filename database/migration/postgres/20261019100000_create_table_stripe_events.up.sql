@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS stripe_events
+(
+    id         VARCHAR(255) NOT NULL,
+    type       VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
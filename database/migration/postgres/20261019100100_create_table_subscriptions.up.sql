@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS subscriptions
+(
+    id                     INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    stripe_subscription_id VARCHAR(255)                             NOT NULL,
+    stripe_customer_id     VARCHAR(255)                             NOT NULL,
+    stripe_price_id        VARCHAR(255)                             NULL,
+    status                 VARCHAR(30)                              NOT NULL,
+    cancel_at_period_end   BOOLEAN                                  NOT NULL DEFAULT FALSE,
+    current_period_end     TIMESTAMP                                NULL,
+    canceled_at            TIMESTAMP                                NULL,
+    latest_invoice_id      VARCHAR(255)                             NULL,
+    latest_invoice_status  VARCHAR(30)                              NULL,
+    stripe_event_at        TIMESTAMP                                NOT NULL,
+    created_at             TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at             TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT UQ_subscriptions_stripe_subscription_id UNIQUE (stripe_subscription_id)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_subscriptions_stripe_customer_id ON subscriptions (stripe_customer_id);
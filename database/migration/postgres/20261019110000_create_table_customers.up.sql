@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS customers
+(
+    id                 INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    user_id            INTEGER                                  NOT NULL,
+    stripe_customer_id VARCHAR(255)                             NOT NULL,
+    created_at         TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at         TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT UQ_customers_user_id UNIQUE (user_id),
+    CONSTRAINT UQ_customers_stripe_customer_id UNIQUE (stripe_customer_id),
+    CONSTRAINT FK_customers_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
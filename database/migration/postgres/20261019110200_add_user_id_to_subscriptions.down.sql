@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS IDX_subscriptions_user_id;
+
+ALTER TABLE subscriptions
+    DROP CONSTRAINT FK_subscriptions_user_id,
+    DROP COLUMN user_id;
@@ -0,0 +1,5 @@
+ALTER TABLE subscriptions
+    ADD COLUMN user_id INTEGER NULL,
+    ADD CONSTRAINT FK_subscriptions_user_id FOREIGN KEY (user_id) REFERENCES users (id);
+
+CREATE INDEX IF NOT EXISTS IDX_subscriptions_user_id ON subscriptions (user_id);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS notification_templates
+(
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    event      VARCHAR(100)                             NOT NULL,
+    channel    VARCHAR(20)                              NOT NULL,
+    locale     VARCHAR(10)                              NOT NULL,
+    subject    VARCHAR(255)                             NOT NULL DEFAULT '',
+    body       TEXT                                     NOT NULL,
+    created_at TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT UQ_notification_templates_event_channel_locale UNIQUE (event, channel, locale)
+);
+
+INSERT INTO notification_templates (event, channel, locale, subject, body)
+VALUES ('payment_failed', 'email', 'en', 'Your payment failed',
+        E'Hi {{.FullName}},\n\nWe could not charge your card for your subscription. Please update your payment method to keep your plan active.'),
+       ('payment_failed', 'sms', 'en', '',
+        'Your payment failed. Please update your payment method to keep your plan active.'),
+       ('payment_failed', 'push', 'en', 'Payment failed',
+        'Please update your payment method to keep your plan active.'),
+       ('subscription_canceled', 'email', 'en', 'Your subscription was canceled',
+        E'Hi {{.FullName}},\n\nYour subscription has ended. You can subscribe again at any time.'),
+       ('subscription_canceled', 'push', 'en', 'Subscription canceled',
+        'Your subscription has ended.');
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS notification_preferences
+(
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    user_id    INTEGER                                  NOT NULL,
+    event      VARCHAR(100)                             NOT NULL,
+    channel    VARCHAR(20)                              NOT NULL,
+    enabled    BOOLEAN                                  NOT NULL,
+    created_at TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT UQ_notification_preferences_user_id_event_channel UNIQUE (user_id, event, channel),
+    CONSTRAINT FK_notification_preferences_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS user_devices
+(
+    id         INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    user_id    INTEGER                                  NOT NULL,
+    token      VARCHAR(255)                             NOT NULL,
+    platform   VARCHAR(20)                              NOT NULL,
+    created_at TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP                                NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CONSTRAINT UQ_user_devices_token UNIQUE (token),
+    CONSTRAINT FK_user_devices_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_user_devices_user_id ON user_devices (user_id);
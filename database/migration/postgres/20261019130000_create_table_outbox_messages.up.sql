@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS outbox_messages
+(
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    topic        VARCHAR(100)                            NOT NULL,
+    payload      JSON                                    NOT NULL,
+    status       VARCHAR(20)                             NOT NULL DEFAULT 'pending',
+    attempts     INTEGER                                 NOT NULL DEFAULT 0,
+    max_attempts INTEGER                                 NOT NULL,
+    available_at TIMESTAMP                               NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error   TEXT                                    NULL,
+    sent_at      TIMESTAMP                               NULL,
+    created_at   TIMESTAMP                               NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP                               NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_outbox_messages_status_available_at ON outbox_messages (status, available_at);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS task_runs
+(
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    task         VARCHAR(100)                            NOT NULL,
+    triggered_by VARCHAR(20)                             NOT NULL,
+    status       VARCHAR(20)                             NOT NULL,
+    host         VARCHAR(255)                            NOT NULL DEFAULT '',
+    error        TEXT                                    NULL,
+    started_at   TIMESTAMP                               NOT NULL,
+    finished_at  TIMESTAMP                               NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_task_runs_task_id ON task_runs (task, id);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS seed_runs
+(
+    id     INTEGER GENERATED BY DEFAULT AS IDENTITY NOT NULL,
+    seed   VARCHAR(100)                             NOT NULL,
+    ran_at TIMESTAMP                                NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT UQ_seed_runs_seed UNIQUE (seed)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE task_runs
+    DROP CONSTRAINT IF EXISTS UQ_task_runs_task_scheduled_at,
+    DROP COLUMN scheduled_at;
@@ -0,0 +1,3 @@
+ALTER TABLE task_runs
+    ADD COLUMN scheduled_at TIMESTAMP NULL,
+    ADD CONSTRAINT UQ_task_runs_task_scheduled_at UNIQUE (task, scheduled_at);
@@ -0,0 +1,3 @@
+DELETE
+FROM notification_templates
+WHERE channel = 'email';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS IDX_upload_sessions_user_id;
+
+ALTER TABLE upload_sessions
+    DROP COLUMN user_id;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS users
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    full_name  VARCHAR(45)                       NOT NULL,
+    phone      VARCHAR(15)                       NOT NULL,
+    gender     VARCHAR(15)                       NOT NULL,
+    email      VARCHAR(100)                      NOT NULL,
+    password   VARCHAR(100)                      NOT NULL,
+    created_at DATETIME                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME                          NULL,
+    CONSTRAINT UQ_user_email UNIQUE (email),
+    CONSTRAINT UQ_user_phone UNIQUE (phone)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS customers
+(
+    id                 INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    user_id            INTEGER                           NOT NULL,
+    stripe_customer_id VARCHAR(255)                      NOT NULL,
+    created_at         DATETIME                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at         DATETIME                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT UQ_customers_user_id UNIQUE (user_id),
+    CONSTRAINT UQ_customers_stripe_customer_id UNIQUE (stripe_customer_id),
+    CONSTRAINT FK_customers_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
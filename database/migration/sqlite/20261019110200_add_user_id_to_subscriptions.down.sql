@@ -0,0 +1,43 @@
+-- sqlite can't drop a column of a foreign key, the table is rebuilt without it
+DROP INDEX IF EXISTS IDX_subscriptions_user_id;
+
+CREATE TABLE subscriptions_without_user_id
+(
+    id                     INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    stripe_subscription_id VARCHAR(255)                      NOT NULL,
+    stripe_customer_id     VARCHAR(255)                      NOT NULL,
+    stripe_price_id        VARCHAR(255)                      NULL,
+    status                 VARCHAR(30)                       NOT NULL,
+    cancel_at_period_end   BOOLEAN                           NOT NULL DEFAULT 0,
+    current_period_end     DATETIME                          NULL,
+    canceled_at            DATETIME                          NULL,
+    latest_invoice_id      VARCHAR(255)                      NULL,
+    latest_invoice_status  VARCHAR(30)                       NULL,
+    stripe_event_at        DATETIME                          NOT NULL,
+    created_at             DATETIME                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at             DATETIME                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT UQ_subscriptions_stripe_subscription_id UNIQUE (stripe_subscription_id)
+);
+
+INSERT INTO subscriptions_without_user_id
+SELECT id,
+       stripe_subscription_id,
+       stripe_customer_id,
+       stripe_price_id,
+       status,
+       cancel_at_period_end,
+       current_period_end,
+       canceled_at,
+       latest_invoice_id,
+       latest_invoice_status,
+       stripe_event_at,
+       created_at,
+       updated_at
+FROM subscriptions;
+
+DROP TABLE subscriptions;
+
+ALTER TABLE subscriptions_without_user_id
+    RENAME TO subscriptions;
+
+CREATE INDEX IF NOT EXISTS IDX_subscriptions_stripe_customer_id ON subscriptions (stripe_customer_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS user_devices
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    user_id    INTEGER                           NOT NULL,
+    token      VARCHAR(255)                      NOT NULL,
+    platform   VARCHAR(20)                       NOT NULL,
+    created_at DATETIME                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT UQ_user_devices_token UNIQUE (token),
+    CONSTRAINT FK_user_devices_user_id FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_user_devices_user_id ON user_devices (user_id);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS jobs
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name         VARCHAR(100)                      NOT NULL,
+    payload      JSON                              NOT NULL,
+    status       VARCHAR(20)                       NOT NULL DEFAULT 'pending',
+    attempts     INTEGER                           NOT NULL DEFAULT 0,
+    max_attempts INTEGER                           NOT NULL,
+    run_at       DATETIME                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error   TEXT                              NULL,
+    created_at   DATETIME                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   DATETIME                          NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS IDX_jobs_status_run_at ON jobs (status, run_at);
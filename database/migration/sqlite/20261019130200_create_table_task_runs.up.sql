@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS task_runs
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    task         VARCHAR(100)                      NOT NULL,
+    triggered_by VARCHAR(20)                       NOT NULL,
+    status       VARCHAR(20)                       NOT NULL,
+    host         VARCHAR(255)                      NOT NULL DEFAULT '',
+    error        TEXT                              NULL,
+    started_at   DATETIME                          NOT NULL,
+    finished_at  DATETIME                          NULL
+);
+
+CREATE INDEX IF NOT EXISTS IDX_task_runs_task_id ON task_runs (task, id);
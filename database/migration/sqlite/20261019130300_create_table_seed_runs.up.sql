@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS seed_runs
+(
+    id     INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    seed   VARCHAR(100)                      NOT NULL,
+    ran_at DATETIME                          NOT NULL,
+    CONSTRAINT UQ_seed_runs_seed UNIQUE (seed)
+);
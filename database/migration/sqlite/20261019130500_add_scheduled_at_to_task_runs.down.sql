@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS UQ_task_runs_task_scheduled_at;
+
+ALTER TABLE task_runs
+    DROP COLUMN scheduled_at;
@@ -0,0 +1,4 @@
+ALTER TABLE task_runs
+    ADD COLUMN scheduled_at DATETIME NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS UQ_task_runs_task_scheduled_at ON task_runs (task, scheduled_at);
@@ -0,0 +1,7 @@
+INSERT INTO notification_templates (event, channel, locale, subject, body)
+VALUES ('payment_failed', 'email', 'en', 'Your payment failed',
+        'Hi {{.FullName}},' || char(10) || char(10) ||
+        'We could not charge your card for your subscription. Please update your payment method to keep your plan active.'),
+       ('subscription_canceled', 'email', 'en', 'Your subscription was canceled',
+        'Hi {{.FullName}},' || char(10) || char(10) ||
+        'Your subscription has ended. You can subscribe again at any time.');
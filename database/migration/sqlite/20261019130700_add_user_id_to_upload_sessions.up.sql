@@ -0,0 +1,4 @@
+ALTER TABLE upload_sessions
+    ADD COLUMN user_id INTEGER NULL;
+
+CREATE INDEX IF NOT EXISTS IDX_upload_sessions_user_id ON upload_sessions (user_id);
@@ -0,0 +1,2 @@
+ALTER TABLE upload_sessions
+    ADD COLUMN host VARCHAR(255) NULL;
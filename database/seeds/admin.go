@@ -2,56 +2,80 @@ package seeds
 
 import (
 	"context"
+	"errors"
 
+	"boilerplate-api/database/dao"
 	"boilerplate-api/lib/config"
 	"boilerplate-api/lib/constants"
-	"boilerplate-api/services"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
-// AdminSeed  Admin seeding
+// AdminSeed creates the user of ADMIN_EMAIL, the admin role is given to the email when signing in
 type AdminSeed struct {
-	Seed
-	logger          config.Logger
-	firebaseService services.IFirebaseAdminSeed
-	adminEmail      string
-	adminPass       string
-	adminName       string
+	logger     config.Logger
+	adminEmail string
+	adminPass  string
+	adminName  string
 }
 
 // NewAdminSeed creates admin seed
 func NewAdminSeed(
 	logger config.Logger,
-	authService services.IFirebaseAdminSeed,
-	adminEmail string,
-	adminPass string,
-	adminName string,
+	env config.Env,
 ) AdminSeed {
 	return AdminSeed{
-		logger:          logger,
-		firebaseService: authService,
-		adminEmail:      adminEmail,
-		adminPass:       adminPass,
-		adminName:       adminName,
+		logger:     logger,
+		adminEmail: env.AdminEmail,
+		adminPass:  env.AdminPass,
+		adminName:  env.AdminName,
 	}
 }
 
+// Name of the seed
+func (c AdminSeed) Name() constants.SeedName {
+	return constants.SeedNames.Admin
+}
+
 // Run the seed data
-func (c AdminSeed) Run() {
-	c.logger.Info("🌱 seeding admin data...")
+func (c AdminSeed) Run(ctx context.Context, tx *gorm.DB) error {
+	if c.adminEmail == "" || c.adminPass == "" {
+		return errors.New("ADMIN_EMAIL and ADMIN_PASS are required")
+	}
 
-	_, err := c.firebaseService.GetUserByEmail(context.Background(), c.adminEmail)
-	if err != nil {
-		_, errResponse := c.firebaseService.CreateUser(
-			c.adminName, c.adminEmail, c.adminPass,
-			string(constants.Roles.SuperAdmin),
-		)
-		if errResponse != nil {
-			c.logger.Error("Firebase Admin user can't be created: ", errResponse.Message)
-			return
-		}
+	var count int64
+	if err := tx.Model(&dao.User{}).Where("email = ?", c.adminEmail).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		c.logger.Info("Admin already exist")
+		return nil
+	}
 
-		c.logger.Info("Firebase Admin UserName Created, email: ", c.adminEmail, " password: ", c.adminPass)
+	password, err := bcrypt.GenerateFromPassword([]byte(c.adminPass), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	name := c.adminName
+	if name == "" {
+		name = "Admin"
+	}
+	admin := dao.User{
+		FullName: name,
+		Email:    c.adminEmail,
+		Password: string(password),
+		Locale:   constants.DefaultLocale,
 	}
+	if err := tx.Create(&admin).Error; err != nil {
+		return err
+	}
+
+	c.logger.Info("Admin user created, email: ", c.adminEmail)
+	return nil
+}
 
-	c.logger.Info("Admin already exist")
+// Unseed deletes the admin user
+func (c AdminSeed) Unseed(ctx context.Context, tx *gorm.DB) error {
+	return tx.Unscoped().Where("email = ?", c.adminEmail).Delete(&dao.User{}).Error
 }
@@ -2,9 +2,13 @@ package seeds
 
 import (
 	"context"
+	"slices"
 
 	"boilerplate-api/lib/config"
-	"boilerplate-api/lib/utils"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/scheduler"
+
+	"gorm.io/gorm"
 )
 
 type IGcpBillingService interface {
@@ -13,7 +17,6 @@ type IGcpBillingService interface {
 
 // ProjectBudgetSeed  Budget setup seed
 type ProjectBudgetSeed struct {
-	Seed
 	logger        config.Logger
 	budgetService IGcpBillingService
 	env           config.Env
@@ -32,18 +35,52 @@ func NewProjectBudgetSeed(
 	}
 }
 
-// Run the seed data
-func (c ProjectBudgetSeed) Run() {
-	c.logger.Info("🌱 seeding  budget alert related setup...")
+// Name of the seed
+func (c ProjectBudgetSeed) Name() constants.SeedName {
+	return constants.SeedNames.ProjectBudget
+}
 
-	if c.env.SetBudget == 1 {
-		ctx := utils.GetContext()
-		_, err := c.budgetService.CreateOrUpdateBudget(ctx)
+// Environments budget alerts are set for the production project only
+func (c ProjectBudgetSeed) Environments() []constants.Environment {
+	return []constants.Environment{constants.Environments.Production}
+}
+
+// Run the seed data
+func (c ProjectBudgetSeed) Run(ctx context.Context, _ *gorm.DB) error {
+	if c.env.SetBudget != 1 {
+		c.logger.Info("SET_BUDGET is not enabled, skipping budget alert")
+		return nil
+	}
 
-		if err != nil {
-			c.logger.Info("There is an error setting up budget alert ")
-		} else {
-			c.logger.Info("budget alert setup successfully")
-		}
+	c.logger.Info("🌱 seeding  budget alert related setup...")
+	if _, err := c.budgetService.CreateOrUpdateBudget(ctx); err != nil {
+		return err
 	}
+	c.logger.Info("budget alert setup successfully")
+	return nil
+}
+
+// NewProjectBudgetTask syncs the budget alert daily, the seed only runs once per environment
+//
+// budget service is optional, the task is skipped until the gcp billing service is provided
+func NewProjectBudgetTask(
+	logger config.Logger,
+	budgetService IGcpBillingService,
+	env config.Env,
+) scheduler.Task {
+	seed := NewProjectBudgetSeed(logger, budgetService, env)
+	return scheduler.NewTask(
+		constants.TaskNames.CheckProjectBudget,
+		"@daily",
+		func(ctx context.Context) error {
+			if budgetService == nil {
+				logger.Info("GCP billing service is not provided, skipping budget check")
+				return nil
+			}
+			if !slices.Contains(seed.Environments(), constants.Environment(env.Environment)) {
+				return nil
+			}
+			return seed.Run(ctx, nil)
+		},
+	)
 }
@@ -0,0 +1,62 @@
+package seeds
+
+import (
+	"context"
+
+	"boilerplate-api/__mocks/mock_data"
+	"boilerplate-api/database/dao"
+	"boilerplate-api/database/seeds/faker"
+	"boilerplate-api/lib/constants"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"gorm.io/gorm"
+)
+
+// NewFakeUsersSeed users with devices and stripe customers to try the api locally
+func NewFakeUsersSeed() Seed {
+	return New(
+		constants.SeedNames.FakeUsers,
+		func(ctx context.Context, tx *gorm.DB) error {
+			users := faker.Has(
+				mock_data.Users.Count(10), mock_data.UserDevices.Count(2),
+				func(user *dao.User, device *dao.UserDevice) {
+					device.UserID = user.ID
+				},
+			)
+			users = faker.Has(
+				users, mock_data.Customers,
+				func(user *dao.User, customer *dao.Customer) {
+					customer.UserID = user.ID
+				},
+			)
+			_, err := users.Create(tx, gofakeit.New(0))
+			return err
+		},
+		In(constants.Environments.Local, constants.Environments.Development),
+		WithUnseed(
+			func(ctx context.Context, tx *gorm.DB) error {
+				var userIDs []uint32
+				err := tx.Unscoped().
+					Model(&dao.User{}).
+					Where("email LIKE ?", "%@"+mock_data.EmailDomain).
+					Pluck("id", &userIDs).
+					Error
+				if err != nil || len(userIDs) == 0 {
+					return err
+				}
+				// rows referencing the users, including the ones created through the api
+				for _, model := range []interface{}{
+					&dao.UserDevice{},
+					&dao.Customer{},
+					&dao.NotificationPreference{},
+					&dao.Subscription{},
+				} {
+					if err := tx.Where("user_id IN ?", userIDs).Delete(model).Error; err != nil {
+						return err
+					}
+				}
+				return tx.Unscoped().Where("id IN ?", userIDs).Delete(&dao.User{}).Error
+			},
+		),
+	)
+}
@@ -0,0 +1,109 @@
+package faker
+
+import (
+	"slices"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"gorm.io/gorm"
+)
+
+// Factory builds fake T records and creates them with their related records,
+// methods return a copy so factories can be shared as defaults
+//
+//	users := faker.Has(mock_data.Users.Count(5), mock_data.UserDevices.Count(2), func(user *dao.User, device *dao.UserDevice) {
+//		device.UserID = user.ID
+//	})
+//	created, err := users.Create(tx, gofakeit.New(0))
+type Factory[T any] struct {
+	define func(f *gofakeit.Faker) T
+	count  int
+	states []func(f *gofakeit.Faker, record *T)
+	// before creates records the record belongs to, after creates records belonging to it
+	before []func(tx *gorm.DB, f *gofakeit.Faker, record *T) error
+	after  []func(tx *gorm.DB, f *gofakeit.Faker, record *T) error
+}
+
+// NewFactory creates factory of one record defined by the function
+func NewFactory[T any](define func(f *gofakeit.Faker) T) Factory[T] {
+	return Factory[T]{
+		define: define,
+		count:  1,
+	}
+}
+
+// Count number of records to make
+func (f Factory[T]) Count(count int) Factory[T] {
+	f.count = count
+	return f
+}
+
+// State overrides values of the defined records
+func (f Factory[T]) State(state func(f *gofakeit.Faker, record *T)) Factory[T] {
+	f.states = append(slices.Clip(f.states), state)
+	return f
+}
+
+// Make builds the records without inserting them nor their related records
+func (f Factory[T]) Make(faker *gofakeit.Faker) []T {
+	records := make([]T, f.count)
+	for i := range records {
+		records[i] = f.define(faker)
+		for _, state := range f.states {
+			state(faker, &records[i])
+		}
+	}
+	return records
+}
+
+// Create inserts the records with their related records
+func (f Factory[T]) Create(tx *gorm.DB, faker *gofakeit.Faker) ([]T, error) {
+	records := f.Make(faker)
+	for i := range records {
+		for _, before := range f.before {
+			if err := before(tx, faker, &records[i]); err != nil {
+				return nil, err
+			}
+		}
+		if err := tx.Create(&records[i]).Error; err != nil {
+			return nil, err
+		}
+		for _, after := range f.after {
+			if err := after(tx, faker, &records[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return records, nil
+}
+
+// Has creates the related records of every record after it is inserted,
+// link sets the foreign key of the related record
+func Has[T, R any](factory Factory[T], related Factory[R], link func(record *T, related *R)) Factory[T] {
+	factory.after = append(
+		slices.Clip(factory.after), func(tx *gorm.DB, faker *gofakeit.Faker, record *T) error {
+			_, err := related.State(
+				func(_ *gofakeit.Faker, child *R) {
+					link(record, child)
+				},
+			).Create(tx, faker)
+			return err
+		},
+	)
+	return factory
+}
+
+// For creates the record every record belongs to before it is inserted,
+// link sets the foreign key of the record
+func For[T, P any](factory Factory[T], parent Factory[P], link func(record *T, parent *P)) Factory[T] {
+	factory.before = append(
+		slices.Clip(factory.before), func(tx *gorm.DB, faker *gofakeit.Faker, record *T) error {
+			parents, err := parent.Count(1).Create(tx, faker)
+			if err != nil {
+				return err
+			}
+			link(record, &parents[0])
+			return nil
+		},
+	)
+	return factory
+}
@@ -1,7 +1,9 @@
 package faker
 
 import (
-	_ "boilerplate-api/__mocks/mock_data"
+	"slices"
+	"strings"
+
 	"boilerplate-api/lib/config"
 	"gorm.io/gorm"
 )
@@ -44,48 +46,74 @@ func (f *Faker) Seed(data ...interface{}) error {
 	return nil
 }
 
+// UnSeed truncates every table except the migration versions and Config.SkipClean
 func (f *Faker) UnSeed() error {
 	f.logger.Info("UnSeeding data from database")
 
-	err := f.database.Transaction(
-		func(tx *gorm.DB) error {
-			err := tx.Exec("SET FOREIGN_KEY_CHECKS = 0;").Error
-			if err != nil {
-				return err
-			}
-			var statements []string
+	tables, err := f.database.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
 
-			tableQuery := tx.Table("information_schema.tables").
-				Select("CONCAT('TRUNCATE TABLE ', table_name, ';')").
-				Where("table_schema = DATABASE()")
+	skip := map[string]bool{"schema_migrations": true}
+	for _, table := range f.config.SkipClean {
+		skip[table] = true
+	}
+	var truncate []string
+	for _, table := range tables {
+		if !skip[table] && !strings.HasPrefix(table, "sqlite_") {
+			truncate = append(truncate, f.database.Statement.Quote(table))
+		}
+	}
+	if len(truncate) == 0 {
+		return nil
+	}
 
-			if len(f.config.SkipClean) > 0 {
-				tableQuery = tableQuery.Where("table_name NOT IN (?)", f.config.SkipClean)
-			}
+	switch f.database.Dialector.Name() {
+	case "postgres":
+		// foreign keys are truncated together
+		return f.database.Exec("TRUNCATE TABLE " + strings.Join(truncate, ", ") + " RESTART IDENTITY CASCADE").Error
+	case "sqlite":
+		// sqlite has no truncate, the foreign keys are checked on commit once every table is empty
+		return f.database.Transaction(
+			func(tx *gorm.DB) error {
+				if err := tx.Exec("PRAGMA defer_foreign_keys = ON").Error; err != nil {
+					return err
+				}
+				for _, table := range truncate {
+					if err := tx.Exec("DELETE FROM " + table).Error; err != nil {
+						return err
+					}
+				}
+				if !slices.Contains(tables, "sqlite_sequence") {
+					return nil
+				}
+				return tx.Exec("DELETE FROM sqlite_sequence").Error
+			},
+		)
+	}
 
-			err = tableQuery.
-				Scan(&statements).
-				Error
-			if err != nil {
+	// mysql commits each TRUNCATE on its own, FOREIGN_KEY_CHECKS is a session variable
+	// so the tables are truncated on one connection which gets the checks back before it is released
+	return f.database.Connection(
+		func(conn *gorm.DB) (err error) {
+			// new session so the reset runs even after a failed truncate left its error on conn
+			conn = conn.Session(&gorm.Session{})
+			if err := conn.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
 				return err
 			}
+			defer func() {
+				if resetErr := conn.Exec("SET FOREIGN_KEY_CHECKS = 1").Error; resetErr != nil && err == nil {
+					err = resetErr
+				}
+			}()
 
-			for _, statement := range statements {
-				statementErr := tx.Exec(statement).Error
-				if statementErr != nil {
+			for _, table := range truncate {
+				if err := conn.Exec("TRUNCATE TABLE " + table).Error; err != nil {
 					return err
 				}
 			}
-
-			err = tx.Exec("SET FOREIGN_KEY_CHECKS = 1;").Error
-			if err != nil {
-				return err
-			}
 			return nil
 		},
 	)
-	if err != nil {
-		return err
-	}
-	return nil
 }
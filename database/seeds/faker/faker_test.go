@@ -0,0 +1,33 @@
+package faker
+
+import (
+	"errors"
+	"testing"
+
+	"boilerplate-api/lib/config"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestUnSeedMySQLRestoresForeignKeyChecks(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	mock.ExpectQuery("SELECT DATABASE()").WillReturnRows(sqlmock.NewRows([]string{"DATABASE()"}).AddRow("app"))
+	mock.ExpectQuery("information_schema.tables").WillReturnRows(
+		sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("schema_migrations").AddRow("users").AddRow("roles"),
+	)
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("TRUNCATE TABLE `users`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("TRUNCATE TABLE `roles`").WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 1").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = NewFaker(db, config.GetLogger(), Config{}).UnSeed()
+	assert.EqualError(t, err, "lock wait timeout")
+	assert.NoError(t, mock.ExpectationsWereMet(), "checks are enabled again after a failed truncate")
+}
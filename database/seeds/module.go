@@ -1,26 +1,38 @@
 package seeds
 
 import (
-	"boilerplate-api/lib/config"
 	"go.uber.org/fx"
 )
 
-// Seed db seed
-type Seed interface {
-	Run()
-}
-
 // Module exports seed module
 var Module = fx.Module(
 	"seeds",
 	fx.Options(
-		//fx.Provide(
-		//	fx.Annotate(
-		//		NewAdminSeed,
-		//		fx.As(new(Seed)),
-		//		fx.ResultTags(`group:"seeds"`),
-		//	),
-		//),
+		fx.Provide(
+			NewRepository,
+			fx.Annotate(
+				NewSeeder,
+				fx.ParamTags(`group:"seeds"`),
+			),
+		),
+		fx.Provide(
+			fx.Annotate(
+				NewAdminSeed,
+				fx.As(new(Seed)),
+				fx.ResultTags(`group:"seeds"`),
+			),
+			fx.Annotate(
+				NewFakeUsersSeed,
+				fx.ResultTags(`group:"seeds"`),
+			),
+		),
+		fx.Provide(
+			fx.Annotate(
+				NewProjectBudgetTask,
+				fx.ParamTags(``, `optional:"true"`, ``),
+				fx.ResultTags(`group:"tasks"`),
+			),
+		),
 		//fx.Provide(
 		//	fx.Annotate(
 		//		NewProjectBudgetSeed,
@@ -28,19 +40,5 @@ var Module = fx.Module(
 		//		fx.ResultTags(`group:"seeds"`),
 		//	),
 		//),
-		fx.Invoke(
-			fx.Annotate(
-				SetupSeeds,
-				fx.ParamTags(`group:"seeds"`),
-			),
-		),
 	),
 )
-
-// SetupSeeds creates new seeds
-func SetupSeeds(seeds []Seed, logger config.Logger) {
-	logger.Info("🌱 seeding data...")
-	for _, seed := range seeds {
-		seed.Run()
-	}
-}
@@ -0,0 +1,58 @@
+package seeds
+
+import (
+	"context"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"gorm.io/gorm/clause"
+)
+
+// Repository runs of the seeds
+type Repository struct {
+	db     *config.Database
+	logger config.Logger
+}
+
+// NewRepository creates a new seed run repository
+func NewRepository(db *config.Database, logger config.Logger) Repository {
+	return Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetRuns recorded runs of every seed
+func (r Repository) GetRuns(ctx context.Context) (runs []dao.SeedRun, err error) {
+	return runs, r.db.Conn(ctx).Find(&runs).Error
+}
+
+// CreateRun records the run, false when it is already recorded.
+// Concurrent instances wait for the transaction that recorded it first
+func (r Repository) CreateRun(ctx context.Context, name constants.SeedName, ranAt time.Time) (bool, error) {
+	result := r.db.Conn(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&dao.SeedRun{Seed: string(name), RanAt: ranAt})
+	return result.RowsAffected > 0, result.Error
+}
+
+// SaveRun records the run, rerun updates the time
+func (r Repository) SaveRun(ctx context.Context, name constants.SeedName, ranAt time.Time) error {
+	return r.db.Conn(ctx).
+		Clauses(
+			clause.OnConflict{
+				Columns:   []clause.Column{{Name: "seed"}},
+				DoUpdates: clause.AssignmentColumns([]string{"ran_at"}),
+			},
+		).
+		Create(&dao.SeedRun{Seed: string(name), RanAt: ranAt}).
+		Error
+}
+
+// DeleteRun removes the run so the seed runs again
+func (r Repository) DeleteRun(ctx context.Context, name constants.SeedName) error {
+	return r.db.Conn(ctx).Where("seed = ?", name).Delete(&dao.SeedRun{}).Error
+}
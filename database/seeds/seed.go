@@ -0,0 +1,112 @@
+package seeds
+
+import (
+	"context"
+
+	"boilerplate-api/lib/constants"
+
+	"gorm.io/gorm"
+)
+
+// Seed inserts data once, seeds are provided to the `group:"seeds"`
+//
+// Run is called in a transaction with recording the run in seed_runs,
+// it runs again only when the seed is rerun or unseeded
+type Seed interface {
+	Name() constants.SeedName
+	Run(ctx context.Context, tx *gorm.DB) error
+}
+
+// Dependent seed runs after the seeds it depends on
+type Dependent interface {
+	DependsOn() []constants.SeedName
+}
+
+// Environmental seed runs only in the environments, in every environment otherwise
+type Environmental interface {
+	Environments() []constants.Environment
+}
+
+// Unseeder seed removes its data, seeds without it can't be unseeded
+type Unseeder interface {
+	Unseed(ctx context.Context, tx *gorm.DB) error
+}
+
+// Option configures seed created with New
+type Option func(*funcSeed)
+
+// DependsOn runs the seed after the seeds
+func DependsOn(names ...constants.SeedName) Option {
+	return func(s *funcSeed) {
+		s.dependsOn = append(s.dependsOn, names...)
+	}
+}
+
+// In runs the seed only in the environments
+func In(environments ...constants.Environment) Option {
+	return func(s *funcSeed) {
+		s.environments = append(s.environments, environments...)
+	}
+}
+
+// WithUnseed removes data of the seed when it is unseeded
+func WithUnseed(unseed func(ctx context.Context, tx *gorm.DB) error) Option {
+	return func(s *funcSeed) {
+		s.unseed = unseed
+	}
+}
+
+type funcSeed struct {
+	name         constants.SeedName
+	run          func(ctx context.Context, tx *gorm.DB) error
+	unseed       func(ctx context.Context, tx *gorm.DB) error
+	dependsOn    []constants.SeedName
+	environments []constants.Environment
+}
+
+// New creates seed of the function
+//
+//	seeds.New(constants.SeedNames.FakeUsers, run, seeds.In(constants.Environments.Local), seeds.WithUnseed(unseed))
+func New(
+	name constants.SeedName,
+	run func(ctx context.Context, tx *gorm.DB) error,
+	options ...Option,
+) Seed {
+	seed := &funcSeed{
+		name: name,
+		run:  run,
+	}
+	for _, option := range options {
+		option(seed)
+	}
+	return seed
+}
+
+func (s *funcSeed) Name() constants.SeedName {
+	return s.name
+}
+
+func (s *funcSeed) Run(ctx context.Context, tx *gorm.DB) error {
+	return s.run(ctx, tx)
+}
+
+func (s *funcSeed) Unseed(ctx context.Context, tx *gorm.DB) error {
+	return s.unseed(ctx, tx)
+}
+
+func (s *funcSeed) DependsOn() []constants.SeedName {
+	return s.dependsOn
+}
+
+func (s *funcSeed) Environments() []constants.Environment {
+	return s.environments
+}
+
+// unseeder Unseed of the seed, false when it can't be unseeded
+func unseeder(seed Seed) (Unseeder, bool) {
+	if s, ok := seed.(*funcSeed); ok {
+		return s, s.unseed != nil
+	}
+	u, ok := seed.(Unseeder)
+	return u, ok
+}
@@ -0,0 +1,315 @@
+package seeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+)
+
+var (
+	// ErrSeedNotFound no seed is registered with the name
+	ErrSeedNotFound = errors.New("seed not found")
+	// ErrSeedDisabled seed doesn't run in the environment
+	ErrSeedDisabled = errors.New("seed doesn't run in this environment")
+	// ErrSeedNotUnseedable seed doesn't implement Unseeder
+	ErrSeedNotUnseedable = errors.New("seed can't be unseeded")
+
+	// errAlreadyRan another instance ran the seed, rolls back the transaction
+	errAlreadyRan = errors.New("seed already ran")
+)
+
+// SeedStatus seed and its last run
+type SeedStatus struct {
+	Name         constants.SeedName
+	DependsOn    []constants.SeedName
+	Environments []constants.Environment
+	// Enabled runs in the current environment
+	Enabled    bool
+	Unseedable bool
+	// RanAt nil when it hasn't run
+	RanAt *time.Time
+}
+
+// Seeder runs the seeds after their dependencies and records the runs
+type Seeder struct {
+	seeds       map[constants.SeedName]Seed
+	names       []constants.SeedName
+	repository  Repository
+	database    *config.Database
+	unitOfWork  config.UnitOfWork
+	environment constants.Environment
+	logger      config.Logger
+}
+
+// NewSeeder creates seeder, fails on duplicate names, unknown dependencies and cycles
+func NewSeeder(
+	seeds []Seed,
+	repository Repository,
+	database *config.Database,
+	env config.Env,
+	logger config.Logger,
+) (*Seeder, error) {
+	seeder := &Seeder{
+		seeds:       make(map[constants.SeedName]Seed, len(seeds)),
+		repository:  repository,
+		database:    database,
+		unitOfWork:  config.NewUnitOfWork(database),
+		environment: constants.Environment(env.Environment),
+		logger:      logger,
+	}
+	for _, seed := range seeds {
+		if _, ok := seeder.seeds[seed.Name()]; ok {
+			return nil, fmt.Errorf("seed %s is registered twice", seed.Name())
+		}
+		seeder.seeds[seed.Name()] = seed
+		seeder.names = append(seeder.names, seed.Name())
+	}
+	slices.Sort(seeder.names)
+
+	if _, err := seeder.order(nil); err != nil {
+		return nil, err
+	}
+	return seeder, nil
+}
+
+// Run runs the seeds and their dependencies that haven't run in the environment, every seed without names
+func (s *Seeder) Run(ctx context.Context, names ...constants.SeedName) error {
+	return s.run(ctx, names, false)
+}
+
+// Rerun runs the seeds again, their dependencies run only when they haven't
+func (s *Seeder) Rerun(ctx context.Context, names ...constants.SeedName) error {
+	if len(names) == 0 {
+		return errors.New("seeds to rerun are required")
+	}
+	return s.run(ctx, names, true)
+}
+
+func (s *Seeder) run(ctx context.Context, names []constants.SeedName, rerun bool) error {
+	seeds, err := s.order(names)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		switch disabledBy := s.disabledBy(s.seeds[name]); disabledBy {
+		case "":
+		case name:
+			return fmt.Errorf("%w: %s in %s", ErrSeedDisabled, name, s.environment)
+		default:
+			return fmt.Errorf("%w: %s depends on %s in %s", ErrSeedDisabled, name, disabledBy, s.environment)
+		}
+	}
+	runs, err := s.runs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, seed := range seeds {
+		name := seed.Name()
+		if disabledBy := s.disabledBy(seed); disabledBy == name {
+			s.logger.Info("Skipping seed ", name, " of the other environments")
+			continue
+		} else if disabledBy != "" {
+			s.logger.Info("Skipping seed ", name, ", it depends on ", disabledBy, " of the other environments")
+			continue
+		}
+		if _, ran := runs[name]; ran && !(rerun && slices.Contains(names, name)) {
+			continue
+		}
+
+		s.logger.Info("🌱 seeding ", name, "...")
+		err := s.unitOfWork.Do(
+			ctx, func(ctx context.Context) error {
+				// recorded before running so another instance seeding at the same time waits and skips it
+				if rerun && slices.Contains(names, name) {
+					if err := s.repository.SaveRun(ctx, name, time.Now()); err != nil {
+						return err
+					}
+				} else if created, err := s.repository.CreateRun(ctx, name, time.Now()); err != nil || !created {
+					if err == nil {
+						err = errAlreadyRan
+					}
+					return err
+				}
+				return seed.Run(ctx, s.database.Conn(ctx))
+			},
+		)
+		if errors.Is(err, errAlreadyRan) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("seed %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Unseed removes data of the seeds and the seeds depending on them, of every seed without names
+func (s *Seeder) Unseed(ctx context.Context, names ...constants.SeedName) error {
+	if _, err := s.order(names); err != nil {
+		return err
+	}
+	seeds, err := s.order(nil)
+	if err != nil {
+		return err
+	}
+	runs, err := s.runs(ctx)
+	if err != nil {
+		return err
+	}
+
+	// dependents come after their dependencies in the order
+	targets := map[constants.SeedName]bool{}
+	for _, seed := range seeds {
+		targets[seed.Name()] = len(names) == 0 || slices.Contains(names, seed.Name())
+		for _, dependency := range dependsOn(seed) {
+			targets[seed.Name()] = targets[seed.Name()] || targets[dependency]
+		}
+	}
+
+	var unseed []Seed
+	for i := len(seeds) - 1; i >= 0; i-- {
+		seed := seeds[i]
+		if _, ran := runs[seed.Name()]; !ran || !targets[seed.Name()] {
+			continue
+		}
+		if _, ok := unseeder(seed); !ok {
+			return fmt.Errorf("%w: %s", ErrSeedNotUnseedable, seed.Name())
+		}
+		unseed = append(unseed, seed)
+	}
+
+	for _, seed := range unseed {
+		name := seed.Name()
+		s.logger.Info("🧹 unseeding ", name, "...")
+		seedUnseeder, _ := unseeder(seed)
+		err := s.unitOfWork.Do(
+			ctx, func(ctx context.Context) error {
+				if err := seedUnseeder.Unseed(ctx, s.database.Conn(ctx)); err != nil {
+					return err
+				}
+				return s.repository.DeleteRun(ctx, name)
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unseed %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Status seeds in the run order with their last run
+func (s *Seeder) Status(ctx context.Context) ([]SeedStatus, error) {
+	seeds, err := s.order(nil)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := s.runs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]SeedStatus, 0, len(seeds))
+	for _, seed := range seeds {
+		status := SeedStatus{
+			Name:      seed.Name(),
+			DependsOn: dependsOn(seed),
+			Enabled:   s.enabled(seed),
+		}
+		if environmental, ok := seed.(Environmental); ok {
+			status.Environments = environmental.Environments()
+		}
+		_, status.Unseedable = unseeder(seed)
+		if ranAt, ok := runs[seed.Name()]; ok {
+			status.RanAt = &ranAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// order seeds of the names after their dependencies, every seed without names
+func (s *Seeder) order(names []constants.SeedName) ([]Seed, error) {
+	if len(names) == 0 {
+		names = s.names
+	}
+	const visiting, visited = 1, 2
+	state := map[constants.SeedName]int{}
+	var ordered []Seed
+
+	var visit func(name constants.SeedName) error
+	visit = func(name constants.SeedName) error {
+		seed, ok := s.seeds[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrSeedNotFound, name)
+		}
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("seed %s depends on itself", name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dependency := range dependsOn(seed) {
+			if err := visit(dependency); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, seed)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// enabled runs in the environment, a seed depending on a seed of the other environments doesn't run either
+func (s *Seeder) enabled(seed Seed) bool {
+	return s.disabledBy(seed) == ""
+}
+
+// disabledBy the seed itself or the dependency of the other environments, empty when it runs in the environment
+func (s *Seeder) disabledBy(seed Seed) constants.SeedName {
+	environmental, ok := seed.(Environmental)
+	if ok && len(environmental.Environments()) > 0 && !slices.Contains(environmental.Environments(), s.environment) {
+		return seed.Name()
+	}
+	for _, dependency := range dependsOn(seed) {
+		if disabledBy := s.disabledBy(s.seeds[dependency]); disabledBy != "" {
+			return disabledBy
+		}
+	}
+	return ""
+}
+
+func (s *Seeder) runs(ctx context.Context) (map[constants.SeedName]time.Time, error) {
+	runs, err := s.repository.GetRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ranAt := make(map[constants.SeedName]time.Time, len(runs))
+	for _, run := range runs {
+		ranAt[constants.SeedName(run.Seed)] = run.RanAt
+	}
+	return ranAt, nil
+}
+
+func dependsOn(seed Seed) []constants.SeedName {
+	dependent, ok := seed.(Dependent)
+	if !ok {
+		return nil
+	}
+	dependencies := slices.Clone(dependent.DependsOn())
+	slices.Sort(dependencies)
+	return dependencies
+}
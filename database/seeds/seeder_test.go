@@ -0,0 +1,161 @@
+package seeds
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/tests"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func testSeed(name constants.SeedName, options ...Option) Seed {
+	return New(
+		name, func(context.Context, *gorm.DB) error {
+			return nil
+		}, options...,
+	)
+}
+
+func seedNames(seeds []Seed) []constants.SeedName {
+	names := make([]constants.SeedName, len(seeds))
+	for i, seed := range seeds {
+		names[i] = seed.Name()
+	}
+	return names
+}
+
+func TestSeederOrder(t *testing.T) {
+	seeder, err := NewSeeder(
+		[]Seed{
+			testSeed("posts", DependsOn("users")),
+			testSeed("comments", DependsOn("users", "posts")),
+			testSeed("users", DependsOn("roles")),
+			testSeed("roles"),
+		},
+		Repository{},
+		&config.Database{},
+		config.Env{Environment: "local"},
+		config.Logger{},
+	)
+	assert.NoError(t, err)
+
+	seeds, err := seeder.order(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []constants.SeedName{"roles", "users", "posts", "comments"}, seedNames(seeds))
+
+	seeds, err = seeder.order([]constants.SeedName{"posts"})
+	assert.NoError(t, err)
+	assert.Equal(t, []constants.SeedName{"roles", "users", "posts"}, seedNames(seeds))
+
+	_, err = seeder.order([]constants.SeedName{"unknown"})
+	assert.True(t, errors.Is(err, ErrSeedNotFound))
+}
+
+func TestNewSeederValidation(t *testing.T) {
+	newSeeder := func(seeds ...Seed) error {
+		_, err := NewSeeder(seeds, Repository{}, &config.Database{}, config.Env{}, config.Logger{})
+		return err
+	}
+
+	assert.Error(t, newSeeder(testSeed("users"), testSeed("users")))
+	assert.True(t, errors.Is(newSeeder(testSeed("users", DependsOn("roles"))), ErrSeedNotFound))
+	assert.ErrorContains(
+		t,
+		newSeeder(testSeed("users", DependsOn("roles")), testSeed("roles", DependsOn("users"))),
+		"depends on itself",
+	)
+}
+
+func TestSeederEnvironments(t *testing.T) {
+	local := testSeed("local", In(constants.Environments.Local))
+	everywhere := testSeed("everywhere")
+
+	seeder, err := NewSeeder([]Seed{local, everywhere}, Repository{}, &config.Database{}, config.Env{Environment: "production"}, config.Logger{})
+	assert.NoError(t, err)
+	assert.False(t, seeder.enabled(local))
+	assert.True(t, seeder.enabled(everywhere))
+	assert.True(t, errors.Is(seeder.Run(context.Background(), "local"), ErrSeedDisabled))
+
+	_, ok := unseeder(local)
+	assert.False(t, ok)
+	_, ok = unseeder(testSeed("unseedable", WithUnseed(func(context.Context, *gorm.DB) error { return nil })))
+	assert.True(t, ok)
+}
+
+func TestSeederDisabledDependency(t *testing.T) {
+	db := tests.NewDatabase(t)
+	var ran []constants.SeedName
+	runSeed := func(name constants.SeedName, options ...Option) Seed {
+		return New(
+			name, func(context.Context, *gorm.DB) error {
+				ran = append(ran, name)
+				return nil
+			}, options...,
+		)
+	}
+
+	seeder, err := NewSeeder(
+		[]Seed{
+			runSeed("roles", In(constants.Environments.Local)),
+			runSeed("users", DependsOn("roles")),
+			runSeed("posts", DependsOn("users")),
+			runSeed("settings"),
+		},
+		NewRepository(db, config.GetLogger()),
+		db,
+		config.Env{Environment: "production"},
+		config.GetLogger(),
+	)
+	assert.NoError(t, err)
+
+	// dependents of a seed of the other environments are skipped with it
+	assert.NoError(t, seeder.Run(context.Background()))
+	assert.Equal(t, []constants.SeedName{"settings"}, ran)
+
+	err = seeder.Run(context.Background(), "posts")
+	assert.True(t, errors.Is(err, ErrSeedDisabled))
+	assert.ErrorContains(t, err, "posts depends on roles")
+
+	statuses, err := seeder.Status(context.Background())
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.Equal(t, status.Name == "settings", status.Enabled, status.Name)
+	}
+}
+
+func TestSeederRunFakeUsers(t *testing.T) {
+	db := tests.NewDatabase(t)
+	seeder, err := NewSeeder(
+		[]Seed{NewFakeUsersSeed()},
+		NewRepository(db, config.GetLogger()),
+		db,
+		config.Env{Environment: "local"},
+		config.GetLogger(),
+	)
+	assert.NoError(t, err)
+
+	countUsers := func() (count int64) {
+		assert.NoError(t, db.Model(&dao.User{}).Count(&count).Error)
+		return count
+	}
+
+	assert.NoError(t, seeder.Run(context.Background()))
+	assert.EqualValues(t, 10, countUsers())
+
+	assert.NoError(t, seeder.Run(context.Background()), "recorded runs are skipped")
+	assert.EqualValues(t, 10, countUsers())
+
+	statuses, err := seeder.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 1)
+	assert.NotNil(t, statuses[0].RanAt)
+
+	assert.NoError(t, seeder.Unseed(context.Background(), constants.SeedNames.FakeUsers))
+	assert.Zero(t, countUsers())
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"boilerplate-api/lib/constants"
+)
+
+// EnqueueOption configures job queued with Client.Enqueue
+type EnqueueOption func(*Job)
+
+// Delay runs the job after the delay
+func Delay(delay time.Duration) EnqueueOption {
+	return func(job *Job) {
+		job.RunAt = time.Now().Add(delay)
+	}
+}
+
+// At runs the job at the time
+func At(runAt time.Time) EnqueueOption {
+	return func(job *Job) {
+		job.RunAt = runAt
+	}
+}
+
+// MaxAttempts overrides how many times the job is tried before it is buried
+func MaxAttempts(attempts uint32) EnqueueOption {
+	return func(job *Job) {
+		job.MaxAttempts = attempts
+	}
+}
+
+// Client queues jobs, workers run them in the `worker` mode
+type Client struct {
+	queue Queue
+}
+
+// NewClient creates new job client
+func NewClient(queue Queue) Client {
+	return Client{queue: queue}
+}
+
+// Enqueue queues the job with json encoded payload, returns id of the job
+func (c Client) Enqueue(
+	ctx context.Context,
+	name constants.JobName,
+	payload interface{},
+	options ...EnqueueOption,
+) (string, error) {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("payload of %s: %w", name, err)
+	}
+
+	job := Job{
+		Name:        name,
+		Payload:     content,
+		MaxAttempts: constants.JobMaxAttempts,
+		RunAt:       time.Now(),
+	}
+	for _, option := range options {
+		option(&job)
+	}
+	return c.queue.Push(ctx, job)
+}
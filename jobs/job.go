@@ -0,0 +1,114 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"boilerplate-api/lib/constants"
+)
+
+// ErrPermanent retrying can't succeed, e.g. invalid payload, the job is buried right away
+var ErrPermanent = errors.New("permanent failure")
+
+// Job queued unit of work
+type Job struct {
+	// ID assigned by the queue
+	ID          string
+	Name        constants.JobName
+	Payload     json.RawMessage
+	Attempts    uint32
+	MaxAttempts uint32
+	RunAt       time.Time
+	LastError   string
+}
+
+// Handler runs jobs of the name, handlers are provided to the `group:"jobs"`
+//
+// jobs run at least once, Handle can be called again after it succeeded
+type Handler interface {
+	Name() constants.JobName
+	Handle(ctx context.Context, job Job) error
+}
+
+// Limited handler runs at most Concurrency jobs at once in a worker, others are claimed once a slot is free
+type Limited interface {
+	Concurrency() int
+}
+
+// Timeouter handler overrides the default timeout of its jobs, it is capped to constants.JobMaxTimeout
+type Timeouter interface {
+	Timeout() time.Duration
+}
+
+// Permanent marks the error as not retryable
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
+}
+
+// HandlerOption configures handler created with NewHandler
+type HandlerOption func(*typedHandler)
+
+// WithConcurrency limits concurrent jobs of the handler
+func WithConcurrency(concurrency int) HandlerOption {
+	return func(h *typedHandler) {
+		h.concurrency = concurrency
+	}
+}
+
+// WithTimeout cancels context of the job after the timeout, capped to constants.JobMaxTimeout
+func WithTimeout(timeout time.Duration) HandlerOption {
+	return func(h *typedHandler) {
+		h.timeout = timeout
+	}
+}
+
+type typedHandler struct {
+	name        constants.JobName
+	handle      func(ctx context.Context, job Job) error
+	concurrency int
+	timeout     time.Duration
+}
+
+// NewHandler creates handler decoding json payload of the job into T
+//
+//	jobs.NewHandler(constants.JobNames.SyncSubscription, func(ctx context.Context, payload SyncPayload) error {...})
+func NewHandler[T any](
+	name constants.JobName,
+	handle func(ctx context.Context, payload T) error,
+	options ...HandlerOption,
+) Handler {
+	handler := &typedHandler{
+		name: name,
+		handle: func(ctx context.Context, job Job) error {
+			var payload T
+			if err := json.Unmarshal(job.Payload, &payload); err != nil {
+				return Permanent(fmt.Errorf("payload of %s: %w", name, err))
+			}
+			return handle(ctx, payload)
+		},
+		timeout: constants.JobDefaultTimeout,
+	}
+	for _, option := range options {
+		option(handler)
+	}
+	return handler
+}
+
+func (h *typedHandler) Name() constants.JobName {
+	return h.name
+}
+
+func (h *typedHandler) Handle(ctx context.Context, job Job) error {
+	return h.handle(ctx, job)
+}
+
+func (h *typedHandler) Concurrency() int {
+	return h.concurrency
+}
+
+func (h *typedHandler) Timeout() time.Duration {
+	return h.timeout
+}
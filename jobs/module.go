@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"jobs",
+	fx.Options(
+		// Queue provider, only the driver selected by QUEUE_DRIVER is created
+		fx.Provide(
+			func(
+				lifecycle fx.Lifecycle,
+				env config.Env,
+				logger config.Logger,
+				db *config.Database,
+			) Queue {
+				switch constants.QueueDriver(env.QueueDriver) {
+				case constants.QueueDrivers.MySQL, "":
+					return NewMySQLQueue(db)
+				case constants.QueueDrivers.Redis:
+					client := redis.NewClient(
+						&redis.Options{
+							Addr:     env.RedisAddr,
+							Password: env.RedisPassword,
+							DB:       env.RedisDB,
+						},
+					)
+					lifecycle.Append(
+						fx.Hook{
+							OnStop: func(context.Context) error {
+								return client.Close()
+							},
+						},
+					)
+					logger.Info("✅ Redis job queue created.")
+					return NewRedisQueue(client)
+				default:
+					logger.Fatal("Unknown QUEUE_DRIVER: ", env.QueueDriver)
+					return nil
+				}
+			},
+		),
+		fx.Provide(
+			NewClient,
+			fx.Annotate(
+				NewWorker,
+				fx.ParamTags(``, ``, ``, `group:"jobs"`),
+			),
+		),
+	),
+)
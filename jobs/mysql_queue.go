@@ -0,0 +1,143 @@
+package jobs
+
+import (
+	"context"
+	"maps"
+	"strconv"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLQueue queue in the jobs table, pending jobs are kept until they complete
+type MySQLQueue struct {
+	db *config.Database
+}
+
+// NewMySQLQueue creates new mysql queue
+func NewMySQLQueue(db *config.Database) MySQLQueue {
+	return MySQLQueue{db: db}
+}
+
+func (q MySQLQueue) Push(ctx context.Context, job Job) (string, error) {
+	record := dao.Job{
+		Name:        string(job.Name),
+		Payload:     string(job.Payload),
+		Status:      string(constants.JobStatuses.Pending),
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+	}
+	if err := q.db.Conn(ctx).Create(&record).Error; err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(record.ID, 10), nil
+}
+
+func (q MySQLQueue) Claim(
+	ctx context.Context,
+	limit int,
+	capped map[constants.JobName]int,
+	lease time.Duration,
+) (jobs []Job, err error) {
+	now := time.Now()
+	var full []string
+	for name, free := range capped {
+		if free <= 0 {
+			full = append(full, string(name))
+		}
+	}
+
+	err = q.db.Conn(ctx).Transaction(
+		func(tx *gorm.DB) error {
+			query := tx.
+				Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status IN ?", []constants.JobStatus{constants.JobStatuses.Pending, constants.JobStatuses.Running}).
+				Where("run_at <= ?", now)
+			if len(full) > 0 {
+				query = query.Where("name NOT IN ?", full)
+			}
+			var records []dao.Job
+			err := query.
+				Order("run_at").
+				Limit(max(limit, claimScanSize)).
+				Find(&records).
+				Error
+			if err != nil || len(records) == 0 {
+				return err
+			}
+
+			// rows which are not claimed are unlocked with the commit
+			remaining := maps.Clone(capped)
+			var ids []uint64
+			for _, record := range records {
+				if len(ids) == limit {
+					break
+				}
+				name := constants.JobName(record.Name)
+				if free, ok := remaining[name]; ok {
+					if free <= 0 {
+						continue
+					}
+					remaining[name] = free - 1
+				}
+				ids = append(ids, record.ID)
+				jobs = append(
+					jobs, Job{
+						ID:          strconv.FormatUint(record.ID, 10),
+						Name:        name,
+						Payload:     []byte(record.Payload),
+						Attempts:    record.Attempts + 1,
+						MaxAttempts: record.MaxAttempts,
+						RunAt:       record.RunAt,
+					},
+				)
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+			return tx.Model(&dao.Job{}).
+				Where("id IN ?", ids).
+				Updates(
+					map[string]interface{}{
+						"status":     constants.JobStatuses.Running,
+						"attempts":   gorm.Expr("attempts + 1"),
+						"run_at":     now.Add(lease),
+						"updated_at": now,
+					},
+				).
+				Error
+		},
+	)
+	return jobs, err
+}
+
+func (q MySQLQueue) Complete(ctx context.Context, job Job) error {
+	return q.db.Conn(ctx).Where("id = ?", job.ID).Delete(&dao.Job{}).Error
+}
+
+func (q MySQLQueue) Retry(ctx context.Context, job Job, runAt time.Time) error {
+	return q.update(ctx, job, constants.JobStatuses.Pending, runAt)
+}
+
+func (q MySQLQueue) Bury(ctx context.Context, job Job) error {
+	return q.update(ctx, job, constants.JobStatuses.Dead, time.Now())
+}
+
+func (q MySQLQueue) update(ctx context.Context, job Job, status constants.JobStatus, runAt time.Time) error {
+	return q.db.Conn(ctx).Model(&dao.Job{}).
+		Where("id = ?", job.ID).
+		Updates(
+			map[string]interface{}{
+				"status":     status,
+				"run_at":     runAt,
+				"last_error": job.LastError,
+				"updated_at": time.Now(),
+			},
+		).
+		Error
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"boilerplate-api/lib/constants"
+)
+
+// claimScanSize due jobs looked at by a claim, jobs of capped names are skipped so more than limit are scanned
+const claimScanSize = 100
+
+// Queue stores jobs until they are due, implementations must be safe for several workers
+type Queue interface {
+	// Push queues the job to run at its RunAt, returns id of the job
+	Push(ctx context.Context, job Job) (string, error)
+	// Claim hides up to limit due jobs from other workers for the lease and increments their attempts,
+	// jobs whose lease expired are claimed again. capped limits jobs claimed of the names,
+	// e.g. to the free slots of limited handlers, other names are limited by limit only
+	Claim(ctx context.Context, limit int, capped map[constants.JobName]int, lease time.Duration) ([]Job, error)
+	// Complete removes the finished job
+	Complete(ctx context.Context, job Job) error
+	// Retry queues the failed job again at runAt
+	Retry(ctx context.Context, job Job, runAt time.Time) error
+	// Bury keeps the job which failed for good for inspection
+	Bury(ctx context.Context, job Job) error
+}
@@ -0,0 +1,212 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"boilerplate-api/lib/constants"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keys share the {jobs} hash tag so the scripts work on redis cluster
+const (
+	redisJobID         = "{jobs}:id"
+	redisJobData       = "{jobs}:data"
+	redisJobAttempts   = "{jobs}:attempts"
+	redisJobScheduled  = "{jobs}:scheduled"
+	redisJobProcessing = "{jobs}:processing"
+	redisJobDead       = "{jobs}:dead"
+)
+
+// claimScript moves due jobs and jobs with expired lease to processing, returns id, attempts and data of each
+//
+// ARGV[5:] are name, free pairs of the capped names, their jobs are skipped when no more are free
+var claimScript = redis.NewScript(`
+local limit = tonumber(ARGV[2])
+local capped = {}
+for i = 5, #ARGV, 2 do
+	capped[ARGV[i]] = tonumber(ARGV[i + 1])
+end
+
+local ids = {}
+local function pick(key, remove)
+	local candidates = redis.call('ZRANGEBYSCORE', key, '-inf', ARGV[1], 'LIMIT', 0, ARGV[4])
+	for _, id in ipairs(candidates) do
+		if #ids >= limit then
+			return
+		end
+		local data = redis.call('HGET', KEYS[3], id)
+		local name = ''
+		if data then
+			name = cjson.decode(data).name
+		end
+		local free = capped[name]
+		if free == nil or free > 0 then
+			if free ~= nil then
+				capped[name] = free - 1
+			end
+			if remove then
+				redis.call('ZREM', key, id)
+			end
+			table.insert(ids, id)
+		end
+	end
+end
+pick(KEYS[2], false)
+pick(KEYS[1], true)
+
+local result = {}
+for _, id in ipairs(ids) do
+	redis.call('ZADD', KEYS[2], ARGV[3], id)
+	table.insert(result, id)
+	table.insert(result, redis.call('HINCRBY', KEYS[4], id, 1))
+	table.insert(result, redis.call('HGET', KEYS[3], id) or '')
+end
+return result
+`)
+
+// redisJob job stored in the data hash, attempts are counted separately by the claim script
+type redisJob struct {
+	Name        constants.JobName `json:"name"`
+	Payload     json.RawMessage   `json:"payload"`
+	MaxAttempts uint32            `json:"max_attempts"`
+	RunAt       time.Time         `json:"run_at"`
+	LastError   string            `json:"last_error,omitempty"`
+}
+
+// RedisQueue queue in redis sorted sets scored by run time
+type RedisQueue struct {
+	client redis.UniversalClient
+}
+
+// NewRedisQueue creates new redis queue
+func NewRedisQueue(client redis.UniversalClient) RedisQueue {
+	return RedisQueue{client: client}
+}
+
+func (q RedisQueue) Push(ctx context.Context, job Job) (string, error) {
+	id, err := q.client.Incr(ctx, redisJobID).Result()
+	if err != nil {
+		return "", err
+	}
+	job.ID = strconv.FormatInt(id, 10)
+
+	data, err := q.encode(job)
+	if err != nil {
+		return "", err
+	}
+	_, err = q.client.TxPipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, redisJobData, job.ID, data)
+			pipe.ZAdd(ctx, redisJobScheduled, redis.Z{Score: score(job.RunAt), Member: job.ID})
+			return nil
+		},
+	)
+	return job.ID, err
+}
+
+func (q RedisQueue) Claim(
+	ctx context.Context,
+	limit int,
+	capped map[constants.JobName]int,
+	lease time.Duration,
+) ([]Job, error) {
+	now := time.Now()
+	args := []interface{}{score(now), limit, score(now.Add(lease)), max(limit, claimScanSize)}
+	for name, free := range capped {
+		args = append(args, string(name), free)
+	}
+	result, err := claimScript.Run(
+		ctx,
+		q.client,
+		[]string{redisJobScheduled, redisJobProcessing, redisJobData, redisJobAttempts},
+		args...,
+	).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]Job, 0, len(result)/3)
+	for i := 0; i+2 < len(result); i += 3 {
+		id, _ := result[i].(string)
+		attempts, _ := result[i+1].(int64)
+		data, _ := result[i+2].(string)
+
+		stored := redisJob{}
+		if err := json.Unmarshal([]byte(data), &stored); err != nil {
+			// data of a completed job is gone, drop the stale id
+			q.client.ZRem(ctx, redisJobProcessing, id)
+			continue
+		}
+		jobs = append(
+			jobs, Job{
+				ID:          id,
+				Name:        stored.Name,
+				Payload:     stored.Payload,
+				Attempts:    uint32(attempts),
+				MaxAttempts: stored.MaxAttempts,
+				RunAt:       stored.RunAt,
+				LastError:   stored.LastError,
+			},
+		)
+	}
+	return jobs, nil
+}
+
+func (q RedisQueue) Complete(ctx context.Context, job Job) error {
+	_, err := q.client.TxPipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRem(ctx, redisJobProcessing, job.ID)
+			pipe.HDel(ctx, redisJobData, job.ID)
+			pipe.HDel(ctx, redisJobAttempts, job.ID)
+			return nil
+		},
+	)
+	return err
+}
+
+func (q RedisQueue) Retry(ctx context.Context, job Job, runAt time.Time) error {
+	return q.move(ctx, job, redisJobScheduled, runAt)
+}
+
+func (q RedisQueue) Bury(ctx context.Context, job Job) error {
+	return q.move(ctx, job, redisJobDead, time.Now())
+}
+
+// move moves the job from processing to the sorted set, with the last error saved
+func (q RedisQueue) move(ctx context.Context, job Job, key string, at time.Time) error {
+	data, err := q.encode(job)
+	if err != nil {
+		return err
+	}
+	_, err = q.client.TxPipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRem(ctx, redisJobProcessing, job.ID)
+			pipe.HSet(ctx, redisJobData, job.ID, data)
+			pipe.ZAdd(ctx, key, redis.Z{Score: score(at), Member: job.ID})
+			return nil
+		},
+	)
+	return err
+}
+
+func (q RedisQueue) encode(job Job) (string, error) {
+	data, err := json.Marshal(
+		redisJob{
+			Name:        job.Name,
+			Payload:     job.Payload,
+			MaxAttempts: job.MaxAttempts,
+			RunAt:       job.RunAt,
+			LastError:   job.LastError,
+		},
+	)
+	return string(data), err
+}
+
+// score unix milliseconds, sorted sets order jobs by it
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
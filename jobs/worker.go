@@ -0,0 +1,208 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/utils"
+)
+
+// registration handler with its concurrency slots
+type registration struct {
+	handler Handler
+	timeout time.Duration
+	// slots nil when the handler is not limited
+	slots chan struct{}
+}
+
+// Worker claims due jobs and runs up to concurrency of them at once
+type Worker struct {
+	logger      config.Logger
+	queue       Queue
+	handlers    map[constants.JobName]registration
+	concurrency int
+
+	stopPolling context.CancelFunc
+	stopJobs    context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// NewWorker creates new worker, it is started in the worker mode
+func NewWorker(logger config.Logger, env config.Env, queue Queue, handlers []Handler) *Worker {
+	worker := &Worker{
+		logger:      logger,
+		queue:       queue,
+		handlers:    map[constants.JobName]registration{},
+		concurrency: env.WorkerConcurrency,
+	}
+	if worker.concurrency <= 0 {
+		worker.concurrency = constants.JobDefaultConcurrency
+	}
+
+	for _, handler := range handlers {
+		if _, ok := worker.handlers[handler.Name()]; ok {
+			logger.Fatal("Job handler registered twice: ", handler.Name())
+		}
+		registered := registration{handler: handler, timeout: constants.JobDefaultTimeout}
+		if limited, ok := handler.(Limited); ok && limited.Concurrency() > 0 {
+			registered.slots = make(chan struct{}, limited.Concurrency())
+		}
+		if timeouter, ok := handler.(Timeouter); ok && timeouter.Timeout() > 0 {
+			registered.timeout = timeouter.Timeout()
+		}
+		// a job running past its lease is claimed and run again by another worker
+		if registered.timeout > constants.JobMaxTimeout {
+			logger.Warn(
+				"Timeout of job handler ", handler.Name(), " is capped to ", constants.JobMaxTimeout,
+				", it must be shorter than the lease",
+			)
+			registered.timeout = constants.JobMaxTimeout
+		}
+		worker.handlers[handler.Name()] = registered
+	}
+	return worker
+}
+
+// Start claims jobs in background until Stop is called
+func (w *Worker) Start() {
+	pollCtx, stopPolling := context.WithCancel(context.Background())
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	w.stopPolling, w.stopJobs = stopPolling, stopJobs
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.logger.Info("✅ Worker started with ", len(w.handlers), " handlers, concurrency ", w.concurrency)
+
+		slots := make(chan struct{}, w.concurrency)
+		ticker := time.NewTicker(constants.JobPollInterval)
+		defer ticker.Stop()
+		for {
+			if free := cap(slots) - len(slots); free > 0 {
+				jobs, err := w.queue.Claim(pollCtx, free, w.capped(), constants.JobLease)
+				if err != nil && !errors.Is(err, context.Canceled) {
+					w.logger.Error("Error claiming jobs: ", err.Error())
+				}
+				for _, job := range jobs {
+					// claimed jobs fit the free slots, taking them doesn't block
+					slots <- struct{}{}
+					registered, ok := w.handlers[job.Name]
+					if ok && registered.slots != nil {
+						registered.slots <- struct{}{}
+					}
+					w.wg.Add(1)
+					go func(job Job) {
+						defer w.wg.Done()
+						defer func() { <-slots }()
+						if ok && registered.slots != nil {
+							defer func() { <-registered.slots }()
+						}
+						w.run(jobCtx, job)
+					}(job)
+				}
+				// more jobs may be due, claim again without waiting
+				if err == nil && len(jobs) == free && len(slots) < cap(slots) {
+					continue
+				}
+			}
+
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops claiming and waits for running jobs, they are canceled when ctx is done
+func (w *Worker) Stop(ctx context.Context) error {
+	if w.stopPolling == nil {
+		return nil
+	}
+	w.stopPolling()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		w.logger.Info("Worker stopped")
+		return nil
+	case <-ctx.Done():
+		// canceled jobs are claimed again after the lease
+		w.stopJobs()
+		return ctx.Err()
+	}
+}
+
+// capped free slots of the limited handlers, jobs of full handlers are not claimed
+// so they don't wait for a slot while their lease runs out
+func (w *Worker) capped() map[constants.JobName]int {
+	capped := map[constants.JobName]int{}
+	for name, registered := range w.handlers {
+		if registered.slots != nil {
+			capped[name] = cap(registered.slots) - len(registered.slots)
+		}
+	}
+	return capped
+}
+
+// run runs the job with a slot of its handler taken and completes, retries or buries it
+func (w *Worker) run(ctx context.Context, job Job) {
+	registered, ok := w.handlers[job.Name]
+	if !ok {
+		// jobs can be queued before every worker is deployed with the handler, so it is retried
+		w.failed(ctx, job, fmt.Errorf("no handler for job %q", job.Name))
+		return
+	}
+
+	if err := w.handle(ctx, registered, job); err != nil {
+		w.failed(ctx, job, err)
+		return
+	}
+	if err := w.queue.Complete(context.WithoutCancel(ctx), job); err != nil {
+		// the job runs again after the lease
+		w.logger.Error("Error completing job ", job.Name, " ", job.ID, ": ", err.Error())
+	}
+}
+
+// handle runs the handler with timeout, panics are returned as errors
+func (w *Worker) handle(ctx context.Context, registered registration, job Job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, registered.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return registered.handler.Handle(ctx, job)
+}
+
+// failed queues the job again with backoff, or buries it when attempts are exhausted
+func (w *Worker) failed(ctx context.Context, job Job, err error) {
+	ctx = context.WithoutCancel(ctx)
+	job.LastError = err.Error()
+
+	if job.Attempts >= job.MaxAttempts || errors.Is(err, ErrPermanent) {
+		w.logger.Error("Job ", job.Name, " ", job.ID, " failed for good: ", err.Error())
+		if err := w.queue.Bury(ctx, job); err != nil {
+			w.logger.Error("Error burying job ", job.ID, ": ", err.Error())
+		}
+		return
+	}
+
+	runAt := time.Now().Add(utils.Backoff(job.Attempts, constants.JobBaseBackoff, constants.JobMaxBackoff))
+	w.logger.Info("Job ", job.Name, " ", job.ID, " failed, retrying at ", runAt, ": ", err.Error())
+	if err := w.queue.Retry(ctx, job, runAt); err != nil {
+		w.logger.Error("Error retrying job ", job.ID, ": ", err.Error())
+	}
+}
@@ -0,0 +1,172 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPayload struct {
+	Fail bool `json:"fail"`
+}
+
+func newTestRedisQueue(t *testing.T) (RedisQueue, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	return NewRedisQueue(redis.NewClient(&redis.Options{Addr: server.Addr()})), server
+}
+
+func TestRedisQueue(t *testing.T) {
+	ctx := context.Background()
+	queue, server := newTestRedisQueue(t)
+	client := NewClient(queue)
+
+	id, err := client.Enqueue(ctx, "test", testPayload{}, MaxAttempts(3))
+	assert.NoError(t, err)
+	_, err = client.Enqueue(ctx, "test", testPayload{}, Delay(time.Hour))
+	assert.NoError(t, err)
+
+	jobs, err := queue.Claim(ctx, 10, nil, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1, "delayed job is not due")
+	assert.Equal(t, id, jobs[0].ID)
+	assert.Equal(t, uint32(1), jobs[0].Attempts)
+	assert.Equal(t, uint32(3), jobs[0].MaxAttempts)
+	assert.JSONEq(t, `{"fail":false}`, string(jobs[0].Payload))
+
+	jobs, err = queue.Claim(ctx, 10, nil, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, jobs, "claimed job is hidden for the lease")
+
+	// lease expires, e.g. the worker crashed
+	_, _ = server.ZAdd(redisJobProcessing, 0, id)
+	jobs, err = queue.Claim(ctx, 10, nil, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, uint32(2), jobs[0].Attempts)
+
+	jobs[0].LastError = "boom"
+	assert.NoError(t, queue.Bury(ctx, jobs[0]))
+	dead, _ := server.ZMembers(redisJobDead)
+	assert.Equal(t, []string{id}, dead)
+}
+
+func TestRedisQueueClaimCapped(t *testing.T) {
+	ctx := context.Background()
+	queue, _ := newTestRedisQueue(t)
+	client := NewClient(queue)
+	for i := 0; i < 3; i++ {
+		_, _ = client.Enqueue(ctx, "limited", testPayload{})
+	}
+	_, _ = client.Enqueue(ctx, "full", testPayload{})
+	_, _ = client.Enqueue(ctx, "other", testPayload{})
+
+	capped := map[constants.JobName]int{"limited": 1, "full": 0}
+	jobs, err := queue.Claim(ctx, 10, capped, time.Minute)
+	assert.NoError(t, err)
+	names := make([]constants.JobName, 0, len(jobs))
+	for _, job := range jobs {
+		names = append(names, job.Name)
+	}
+	assert.ElementsMatch(t, []constants.JobName{"limited", "other"}, names, "jobs of full handlers stay queued")
+
+	jobs, err = queue.Claim(ctx, 1, nil, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestWorkerLimitedHandler(t *testing.T) {
+	ctx := context.Background()
+	queue, server := newTestRedisQueue(t)
+
+	release := make(chan struct{})
+	var running atomic.Int32
+	handler := NewHandler(
+		"limited",
+		func(ctx context.Context, payload testPayload) error {
+			running.Add(1)
+			<-release
+			return nil
+		},
+		WithConcurrency(1),
+		WithTimeout(time.Hour),
+	)
+	worker := NewWorker(config.GetLogger(), config.Env{WorkerConcurrency: 5}, queue, []Handler{handler})
+	assert.Equal(t, constants.JobMaxTimeout, worker.handlers["limited"].timeout, "timeout is shorter than the lease")
+
+	client := NewClient(queue)
+	for i := 0; i < 3; i++ {
+		_, _ = client.Enqueue(ctx, "limited", testPayload{})
+	}
+
+	worker.Start()
+	assert.Eventually(t, func() bool { return running.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	processing, _ := server.ZMembers(redisJobProcessing)
+	assert.Len(t, processing, 1, "jobs waiting for the handler are not claimed")
+
+	close(release)
+	assert.Eventually(t, func() bool { return running.Load() == 3 }, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, worker.Stop(ctx))
+}
+
+func TestWorker(t *testing.T) {
+	ctx := context.Background()
+	queue, server := newTestRedisQueue(t)
+
+	var succeeded, failed atomic.Int32
+	handler := NewHandler(
+		"test",
+		func(ctx context.Context, payload testPayload) error {
+			if payload.Fail {
+				failed.Add(1)
+				return errors.New("boom")
+			}
+			succeeded.Add(1)
+			return nil
+		},
+	)
+	permanent := NewHandler(
+		"permanent",
+		func(ctx context.Context, payload testPayload) error {
+			return Permanent(errors.New("invalid"))
+		},
+	)
+	worker := NewWorker(config.GetLogger(), config.Env{WorkerConcurrency: 2}, queue, []Handler{handler, permanent})
+
+	client := NewClient(queue)
+	for i := 0; i < 3; i++ {
+		_, _ = client.Enqueue(ctx, "test", testPayload{})
+	}
+	_, _ = client.Enqueue(ctx, "test", testPayload{Fail: true}, MaxAttempts(2))
+	_, _ = client.Enqueue(ctx, "permanent", testPayload{})
+
+	worker.Start()
+	assert.Eventually(t, func() bool { return succeeded.Load() == 3 && failed.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(
+		t, func() bool {
+			dead, _ := server.ZMembers(redisJobDead)
+			return len(dead) == 1
+		}, 5*time.Second, 10*time.Millisecond, "permanent failure is buried right away",
+	)
+	assert.NoError(t, worker.Stop(ctx))
+
+	scheduled, _ := server.ZMembers(redisJobScheduled)
+	assert.Len(t, scheduled, 1, "failed job is retried with backoff")
+	processing, _ := server.ZMembers(redisJobProcessing)
+	assert.Empty(t, processing)
+}
+
+func TestNewHandlerInvalidPayload(t *testing.T) {
+	handler := NewHandler(constants.JobName("test"), func(context.Context, testPayload) error { return nil })
+	err := handler.Handle(context.Background(), Job{Payload: []byte(`[]`)})
+	assert.ErrorIs(t, err, ErrPermanent)
+}
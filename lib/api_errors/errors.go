@@ -17,6 +17,18 @@ const (
 	InternalError   = HttpErrorType(http.StatusInternalServerError)
 	Unavailable     = HttpErrorType(http.StatusServiceUnavailable)
 	TooManyRequests = HttpErrorType(http.StatusTooManyRequests)
+
+	PreconditionFailed   = HttpErrorType(http.StatusPreconditionFailed)
+	PreconditionRequired = HttpErrorType(http.StatusPreconditionRequired)
+
+	PayloadTooLarge      = HttpErrorType(http.StatusRequestEntityTooLarge)
+	UnsupportedMediaType = HttpErrorType(http.StatusUnsupportedMediaType)
+	UnprocessableEntity  = HttpErrorType(http.StatusUnprocessableEntity)
+	MisdirectedRequest   = HttpErrorType(http.StatusMisdirectedRequest)
+
+	PaymentRequired = HttpErrorType(http.StatusPaymentRequired)
+	BadGateway      = HttpErrorType(http.StatusBadGateway)
+	GatewayTimeout  = HttpErrorType(http.StatusGatewayTimeout)
 )
 
 // ToInt converts HttpErrorType to int
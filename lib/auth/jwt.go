@@ -12,6 +12,7 @@ import (
 
 type JWTClaims struct {
 	jwt.RegisteredClaims
+	Role constants.Role `json:"role,omitempty"`
 	// ...other claims
 }
 
@@ -30,6 +31,14 @@ func NewJWTAuthService(
 	}
 }
 
+// RoleOf role of the user signing in, the ADMIN_EMAIL user is the admin
+func (m JWTAuthService) RoleOf(email string) constants.Role {
+	if m.env.AdminEmail != "" && strings.EqualFold(email, m.env.AdminEmail) {
+		return constants.Roles.Admin
+	}
+	return constants.Roles.User
+}
+
 func (m JWTAuthService) GetTokenFromHeader(header string) (string, *api_errors.ErrorResponse) {
 	if header == "" {
 		err := api_errors.ErrorResponse{
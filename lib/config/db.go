@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	goMySql "github.com/go-sql-driver/mysql"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
 // Database modal
@@ -29,32 +32,11 @@ func NewDatabase(logger Logger, dsnConfig DSNConfig) *Database {
 		dbType: dsnConfig.DBType,
 	}
 
-	var dialector *gorm.Dialector
+	var dialector gorm.Dialector
+	database.dsn, dialector = newDialector(dsnConfig)
 
-	switch database.dbType {
-	case DBTypeSql:
-		mysqlDSNConfig := goMySql.Config{
-			User:                 dsnConfig.UserName,
-			Passwd:               dsnConfig.Password,
-			Net:                  dsnConfig.Network,
-			Addr:                 dsnConfig.Address,
-			DBName:               dsnConfig.DBName,
-			ParseTime:            dsnConfig.ParseTime,
-			Loc:                  dsnConfig.TimeLocation,
-			AllowNativePasswords: true,
-			CheckConnLiveness:    true,
-		}
-		_dialector := mysql.New(
-			mysql.Config{
-				DSN: mysqlDSNConfig.FormatDSN(),
-			},
-		)
-		dialector = &_dialector
-		database.dsn = mysqlDSNConfig.FormatDSN()
-		break
-	}
-
-	if dialector == nil || database.dsn == "" || dsnConfig.Address == "" {
+	// sqlite database is a local file, it has no address
+	if dialector == nil || database.dsn == "" || (dsnConfig.Address == "" && database.dbType != DBTypeSqlite) {
 		err := errors.New("database not configured --- Using Mock Database")
 		logger.Error(err)
 
@@ -63,7 +45,12 @@ func NewDatabase(logger Logger, dsnConfig DSNConfig) *Database {
 		return &database
 	}
 
-	db, err := gorm.Open(*dialector, &gorm.Config{Logger: logger.GetGormLogger()})
+	if database.dbType == DBTypePostgres {
+		// postgres can't connect to a database that doesn't exist yet, it is created from the maintenance database
+		createPostgresDatabase(logger, dsnConfig)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.GetGormLogger()})
 	if err != nil {
 		_err := errors.New(
 			fmt.Sprintf(
@@ -76,25 +63,153 @@ func NewDatabase(logger Logger, dsnConfig DSNConfig) *Database {
 	}
 	database.DB = db
 
-	logger.Info("creating database if it doesn't exist")
-	if err = db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", dsnConfig.DBName)).Error; err != nil {
-		logger.Error("Couldn't create database")
-		database.ConnectionError = &err
-		return &database
+	if database.dbType == DBTypeSql {
+		logger.Info("creating database if it doesn't exist")
+		if err = db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", dsnConfig.DBName)).Error; err != nil {
+			logger.Error("Couldn't create database")
+			database.ConnectionError = &err
+			return &database
+		}
+
+		logger.Info("using given database")
+		if err = db.Exec(fmt.Sprintf("USE %s", dsnConfig.DBName)).Error; err != nil {
+			logger.Error("Cannot use the given database")
+			database.ConnectionError = &err
+			return &database
+		}
 	}
 
-	logger.Info("using given database")
-	if err = db.Exec(fmt.Sprintf("USE %s", dsnConfig.DBName)).Error; err != nil {
-		logger.Error("Cannot use the given database")
+	if err = configureConnections(db, dsnConfig); err != nil {
+		logger.Error("Couldn't connect to the read replicas: ", err.Error())
 		database.ConnectionError = &err
 		return &database
 	}
 
-	logger.Infof("Database connection established : %s", db.Migrator().CurrentDatabase())
+	logger.Infof(
+		"Database connection established : %s, read replicas: %d",
+		db.Migrator().CurrentDatabase(), len(dsnConfig.Replicas),
+	)
 
 	return &database
 }
 
+// newDialector dsn and gorm dialector of the database type, nil when the type is unknown
+func newDialector(dsnConfig DSNConfig) (dsn string, dialector gorm.Dialector) {
+	switch dsnConfig.DBType {
+	case DBTypeSql:
+		dsn = dsnConfig.MySQLDSN()
+		return dsn, mysql.New(
+			mysql.Config{
+				DSN: dsn,
+			},
+		)
+	case DBTypePostgres:
+		dsn = dsnConfig.PostgresDSN()
+		return dsn, postgres.New(
+			postgres.Config{
+				DSN: dsn,
+			},
+		)
+	case DBTypeSqlite:
+		if dsnConfig.DBName == "" {
+			return "", nil
+		}
+		dsn = dsnConfig.SqliteDSN()
+		return dsn, sqlite.Open(dsn)
+	}
+	return "", nil
+}
+
+// configureConnections sets up the pools and routes the reads to the replicas,
+// writes, raw statements other than SELECT and transactions stay on the primary
+func configureConnections(db *gorm.DB, dsnConfig DSNConfig) error {
+	if len(dsnConfig.Replicas) == 0 {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		dsnConfig.Pool.apply(sqlDB)
+		return nil
+	}
+
+	replicas := make([]gorm.Dialector, 0, len(dsnConfig.Replicas))
+	for _, replica := range dsnConfig.Replicas {
+		_, dialector := newDialector(replica)
+		replicas = append(replicas, dialector)
+	}
+	resolver := dbresolver.Register(
+		dbresolver.Config{
+			Replicas: replicas,
+			Policy:   dbresolver.RandomPolicy{},
+		},
+	)
+	// applied to the primary and every replica once the resolver is initialized
+	_ = resolver.Call(
+		func(connPool gorm.ConnPool) error {
+			if sqlDB, ok := connPool.(*sql.DB); ok {
+				dsnConfig.Pool.apply(sqlDB)
+			}
+			return nil
+		},
+	)
+	return db.Use(resolver)
+}
+
+func (p PoolConfig) apply(sqlDB *sql.DB) {
+	if p.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+// createPostgresDatabase creates the database when the user is allowed to, connecting fails afterward otherwise
+func createPostgresDatabase(logger Logger, dsnConfig DSNConfig) {
+	maintenance := dsnConfig
+	maintenance.DBName = "postgres"
+
+	db, err := gorm.Open(
+		postgres.Open(maintenance.PostgresDSN()),
+		&gorm.Config{Logger: logger.GetGormLogger()},
+	)
+	if err != nil {
+		logger.Error("Couldn't connect to the postgres database to create the database: ", err.Error())
+		return
+	}
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}()
+
+	var exists bool
+	if err = db.Raw("SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = ?)", dsnConfig.DBName).
+		Scan(&exists).Error; err != nil {
+		logger.Error("Couldn't check the database: ", err.Error())
+		return
+	}
+	if exists {
+		return
+	}
+
+	logger.Info("creating database as it doesn't exist")
+	if err = db.Exec(fmt.Sprintf("CREATE DATABASE %s", db.Statement.Quote(dsnConfig.DBName))).Error; err != nil {
+		logger.Error("Couldn't create database: ", err.Error())
+	}
+}
+
+// Primary forces the primary for reads that must see the latest writes, e.g. read-modify-write outside transactions
+func (d Database) Primary() *gorm.DB {
+	return d.DB.Clauses(dbresolver.Write)
+}
+
 func (d Database) DSN() string {
 	return d.dsn
 }
@@ -103,6 +218,14 @@ func (d Database) Type() string {
 	return d.dbType.ToString()
 }
 
+// MigrationURL database url of golang-migrate, postgres goes through its pgx v5 driver
+func (d Database) MigrationURL() string {
+	if d.dbType == DBTypePostgres {
+		return "pgx5" + strings.TrimPrefix(d.dsn, "postgres")
+	}
+	return fmt.Sprintf("%v://%v", d.Type(), d.dsn)
+}
+
 // MockDatabase modal
 type MockDatabase struct {
 	*Database
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// TestDatabaseReplicas reads outside transactions go to the replica, writes and transactions stay on the primary
+func TestDatabaseReplicas(t *testing.T) {
+	dir := t.TempDir()
+	env := Env{
+		TimeZone:       "UTC",
+		DBType:         DBTypeSqlite.ToString(),
+		DBName:         filepath.Join(dir, "primary.db"),
+		DBReadHosts:    filepath.Join(dir, "replica.db"),
+		DBMaxOpenConns: 4,
+	}
+	dsnConfig := NewDSNConfig(env)
+	assert.Len(t, dsnConfig.Replicas, 1)
+
+	// the replica isn't replicated in the test, it is told apart by its own row
+	replica, err := gorm.Open(sqlite.Open(dsnConfig.Replicas[0].SqliteDSN()), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, replica.Exec("CREATE TABLE markers (name TEXT)").Error)
+	assert.NoError(t, replica.Exec("INSERT INTO markers VALUES ('replica')").Error)
+
+	db := NewDatabase(GetLogger(), dsnConfig)
+	assert.Nil(t, db.ConnectionError)
+	assert.NoError(t, db.Exec("CREATE TABLE markers (name TEXT)").Error)
+	assert.NoError(t, db.Exec("INSERT INTO markers VALUES ('primary')").Error, "writes go to the primary")
+
+	marker := func(tx *gorm.DB) (name string) {
+		assert.NoError(t, tx.Table("markers").Select("name").Scan(&name).Error)
+		return name
+	}
+	assert.Equal(t, "replica", marker(db.DB))
+	assert.Equal(t, "primary", marker(db.Primary()))
+
+	tx := db.Primary().Begin()
+	assert.Equal(t, "primary", marker(tx))
+	assert.NoError(t, tx.Rollback().Error)
+
+	sqlDB, err := db.DB.DB()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, sqlDB.Stats().MaxOpenConnections)
+}
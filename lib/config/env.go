@@ -2,6 +2,8 @@ package config
 
 import (
 	"log"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/spf13/viper"
@@ -22,19 +24,56 @@ type Env struct {
 	DBHost     string `mapstructure:"DB_HOST"`
 	DBPort     string `mapstructure:"DB_PORT"`
 	DBName     string `mapstructure:"DB_NAME"`
+	DBSSLMode  string `mapstructure:"DB_SSL_MODE"`
+
+	DBReadHosts       string        `mapstructure:"DB_READ_HOSTS"`
+	DBMaxOpenConns    int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns    int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetime time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	DBConnMaxIdleTime time.Duration `mapstructure:"DB_CONN_MAX_IDLE_TIME"`
+
+	MigrateOnStart       bool          `mapstructure:"MIGRATE_ON_START"`
+	MigrationLockTimeout time.Duration `mapstructure:"MIGRATION_LOCK_TIMEOUT"`
 
 	SentryDSN string `mapstructure:"SENTRY_DSN"`
 
-	StorageBucketName string `mapstructure:"STORAGE_BUCKET_NAME"`
+	StorageBucketName string        `mapstructure:"STORAGE_BUCKET_NAME"`
+	SignedURLExpiry   time.Duration `mapstructure:"SIGNED_URL_EXPIRY"`
+	ImageProxySecret  string        `mapstructure:"IMAGE_PROXY_SECRET"`
+
+	ClamAVAddress string        `mapstructure:"CLAMAV_ADDRESS"`
+	ClamAVTimeout time.Duration `mapstructure:"CLAMAV_TIMEOUT"`
+
+	UploadTmpDir     string        `mapstructure:"UPLOAD_TMP_DIR"`
+	UploadSessionTTL time.Duration `mapstructure:"UPLOAD_SESSION_TTL"`
 
 	AdminEmail string `mapstructure:"ADMIN_EMAIL"`
 	AdminPass  string `mapstructure:"ADMIN_PASS"`
 	AdminName  string `mapstructure:"ADMIN_NAME"`
 
+	MailDriver       string `mapstructure:"MAIL_DRIVER"`
 	MailClientID     string `mapstructure:"MAIL_CLIENT_ID"`
 	MailClientSecret string `mapstructure:"MAIL_CLIENT_SECRET"`
 	MailAccesstoken  string `mapstructure:"MAIL_ACCESS_TOKEN"`
 	MailRefreshToken string `mapstructure:"MAIL_REFRESH_TOKEN"`
+	MailFromName     string `mapstructure:"MAIL_FROM_NAME"`
+	MailFromAddress  string `mapstructure:"MAIL_FROM_ADDRESS"`
+
+	MailSMTPHost       string        `mapstructure:"MAIL_SMTP_HOST"`
+	MailSMTPPort       int           `mapstructure:"MAIL_SMTP_PORT"`
+	MailSMTPUsername   string        `mapstructure:"MAIL_SMTP_USERNAME"`
+	MailSMTPPassword   string        `mapstructure:"MAIL_SMTP_PASSWORD"`
+	MailSMTPEncryption string        `mapstructure:"MAIL_SMTP_ENCRYPTION"`
+	MailAPIURL         string        `mapstructure:"MAIL_API_URL"`
+	MailAPIKey         string        `mapstructure:"MAIL_API_KEY"`
+	MailCaptureDir     string        `mapstructure:"MAIL_CAPTURE_DIR"`
+	MailTimeout        time.Duration `mapstructure:"MAIL_TIMEOUT"`
+
+	QueueDriver       string `mapstructure:"QUEUE_DRIVER"`
+	RedisAddr         string `mapstructure:"REDIS_ADDR"`
+	RedisPassword     string `mapstructure:"REDIS_PASSWORD"`
+	RedisDB           int    `mapstructure:"REDIS_DB"`
+	WorkerConcurrency int    `mapstructure:"WORKER_CONCURRENCY"`
 
 	AwsS3Region  string `mapstructure:"AWS_S3_REGION"`
 	AwsS3Bucket  string `mapstructure:"AWS_S3_BUCKET"`
@@ -50,6 +89,8 @@ type Env struct {
 	JwtAccessTokenExpiresAt  int    `mapstructure:"JWT_ACCESS_TOKEN_EXPIRES_AT"`
 	JwtRefreshTokenExpiresAt int    `mapstructure:"JWT_REFRESH_TOKEN_EXPIRES_AT"`
 
+	RequestTimeout time.Duration `mapstructure:"REQUEST_TIMEOUT"`
+
 	RateLimitPeriod   time.Duration `mapstructure:"RATE_LIMIT_PERIOD"`
 	RateLimitRequests int64         `mapstructure:"RATE_LIMIT_REQUESTS"`
 
@@ -92,5 +133,23 @@ func NewEnv(envPath EnvPath) Env {
 		env.TimeZone = "UTC"
 	}
 
+	// development and production migrate on startup unless it is set
+	if !viper.IsSet("MIGRATE_ON_START") {
+		env.MigrateOnStart = env.Environment == "development" || env.Environment == "production"
+	}
+
+	if env.UploadTmpDir == "" {
+		env.UploadTmpDir = filepath.Join(os.TempDir(), "uploads")
+	}
+
+	if env.UploadSessionTTL == 0 {
+		env.UploadSessionTTL = 24 * time.Hour
+	}
+
+	// zero disables the default timeout, so only a missing value falls back
+	if !viper.IsSet("REQUEST_TIMEOUT") {
+		env.RequestTimeout = 30 * time.Second
+	}
+
 	return env
 }
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// lockPollInterval postgres has no waiting try-lock, it is retried until the timeout
+const lockPollInterval = 500 * time.Millisecond
+
+// localLocks sqlite has no named locks, the database file belongs to a single instance
+var localLocks sync.Map
+
+// AdvisoryLock acquires the named session lock, waiting up to timeout (0 doesn't wait).
+// ok is false when another session held it for the whole timeout. The lock belongs to the session
+// so it keeps its own connection until released; mysql limits the names to 64 characters
+func (d Database) AdvisoryLock(ctx context.Context, name string, timeout time.Duration) (
+	release func(),
+	ok bool,
+	err error,
+) {
+	if d.DB.Dialector.Name() == DBTypeSqlite.ToString() {
+		return localLock(ctx, name, timeout)
+	}
+
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	unlock := "DO RELEASE_LOCK(?)"
+	if d.DB.Dialector.Name() == DBTypePostgres.ToString() {
+		unlock = "SELECT pg_advisory_unlock(hashtext($1))"
+		ok, err = postgresLock(ctx, conn, name, timeout)
+	} else {
+		ok, err = mysqlLock(ctx, conn, name, timeout)
+	}
+	if err != nil || !ok {
+		_ = conn.Close()
+		if err != nil {
+			err = fmt.Errorf("lock %s: %w", name, err)
+		}
+		return nil, false, err
+	}
+
+	return func() {
+		// closing the connection would release it as well, it is returned to the pool instead
+		_, _ = conn.ExecContext(context.Background(), unlock, name)
+		_ = conn.Close()
+	}, true, nil
+}
+
+func mysqlLock(ctx context.Context, conn *sql.Conn, name string, timeout time.Duration) (bool, error) {
+	var acquired sql.NullInt64
+	seconds := int(math.Ceil(timeout.Seconds()))
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, seconds).Scan(&acquired); err != nil {
+		return false, err
+	}
+	return acquired.Int64 == 1, nil
+}
+
+func postgresLock(ctx context.Context, conn *sql.Conn, name string, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		var acquired bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).
+			Scan(&acquired); err != nil {
+			return false, err
+		}
+		if acquired || !time.Now().Before(deadline) {
+			return acquired, nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func localLock(ctx context.Context, name string, timeout time.Duration) (release func(), ok bool, err error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, held := localLocks.LoadOrStore(name, struct{}{}); !held {
+			return func() { localLocks.Delete(name) }, true, nil
+		}
+		if !time.Now().Before(deadline) {
+			return nil, false, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
@@ -1,64 +1,266 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"regexp"
+	"io/fs"
+	"time"
+
+	"boilerplate-api/database/migration"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/mysql"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+var (
+	// ErrMigrationsUnavailable migrator isn't created when the database is not connected
+	ErrMigrationsUnavailable = errors.New("migrations are unavailable, database is not connected")
+	// ErrMigrationsDirty previous migration failed halfway
+	ErrMigrationsDirty = errors.New("database is dirty, fix the schema and run `cli migrate force <version>`")
+	// ErrMigrationLocked another instance held the lock longer than MIGRATION_LOCK_TIMEOUT
+	ErrMigrationLocked = errors.New("migration lock is held by another instance")
 )
 
 // Migrations Migration Struct
 type Migrations struct {
-	logger   Logger
-	migrator *migrate.Migrate
+	logger      Logger
+	migrator    *migrate.Migrate
+	fsys        fs.FS
+	db          *Database
+	lockName    string
+	lockTimeout time.Duration
+}
+
+// MigrationStatus applied version and the migrations not applied yet
+type MigrationStatus struct {
+	// Version 0 when no migration is applied
+	Version uint
+	// Dirty previous migration failed halfway, fix the schema and force the version
+	Dirty   bool
+	Pending []uint
 }
 
-// NewMigrations return new Migrations struct
+// NewMigrations return new Migrations struct of the embedded migrations
 func NewMigrations(
 	logger Logger,
-	envPath EnvPath,
+	env Env,
 	db *Database,
-) *Migrations {
-	path := getMigrationFolder(envPath.ToString())
-	path = fmt.Sprintf("file://%s/", path)
-
+) (*Migrations, error) {
+	// migrations of each dialect are kept in the folder named after it
+	fsys, err := fs.Sub(migration.FS, db.Type())
+	if err != nil {
+		return nil, fmt.Errorf("migration source: %w", err)
+	}
+	migrations := &Migrations{
+		logger:      logger,
+		fsys:        fsys,
+		db:          db,
+		lockName:    lockName(env.DBName + ":migrate"),
+		lockTimeout: env.MigrationLockTimeout,
+	}
 	if db.ConnectionError != nil {
 		logger.Info("!!! Skipping Migrations !!!")
-		return &Migrations{}
+		return migrations, nil
 	}
 
-	migrator, err := migrate.New(path, fmt.Sprintf("%v://%v", db.Type(), db.DSN()))
+	sourceDriver, err := iofs.New(migrations.fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migration source: %w", err)
+	}
+	migrator, err := newMigrator(sourceDriver, db)
 	if err != nil {
-		logger.Panic("Error in migration: ", err)
+		return nil, fmt.Errorf("migration database: %w", err)
 	}
+	migrations.migrator = migrator
+	return migrations, nil
+}
 
-	return &Migrations{
-		logger:   logger,
-		migrator: migrator,
+// newMigrator sqlite migrations run on the connection of gorm, the others open their own
+func newMigrator(sourceDriver source.Driver, db *Database) (*migrate.Migrate, error) {
+	if db.dbType != DBTypeSqlite {
+		return migrate.NewWithSourceInstance("iofs", sourceDriver, db.MigrationURL())
+	}
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		return nil, err
+	}
+	driver, err := newSqliteMigrationDriver(sqlDB)
+	if err != nil {
+		return nil, err
 	}
+	return migrate.NewWithInstance("iofs", sourceDriver, DBTypeSqlite.ToString(), driver)
 }
 
-// MigrateUp migrates all table
-func (m Migrations) MigrateUp() {
+// MigrateUp applies the pending migrations on startup, fails when the database is dirty
+func (m Migrations) MigrateUp(ctx context.Context) error {
 	m.logger.Info("--- Running Migration Up ---")
-	err := m.migrator.Up()
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		m.logger.Info("Error in migration steps: ", err.Error())
+	return m.run(
+		ctx, func(migrator *migrate.Migrate) error {
+			if err := m.CheckClean(); err != nil {
+				return err
+			}
+			return migrator.Up()
+		},
+	)
+}
+
+// Up applies all the pending migrations
+func (m Migrations) Up(ctx context.Context) error {
+	return m.run(
+		ctx, func(migrator *migrate.Migrate) error {
+			return migrator.Up()
+		},
+	)
+}
+
+// Down rolls back all the applied migrations
+func (m Migrations) Down(ctx context.Context) error {
+	return m.run(
+		ctx, func(migrator *migrate.Migrate) error {
+			return migrator.Down()
+		},
+	)
+}
+
+// Steps applies n migrations when n is positive, rolls back -n of them when negative
+func (m Migrations) Steps(ctx context.Context, n int) error {
+	return m.run(
+		ctx, func(migrator *migrate.Migrate) error {
+			return migrator.Steps(n)
+		},
+	)
+}
+
+// Goto applies or rolls back the migrations until the version
+func (m Migrations) Goto(ctx context.Context, version uint) error {
+	return m.run(
+		ctx, func(migrator *migrate.Migrate) error {
+			return migrator.Migrate(version)
+		},
+	)
+}
+
+// Force sets the version without running migrations and clears the dirty flag,
+// -1 means no migration is applied
+func (m Migrations) Force(version int) error {
+	if m.migrator == nil {
+		return ErrMigrationsUnavailable
+	}
+	return m.migrator.Force(version)
+}
+
+// Version applied version, 0 when no migration is applied
+func (m Migrations) Version() (version uint, dirty bool, err error) {
+	if m.migrator == nil {
+		return 0, false, ErrMigrationsUnavailable
+	}
+	version, dirty, err = m.migrator.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
 	}
+	return version, dirty, err
 }
 
-/*
-getMigrationFolder path from env path.
+// CheckCleanLocked CheckClean holding the migration lock. Migrate sets the dirty flag while a migration runs,
+// the instances starting next to the migrating one wait for it instead of seeing the flag
+func (m Migrations) CheckCleanLocked(ctx context.Context) error {
+	return m.locked(ctx, m.CheckClean)
+}
+
+// CheckClean fails with ErrMigrationsDirty when the previous migration failed halfway
+func (m Migrations) CheckClean() error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("%w: version %d", ErrMigrationsDirty, version)
+	}
+	return nil
+}
+
+// Status applied version and pending migrations
+func (m Migrations) Status() (MigrationStatus, error) {
+	status := MigrationStatus{}
+	version, dirty, err := m.Version()
+	if err != nil {
+		return status, err
+	}
+	status.Version, status.Dirty = version, dirty
+
+	sourceDriver, err := iofs.New(m.fsys, ".")
+	if err != nil {
+		return status, err
+	}
+	defer func() {
+		_ = sourceDriver.Close()
+	}()
+
+	next, err := sourceDriver.First()
+	for err == nil {
+		if next > status.Version {
+			status.Pending = append(status.Pending, next)
+		}
+		next, err = sourceDriver.Next(next)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return status, err
+	}
+	return status, nil
+}
+
+// run runs the migration holding the lock, no change isn't an error
+func (m Migrations) run(ctx context.Context, migration func(migrator *migrate.Migrate) error) error {
+	return m.locked(
+		ctx, func() error {
+			if err := migration(m.migrator); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+				return err
+			}
+			return nil
+		},
+	)
+}
 
-e.g:
+// locked runs fn holding the migration lock
+func (m Migrations) locked(ctx context.Context, fn func() error) error {
+	if m.migrator == nil {
+		return ErrMigrationsUnavailable
+	}
+	release, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn()
+}
 
-	../../<.test.env/.env> => ../../migration
-	<.test.env/.env> => migration
-*/
-func getMigrationFolder(envPath string) string {
-	m1 := regexp.MustCompile(`(\.(\w+))+`)
-	return m1.ReplaceAllString(envPath, "database/migration")
+// lock waits up to MIGRATION_LOCK_TIMEOUT for the other instances migrating the same database,
+// they find nothing to apply afterward. The lock of the migrate driver gives up after 10 seconds,
+// disabled when the timeout is 0
+func (m Migrations) lock(ctx context.Context) (release func(), err error) {
+	if m.lockTimeout <= 0 {
+		return func() {}, nil
+	}
+
+	m.logger.Info("Waiting for the migration lock...")
+	release, acquired, err := m.db.AdvisoryLock(ctx, m.lockName, m.lockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrMigrationLocked
+	}
+	return release, nil
+}
+
+// lockName mysql limits the lock names to 64 characters
+func lockName(name string) string {
+	if len(name) > 64 {
+		return name[len(name)-64:]
+	}
+	return name
 }
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMigrationsCheckCleanLocked the dirty flag of a running migration is checked once it is done
+func TestMigrationsCheckCleanLocked(t *testing.T) {
+	ctx := context.Background()
+	env := Env{
+		TimeZone:             "UTC",
+		DBType:               DBTypeSqlite.ToString(),
+		DBName:               filepath.Join(t.TempDir(), "test.db"),
+		MigrationLockTimeout: 5 * time.Second,
+	}
+	db := NewDatabase(GetLogger(), NewDSNConfig(env))
+	assert.Nil(t, db.ConnectionError)
+	migrations, err := NewMigrations(GetLogger(), env, db)
+	assert.NoError(t, err)
+	assert.NoError(t, migrations.Up(ctx))
+
+	// another instance is migrating, migrate flags the database dirty until it is done
+	release, ok, err := db.AdvisoryLock(ctx, migrations.lockName, 0)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, db.Exec("UPDATE schema_migrations SET dirty = ?", true).Error)
+	assert.ErrorIs(t, migrations.CheckClean(), ErrMigrationsDirty)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, db.Exec("UPDATE schema_migrations SET dirty = ?", false).Error)
+		release()
+	}()
+	assert.NoError(t, migrations.CheckCleanLocked(ctx))
+	assert.NoError(t, migrations.MigrateUp(ctx))
+
+	assert.NoError(t, db.Exec("UPDATE schema_migrations SET dirty = ?", true).Error)
+	assert.ErrorIs(t, migrations.MigrateUp(ctx), ErrMigrationsDirty, "a failed migration is still reported")
+}
@@ -16,6 +16,7 @@ var ENVModule = fx.Options(
 // BaseModule base config
 var BaseModule = fx.Options(
 	fx.Provide(NewDatabase),
+	fx.Provide(NewUnitOfWork),
 	fx.Provide(NewMigrations),
 	fx.Provide(GetLogger),
 )
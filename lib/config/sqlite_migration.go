@@ -0,0 +1,153 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"sync/atomic"
+
+	"github.com/golang-migrate/migrate/v4/database"
+)
+
+// sqliteMigrationDriver golang-migrate driver over the connection of gorm. The sqlite driver of migrate
+// imports modernc.org/sqlite, it registers the same driver name as github.com/glebarez/go-sqlite
+type sqliteMigrationDriver struct {
+	db     *sql.DB
+	locked atomic.Bool
+}
+
+func newSqliteMigrationDriver(db *sql.DB) (*sqliteMigrationDriver, error) {
+	driver := &sqliteMigrationDriver{db: db}
+	if err := driver.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+	return driver, nil
+}
+
+func (d *sqliteMigrationDriver) ensureVersionTable() error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (version uint64, dirty bool);
+CREATE UNIQUE INDEX IF NOT EXISTS version_unique ON schema_migrations (version);`
+	if _, err := d.db.Exec(query); err != nil {
+		return &database.Error{OrigErr: err, Query: []byte(query)}
+	}
+	return nil
+}
+
+// Open migrations are created with the instance of the database
+func (d *sqliteMigrationDriver) Open(string) (database.Driver, error) {
+	return nil, errors.New("sqlite migrations are created from the database connection")
+}
+
+// Close the connection belongs to gorm
+func (d *sqliteMigrationDriver) Close() error {
+	return nil
+}
+
+// Lock sqlite databases are local to the instance, the lock only guards the process
+func (d *sqliteMigrationDriver) Lock() error {
+	if !d.locked.CompareAndSwap(false, true) {
+		return database.ErrLocked
+	}
+	return nil
+}
+
+func (d *sqliteMigrationDriver) Unlock() error {
+	if !d.locked.CompareAndSwap(true, false) {
+		return database.ErrNotLocked
+	}
+	return nil
+}
+
+// Run executes the statements of the migration in a transaction
+func (d *sqliteMigrationDriver) Run(migration io.Reader) error {
+	query, err := io.ReadAll(migration)
+	if err != nil {
+		return err
+	}
+	tx, err := d.db.Begin()
+	if err != nil {
+		return &database.Error{OrigErr: err, Err: "transaction start failed"}
+	}
+	if _, err := tx.Exec(string(query)); err != nil {
+		_ = tx.Rollback()
+		return &database.Error{OrigErr: err, Err: "migration failed", Query: query}
+	}
+	if err := tx.Commit(); err != nil {
+		return &database.Error{OrigErr: err, Err: "transaction commit failed"}
+	}
+	return nil
+}
+
+func (d *sqliteMigrationDriver) SetVersion(version int, dirty bool) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return &database.Error{OrigErr: err, Err: "transaction start failed"}
+	}
+	if _, err := tx.Exec("DELETE FROM schema_migrations"); err != nil {
+		_ = tx.Rollback()
+		return &database.Error{OrigErr: err, Err: "clearing version failed"}
+	}
+	// dirty nil version is kept as well, a failed down migration of the first version is noticed
+	if version >= 0 || (version == database.NilVersion && dirty) {
+		if _, err := tx.Exec("INSERT INTO schema_migrations (version, dirty) VALUES (?, ?)", version, dirty); err != nil {
+			_ = tx.Rollback()
+			return &database.Error{OrigErr: err, Err: "setting version failed"}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return &database.Error{OrigErr: err, Err: "transaction commit failed"}
+	}
+	return nil
+}
+
+func (d *sqliteMigrationDriver) Version() (version int, dirty bool, err error) {
+	err = d.db.QueryRow("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.NilVersion, false, nil
+	}
+	if err != nil {
+		return 0, false, &database.Error{OrigErr: err, Err: "reading version failed"}
+	}
+	return version, dirty, nil
+}
+
+// Drop drops every table and recreates the version table
+func (d *sqliteMigrationDriver) Drop() error {
+	rows, err := d.db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return err
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		tables = append(tables, table)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// the pragma is set on the connection, tables referencing each other are dropped in any order
+	conn, err := d.db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), "PRAGMA foreign_keys = ON")
+		_ = conn.Close()
+	}()
+	if _, err := conn.ExecContext(context.Background(), "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if _, err := conn.ExecContext(context.Background(), `DROP TABLE IF EXISTS "`+table+`"`); err != nil {
+			return err
+		}
+	}
+	return d.ensureVersionTable()
+}
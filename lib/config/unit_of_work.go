@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey context key of the transaction
+type txKey struct{}
+
+// WithTx context carrying the transaction, repositories given the context run their queries in it
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext transaction carried by the context
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// Conn handle of the context, the transaction it carries or the database otherwise.
+// Queries are bound to the context, they are canceled with it
+func (d Database) Conn(ctx context.Context) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return d.DB.WithContext(ctx)
+}
+
+// UnitOfWork runs functions in a transaction carried by their context
+type UnitOfWork struct {
+	db *Database
+}
+
+// NewUnitOfWork creates new unit of work
+func NewUnitOfWork(db *Database) UnitOfWork {
+	return UnitOfWork{db: db}
+}
+
+// Do runs fn in a transaction on the primary, committed when fn returns nil and rolled back when it fails or panics.
+// Inside another transaction it runs in a savepoint, only the changes of fn are rolled back when it fails
+func (u UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	db, ok := TxFromContext(ctx)
+	if !ok {
+		db = u.db.Primary()
+	}
+	return db.WithContext(ctx).Transaction(
+		func(tx *gorm.DB) error {
+			return fn(WithTx(ctx, tx))
+		},
+	)
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUnitOfWork nested units run in savepoints, a failing one rolls back only its own changes
+func TestUnitOfWork(t *testing.T) {
+	db := NewDatabase(
+		GetLogger(), NewDSNConfig(
+			Env{
+				TimeZone: "UTC",
+				DBType:   DBTypeSqlite.ToString(),
+				DBName:   filepath.Join(t.TempDir(), "test.db"),
+			},
+		),
+	)
+	assert.Nil(t, db.ConnectionError)
+	assert.NoError(t, db.Exec("CREATE TABLE markers (name TEXT)").Error)
+	unitOfWork := NewUnitOfWork(db)
+
+	insert := func(ctx context.Context, name string) error {
+		return db.Conn(ctx).Exec("INSERT INTO markers VALUES (?)", name).Error
+	}
+	names := func() (names []string) {
+		assert.NoError(t, db.Table("markers").Order("name").Pluck("name", &names).Error)
+		return names
+	}
+
+	failed := errors.New("failed")
+	err := unitOfWork.Do(
+		context.Background(), func(ctx context.Context) error {
+			_, ok := TxFromContext(ctx)
+			assert.True(t, ok, "the transaction is carried by the context")
+			if err := insert(ctx, "outer"); err != nil {
+				return err
+			}
+			err := unitOfWork.Do(
+				ctx, func(ctx context.Context) error {
+					if err := insert(ctx, "nested"); err != nil {
+						return err
+					}
+					return failed
+				},
+			)
+			assert.ErrorIs(t, err, failed)
+			return nil
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"outer"}, names(), "only the nested unit is rolled back")
+
+	err = unitOfWork.Do(
+		context.Background(), func(ctx context.Context) error {
+			if err := insert(ctx, "rolled back"); err != nil {
+				return err
+			}
+			return failed
+		},
+	)
+	assert.ErrorIs(t, err, failed)
+
+	assert.Panics(
+		t, func() {
+			_ = unitOfWork.Do(
+				context.Background(), func(ctx context.Context) error {
+					_ = insert(ctx, "panicked")
+					panic("boom")
+				},
+			)
+		},
+	)
+	assert.Equal(t, []string{"outer"}, names())
+}
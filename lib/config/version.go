@@ -0,0 +1,48 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict the row was updated since its version was read
+var ErrVersionConflict = errors.New("the record was modified by another request")
+
+// UpdateVersioned updates the row of model with the id when its version column still has the version, and
+// increments it. Versioned tables have an unsigned `version` column starting at 1; reads hand it out as the ETag
+// and updates take it back from If-Match, so concurrent edits don't overwrite each other.
+// Version 0 matches any version (If-Match: *).
+//
+// Returns ErrVersionConflict when the row has another version and gorm.ErrRecordNotFound when it doesn't exist
+func (d Database) UpdateVersioned(
+	ctx context.Context,
+	model any,
+	id any,
+	version uint32,
+	updates map[string]any,
+) error {
+	values := make(map[string]any, len(updates)+1)
+	maps.Copy(values, updates)
+	values["version"] = gorm.Expr("version + 1")
+
+	query := d.Conn(ctx).Model(model).Where("id = ?", id)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Updates(values)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+
+	var count int64
+	if err := d.Conn(ctx).Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ErrVersionConflict
+}
@@ -1,3 +1,39 @@
 package constants
 
 const STORAGE_URL string = "https://storage.googleapis.com/"
+
+// Storage storage backend of uploaded files
+type Storage string
+
+var Storages = struct {
+	GCS Storage
+	S3  Storage
+}{
+	GCS: "gcs",
+	S3:  "s3",
+}
+
+func (s Storage) ToString() string {
+	return string(s)
+}
+
+func (s Storage) IsValid() bool {
+	return s == Storages.GCS || s == Storages.S3
+}
+
+// UploadSessionStatus status of resumable upload session
+type UploadSessionStatus string
+
+var UploadSessionStatuses = struct {
+	Pending   UploadSessionStatus
+	Completed UploadSessionStatus
+	Aborted   UploadSessionStatus
+}{
+	Pending:   "pending",
+	Completed: "completed",
+	Aborted:   "aborted",
+}
+
+func (s UploadSessionStatus) ToString() string {
+	return string(s)
+}
@@ -1,8 +1,8 @@
 package constants
 
 const (
-	// DBTransaction is database transaction handle set at router context
-	DBTransaction = "db_trx"
+	// RequestContext context of the request before the timeout middleware set its deadline
+	RequestContext = "request_context"
 )
 
 const (
@@ -65,9 +65,11 @@ func (r Role) ToString() string {
 type Header string
 
 var Headers = struct {
-	Authorization Header
+	Authorization  Header
+	IdempotencyKey Header
 }{
-	Authorization: "Authorization",
+	Authorization:  "Authorization",
+	IdempotencyKey: "Idempotency-Key",
 }
 
 func (h Header) ToString() string {
@@ -0,0 +1,55 @@
+package constants
+
+import "time"
+
+// QueueDriver backend jobs are queued in, selected by QUEUE_DRIVER
+type QueueDriver string
+
+var QueueDrivers = struct {
+	MySQL QueueDriver
+	Redis QueueDriver
+}{
+	MySQL: "mysql",
+	Redis: "redis",
+}
+
+// JobStatus state of the job in the mysql queue
+type JobStatus string
+
+var JobStatuses = struct {
+	// Pending waits for run_at, also after a failed attempt
+	Pending JobStatus
+	// Running claimed by a worker, reclaimed when run_at passes
+	Running JobStatus
+	// Dead failed max attempts times
+	Dead JobStatus
+}{
+	Pending: "pending",
+	Running: "running",
+	Dead:    "dead",
+}
+
+// JobName name jobs are registered and queued with
+type JobName string
+
+var JobNames = struct {
+	SyncSubscription JobName
+	CreateThumbnail  JobName
+}{
+	SyncSubscription: "billing.sync_subscription",
+	CreateThumbnail:  "utility.create_thumbnail",
+}
+
+const (
+	JobPollInterval       = time.Second
+	JobMaxAttempts        = 5
+	JobDefaultTimeout     = 5 * time.Minute
+	JobDefaultConcurrency = 10
+	// JobLease time a claimed job is hidden from other workers, longer than any job timeout
+	JobLease = 15 * time.Minute
+	// JobMaxTimeout handler timeouts are capped to it, leaves time to complete or retry the job within the lease
+	JobMaxTimeout = JobLease - time.Minute
+	// JobBaseBackoff delay after the first failure, doubled on each attempt up to JobMaxBackoff
+	JobBaseBackoff = 10 * time.Second
+	JobMaxBackoff  = time.Hour
+)
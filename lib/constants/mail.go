@@ -0,0 +1,32 @@
+package constants
+
+// MailDriver backend emails are sent through, selected by MAIL_DRIVER
+type MailDriver string
+
+var MailDrivers = struct {
+	Gmail    MailDriver
+	SMTP     MailDriver
+	SendGrid MailDriver
+	Capture  MailDriver
+}{
+	Gmail:    "gmail",
+	SMTP:     "smtp",
+	SendGrid: "sendgrid",
+	Capture:  "capture",
+}
+
+// SMTPEncryption how the smtp connection is secured
+type SMTPEncryption string
+
+var SMTPEncryptions = struct {
+	// StartTLS upgrades plain connection, usually port 587
+	StartTLS SMTPEncryption
+	// TLS implicit tls, usually port 465
+	TLS SMTPEncryption
+	// None plain connection, only for local relays
+	None SMTPEncryption
+}{
+	StartTLS: "starttls",
+	TLS:      "tls",
+	None:     "none",
+}
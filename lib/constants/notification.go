@@ -0,0 +1,54 @@
+package constants
+
+// NotificationEvent event users are notified about, templates are stored per event
+type NotificationEvent string
+
+var NotificationEvents = struct {
+	PaymentFailed        NotificationEvent
+	SubscriptionCanceled NotificationEvent
+}{
+	PaymentFailed:        "payment_failed",
+	SubscriptionCanceled: "subscription_canceled",
+}
+
+// NotificationEventList events users can set preferences of
+var NotificationEventList = []NotificationEvent{
+	NotificationEvents.PaymentFailed,
+	NotificationEvents.SubscriptionCanceled,
+}
+
+// NotificationChannel how the notification is delivered
+type NotificationChannel string
+
+var NotificationChannels = struct {
+	Email NotificationChannel
+	SMS   NotificationChannel
+	Push  NotificationChannel
+}{
+	Email: "email",
+	SMS:   "sms",
+	Push:  "push",
+}
+
+// NotificationChannelList channels in the order notifications are sent
+var NotificationChannelList = []NotificationChannel{
+	NotificationChannels.Email,
+	NotificationChannels.SMS,
+	NotificationChannels.Push,
+}
+
+// DevicePlatform platform of the push notification device
+type DevicePlatform string
+
+var DevicePlatforms = struct {
+	Android DevicePlatform
+	IOS     DevicePlatform
+	Web     DevicePlatform
+}{
+	Android: "android",
+	IOS:     "ios",
+	Web:     "web",
+}
+
+// DefaultLocale locale of the templates used when the user's locale has none
+const DefaultLocale = "en"
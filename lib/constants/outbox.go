@@ -0,0 +1,41 @@
+package constants
+
+import "time"
+
+// OutboxStatus delivery state of the outbox message
+type OutboxStatus string
+
+var OutboxStatuses = struct {
+	// Pending waits for the dispatcher, also after a failed attempt
+	Pending OutboxStatus
+	// Processing claimed by a dispatcher, reclaimed when available_at passes
+	Processing OutboxStatus
+	Sent       OutboxStatus
+	// Dead failed max attempts times, only retried from the admin api
+	Dead OutboxStatus
+}{
+	Pending:    "pending",
+	Processing: "processing",
+	Sent:       "sent",
+	Dead:       "dead",
+}
+
+// OutboxTopic kind of the outbox message, each topic has one handler
+type OutboxTopic string
+
+var OutboxTopics = struct {
+	Notification OutboxTopic
+}{
+	Notification: "notification",
+}
+
+const (
+	OutboxPollInterval = 5 * time.Second
+	OutboxBatchSize    = 20
+	OutboxMaxAttempts  = 8
+	// OutboxLease time a claimed message is hidden from other dispatchers
+	OutboxLease = 5 * time.Minute
+	// OutboxBaseBackoff delay after the first failure, doubled on each attempt up to OutboxMaxBackoff
+	OutboxBaseBackoff = 30 * time.Second
+	OutboxMaxBackoff  = 6 * time.Hour
+)
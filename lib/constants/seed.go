@@ -0,0 +1,29 @@
+package constants
+
+// SeedName name seeds are registered, depended on and recorded in seed_runs with
+type SeedName string
+
+var SeedNames = struct {
+	Admin         SeedName
+	ProjectBudget SeedName
+	FakeUsers     SeedName
+}{
+	Admin:         "admin",
+	ProjectBudget: "project_budget",
+	FakeUsers:     "fake_users",
+}
+
+// Environment value of ENVIRONMENT
+type Environment string
+
+var Environments = struct {
+	Local       Environment
+	Development Environment
+	Production  Environment
+	Test        Environment
+}{
+	Local:       "local",
+	Development: "development",
+	Production:  "production",
+	Test:        "test",
+}
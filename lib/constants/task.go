@@ -0,0 +1,53 @@
+package constants
+
+import "time"
+
+// TaskName name scheduled tasks are registered, locked and recorded with
+type TaskName string
+
+var TaskNames = struct {
+	CleanupUploadSessions  TaskName
+	ReconcileSubscriptions TaskName
+	PurgeOutbox            TaskName
+	CheckProjectBudget     TaskName
+	PurgeStaleDevices      TaskName
+}{
+	CleanupUploadSessions:  "upload_sessions.cleanup",
+	ReconcileSubscriptions: "billing.reconcile_subscriptions",
+	PurgeOutbox:            "outbox.purge",
+	CheckProjectBudget:     "budget.check",
+	PurgeStaleDevices:      "notification.purge_stale_devices",
+}
+
+// TaskRunStatus state of the task run
+type TaskRunStatus string
+
+var TaskRunStatuses = struct {
+	Running   TaskRunStatus
+	Succeeded TaskRunStatus
+	Failed    TaskRunStatus
+}{
+	Running:   "running",
+	Succeeded: "succeeded",
+	Failed:    "failed",
+}
+
+// TaskTrigger what started the task run
+type TaskTrigger string
+
+var TaskTriggers = struct {
+	Schedule TaskTrigger
+	Manual   TaskTrigger
+}{
+	Schedule: "schedule",
+	Manual:   "manual",
+}
+
+const (
+	// TaskTimeout cancels context of the task run
+	TaskTimeout = time.Hour
+	// OutboxRetention sent outbox messages are purged after it
+	OutboxRetention = 7 * 24 * time.Hour
+	// DeviceTokenRetention push tokens not registered again for this long are stale, FCM expires them
+	DeviceTokenRetention = 60 * 24 * time.Hour
+)
@@ -0,0 +1,44 @@
+package constants
+
+import "time"
+
+const (
+	// MB one megabyte in bytes
+	MB int64 = 1 << 20
+
+	// ImageUploadMaxSize max size of the image upload
+	ImageUploadMaxSize = 10 * MB
+
+	// FileUploadMaxSize max size of the generic file upload
+	FileUploadMaxSize = 25 * MB
+)
+
+// ImageContentTypes image types accepted for upload
+var ImageContentTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+}
+
+// DocumentContentTypes non image types accepted for upload
+var DocumentContentTypes = []string{
+	"application/pdf",
+	"text/plain",
+	"text/csv",
+	"application/zip",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+const (
+	// ChunkedUploadMaxSize max size of the resumable upload
+	ChunkedUploadMaxSize = 5 * 1024 * MB
+
+	// ChunkedUploadChunkSize recommended chunk size sent to clients
+	ChunkedUploadChunkSize = 8 * MB
+
+	// UploadTimeout request timeout of the routes receiving or storing files, slow clients need longer than the default
+	UploadTimeout = 15 * time.Minute
+)
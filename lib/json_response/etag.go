@@ -0,0 +1,45 @@
+package json_response
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+)
+
+// Versioned data of a row with the version column
+type Versioned interface {
+	GetVersion() uint32
+}
+
+// VersionETag strong ETag of the row version, compared with If-Match on updates
+func VersionETag(version uint32) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// Tagged envelope of a GET response with its ETag
+type Tagged interface {
+	ETag() string
+}
+
+// ETag of the envelope, the version of versioned data and otherwise a weak ETag hashed from the json
+func (d Data[T]) ETag() string {
+	if versioned, ok := any(d.Data).(Versioned); ok {
+		return VersionETag(versioned.GetVersion())
+	}
+	return hashETag(d)
+}
+
+// ETag of the page, a weak ETag hashed from the json
+func (d DataCount[T]) ETag() string {
+	return hashETag(d)
+}
+
+func hashETag(envelope any) string {
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
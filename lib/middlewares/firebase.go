@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -33,7 +34,7 @@ func (f FirebaseAuthMiddleware) HandleAuth(setClaims ...SetClaims) gin.HandlerFu
 	return func(c *gin.Context) {
 		token, err := f.getTokenFromHeader(c.GetHeader(constants.Headers.Authorization.ToString()))
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, json_response.Error[string]{Error: err.Message})
+			c.JSON(err.ErrorType.ToInt(), json_response.Error[string]{Error: err.Message})
 			c.Abort()
 			return
 		}
@@ -108,8 +109,14 @@ func (f FirebaseAuthMiddleware) getTokenFromHeader(header string) (*services.Fir
 
 	token, err := f.service.VerifyToken(idToken)
 	if err != nil {
+		// firebase being unreachable is not the client's fault
+		var serviceErr *services.Error
+		if errors.As(err, &serviceErr) {
+			return nil, serviceErr.ErrorResponse()
+		}
 		return nil, &api_errors.ErrorResponse{
-			Message: err.Message,
+			ErrorType: api_errors.Unauthorized,
+			Message:   "invalid token",
 		}
 	}
 
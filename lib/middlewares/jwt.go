@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"net/http"
+	"slices"
 
 	"boilerplate-api/lib/auth"
 	"boilerplate-api/lib/config"
@@ -33,56 +34,88 @@ func NewJWTAuthMiddleWare(
 // Handle user with jwt using this middleware
 func (m JWTAuthMiddleWare) Handle() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get the token from the request header
-		header := c.GetHeader(constants.Headers.Authorization.ToString())
-
-		tokenString, err := m.jwtService.GetTokenFromHeader(header)
-		if err != nil {
-			m.logger.Error("Error getting token from header: ", err.Message)
-			c.JSON(
-				http.StatusUnauthorized, json_response.Error[string]{
-					Error:   err.Message,
-					Message: "Error getting token from header",
-				},
-			)
-			c.Abort()
-			return
+		if _, ok := m.authenticate(c); ok {
+			c.Next()
 		}
+	}
+}
 
-		// Parsing and Verifying token
-		parsedToken, parseErr := m.jwtService.ParseAndVerifyToken(tokenString, m.env.JwtAccessSecret)
-		if parseErr != nil {
-			m.logger.Error("Error parsing token: ", parseErr.Message)
-			c.JSON(
-				http.StatusUnauthorized, json_response.Error[string]{
-					Error:   parseErr.Message,
-					Message: "Failed to parse and verify token",
-				},
-			)
-			c.Abort()
+// HandleAdmin allows only users with the given roles, admin and super admin by default
+func (m JWTAuthMiddleWare) HandleAdmin(allowedRoles ...constants.Role) gin.HandlerFunc {
+	if len(allowedRoles) == 0 {
+		allowedRoles = []constants.Role{constants.Roles.Admin, constants.Roles.SuperAdmin}
+	}
+	return func(c *gin.Context) {
+		claims, ok := m.authenticate(c)
+		if !ok {
 			return
 		}
-		// Retrieve claims
-		claims, claimsError := m.jwtService.RetrieveClaims(parsedToken)
-		if claimsError != nil {
-			m.logger.Error("Error retrieving claims: ", claimsError.Message)
+		if !slices.Contains(allowedRoles, claims.Role) {
 			c.JSON(
-				http.StatusUnauthorized, json_response.Error[string]{
-					Error:   claimsError.Message,
-					Message: "Failed to retrieve claims from token",
+				http.StatusForbidden, json_response.Error[string]{
+					Error:   "unauthorized request",
+					Message: "Admin role is required",
 				},
 			)
 			c.Abort()
 			return
 		}
-		// ser user to the scope
-		sentry.ConfigureScope(
-			func(scope *sentry.Scope) {
-				scope.SetUser(sentry.User{ID: claims.ID})
+		c.Next()
+	}
+}
+
+// authenticate verifies the access token and sets the user to the context, aborts when it is invalid
+func (m JWTAuthMiddleWare) authenticate(c *gin.Context) (*auth.JWTClaims, bool) {
+	// Get the token from the request header
+	header := c.GetHeader(constants.Headers.Authorization.ToString())
+
+	tokenString, err := m.jwtService.GetTokenFromHeader(header)
+	if err != nil {
+		m.logger.Error("Error getting token from header: ", err.Message)
+		c.JSON(
+			http.StatusUnauthorized, json_response.Error[string]{
+				Error:   err.Message,
+				Message: "Error getting token from header",
 			},
 		)
-		// Can set anything in the request context and passes the request to the next handler.
-		c.Set(constants.UserID, claims.ID)
-		c.Next()
+		c.Abort()
+		return nil, false
+	}
+
+	// Parsing and Verifying token
+	parsedToken, parseErr := m.jwtService.ParseAndVerifyToken(tokenString, m.env.JwtAccessSecret)
+	if parseErr != nil {
+		m.logger.Error("Error parsing token: ", parseErr.Message)
+		c.JSON(
+			http.StatusUnauthorized, json_response.Error[string]{
+				Error:   parseErr.Message,
+				Message: "Failed to parse and verify token",
+			},
+		)
+		c.Abort()
+		return nil, false
+	}
+	// Retrieve claims
+	claims, claimsError := m.jwtService.RetrieveClaims(parsedToken)
+	if claimsError != nil {
+		m.logger.Error("Error retrieving claims: ", claimsError.Message)
+		c.JSON(
+			http.StatusUnauthorized, json_response.Error[string]{
+				Error:   claimsError.Message,
+				Message: "Failed to retrieve claims from token",
+			},
+		)
+		c.Abort()
+		return nil, false
 	}
+	// ser user to the scope
+	sentry.ConfigureScope(
+		func(scope *sentry.Scope) {
+			scope.SetUser(sentry.User{ID: claims.ID})
+		},
+	)
+	// Can set anything in the request context and passes the request to the next handler.
+	c.Set(constants.UserID, claims.ID)
+	c.Set(constants.Roles.Key, claims.Role)
+	return claims, true
 }
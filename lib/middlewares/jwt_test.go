@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-api/lib/auth"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTHandleAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := config.Env{JwtAccessSecret: "secret", AdminEmail: "admin@example.com"}
+	jwtService := auth.NewJWTAuthService(config.GetLogger(), env)
+	middleware := NewJWTAuthMiddleWare(jwtService, config.GetLogger(), env)
+
+	engine := gin.New()
+	engine.GET(
+		"/admin", middleware.HandleAdmin(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		},
+	)
+
+	serve := func(email string) int {
+		token, err := jwtService.GenerateToken(
+			auth.JWTClaims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+					ID:        "1",
+				},
+				Role: jwtService.RoleOf(email),
+			}, env.JwtAccessSecret,
+		)
+		require.NoError(t, err)
+
+		request := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		request.Header.Set(constants.Headers.Authorization.ToString(), "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, serve("user@example.com"), "user token is forbidden")
+	assert.Equal(t, http.StatusOK, serve("Admin@Example.com"), "ADMIN_EMAIL token is allowed")
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "missing token is unauthorized")
+}
@@ -7,6 +7,7 @@ import (
 // Module Middleware exported
 var Module = fx.Options(
 	fx.Provide(NewDBTransactionMiddleware),
+	fx.Provide(NewTimeoutMiddleware),
 	fx.Provide(NewRateLimitMiddleware),
 	fx.Provide(NewJWTAuthMiddleWare),
 	//fx.Provide(NewFirebaseAuthMiddleware),
@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/json_response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutMiddleware cancels context of the request after the timeout
+type TimeoutMiddleware struct {
+	logger config.Logger
+	env    config.Env
+}
+
+// NewTimeoutMiddleware new instance of timeout middleware
+func NewTimeoutMiddleware(logger config.Logger, env config.Env) TimeoutMiddleware {
+	return TimeoutMiddleware{
+		logger: logger,
+		env:    env,
+	}
+}
+
+// Handle cancels the request after REQUEST_TIMEOUT, set on every route by the router
+func (m TimeoutMiddleware) Handle() gin.HandlerFunc {
+	return m.HandleTimeout(m.env.RequestTimeout)
+}
+
+// HandleTimeout cancels the request after the timeout, it replaces the timeout set before on the route.
+// Zero timeout removes it, e.g. for streaming responses.
+//
+// Database queries and api calls taking the request context are canceled, handlers stop at the next one
+// and a 504 is sent when they haven't responded yet
+func (m TimeoutMiddleware) HandleTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		current := c.Request.Context()
+		// derived from the context without the earlier timeout, it would cut the new one short
+		parent := current
+		if request, ok := c.Get(constants.RequestContext); ok {
+			parent = request.(context.Context)
+		} else {
+			c.Set(constants.RequestContext, current)
+		}
+
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(parent, timeout)
+		} else {
+			ctx, cancel = context.WithCancel(parent)
+		}
+		defer cancel()
+
+		c.Request = c.Request.WithContext(valuesOf{Context: ctx, values: current})
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			m.logger.Error("Request timed out after ", timeout, ": ", c.Request.Method, " ", c.FullPath())
+			c.AbortWithStatusJSON(
+				http.StatusGatewayTimeout, json_response.Error[string]{
+					Error:   context.DeadlineExceeded.Error(),
+					Message: "Request timed out",
+				},
+			)
+		}
+	}
+}
+
+// valuesOf context canceled with the embedded context, values are looked up in values
+type valuesOf struct {
+	context.Context
+	values context.Context
+}
+
+func (v valuesOf) Value(key any) any {
+	return v.values.Value(key)
+}
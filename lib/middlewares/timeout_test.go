@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-api/lib/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testKey struct{}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	timeout := NewTimeoutMiddleware(config.GetLogger(), config.Env{RequestTimeout: 20 * time.Millisecond})
+
+	// waits for the context like a query would, responds only when it isn't canceled
+	wait := func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(100 * time.Millisecond):
+			c.JSON(http.StatusOK, gin.H{"value": c.Request.Context().Value(testKey{})})
+		}
+	}
+	setValue := func(c *gin.Context) {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), testKey{}, "kept"))
+	}
+
+	engine := gin.New()
+	engine.Use(timeout.Handle())
+	engine.GET("/default", wait)
+	engine.GET("/longer", setValue, timeout.HandleTimeout(time.Second), wait)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusGatewayTimeout, serve("/default").Code)
+
+	longer := serve("/longer")
+	assert.Equal(t, http.StatusOK, longer.Code, "route timeout replaces the default one")
+	assert.JSONEq(t, `{"value":"kept"}`, longer.Body.String(), "values set before the route timeout are kept")
+}
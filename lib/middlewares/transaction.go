@@ -1,11 +1,12 @@
 package middlewares
 
 import (
+	"bytes"
+	"maps"
 	"net/http"
 
 	"boilerplate-api/lib/config"
-	"boilerplate-api/lib/constants"
-	"boilerplate-api/lib/utils"
+	"boilerplate-api/lib/json_response"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,34 +28,122 @@ func NewDBTransactionMiddleware(
 	}
 }
 
-// DBTransactionHandle It setup the database transaction middleware
+// DBTransactionHandle runs the request in a transaction carried by the request context,
+// committed on 2xx responses and rolled back otherwise
+//
+// the response is held until the transaction is committed, a failed commit responds with 500
+// so clients never see a success that was not saved
 func (m DBTransactionMiddleware) DBTransactionHandle() gin.HandlerFunc {
 	m.logger.Info("setting up database transaction middleware")
 
 	return func(c *gin.Context) {
-		txHandle := m.db.DB.Begin()
+		ctx := c.Request.Context()
+		// the transaction reads its own writes, it always runs on the primary
+		txHandle := m.db.Primary().WithContext(ctx).Begin()
+		if err := txHandle.Error; err != nil {
+			m.logger.Error("trx begin error: ", err)
+			c.AbortWithStatusJSON(
+				http.StatusInternalServerError, json_response.Error[string]{
+					Error:   err.Error(),
+					Message: "Failed to begin database transaction",
+				},
+			)
+			return
+		}
 		m.logger.Info("beginning database transaction")
 
+		// headers set before the handler, e.g. cors, are kept when the response is replaced
+		header := c.Writer.Header().Clone()
+		writer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+
 		defer func() {
 			if r := recover(); r != nil {
-				if err := txHandle.Error; err != nil {
-					m.logger.Error("trx commit error: ", err)
-				}
+				m.logger.Error("rolling back transaction due to panic: ", r)
 				txHandle.Rollback()
+				c.Writer = writer.ResponseWriter
+				panic(r)
 			}
 		}()
 
-		c.Set(constants.DBTransaction, txHandle)
+		c.Request = c.Request.WithContext(config.WithTx(ctx, txHandle))
 		c.Next()
+		c.Writer = writer.ResponseWriter
 
-		if utils.StatusInList(c.Writer.Status(), []int{http.StatusOK, http.StatusCreated}) {
+		if status := writer.status; status >= http.StatusOK && status < http.StatusMultipleChoices {
 			m.logger.Info("committing transactions")
 			if err := txHandle.Commit().Error; err != nil {
 				m.logger.Error("trx commit error: ", err)
+				maps.DeleteFunc(c.Writer.Header(), func(string, []string) bool { return true })
+				maps.Copy(c.Writer.Header(), header)
+				c.AbortWithStatusJSON(
+					http.StatusInternalServerError, json_response.Error[string]{
+						Error:   err.Error(),
+						Message: "Failed to commit database transaction",
+					},
+				)
+				return
 			}
 		} else {
-			m.logger.Info("rolling back transaction due to status code: ", c.Writer.Status())
+			m.logger.Info("rolling back transaction due to status code: ", status)
 			txHandle.Rollback()
 		}
+
+		writer.flush()
+	}
+}
+
+// bufferedWriter holds status and body of the response until flush
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+// Flush is delayed until the transaction is done
+func (w *bufferedWriter) Flush() {}
+
+// flush writes the held response
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
 	}
 }
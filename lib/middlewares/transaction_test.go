@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-api/lib/config"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestDBTransactionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{})
+	assert.NoError(t, err)
+	transaction := NewDBTransactionMiddleware(config.GetLogger(), &config.Database{DB: db})
+
+	engine := gin.New()
+	engine.Use(
+		func(c *gin.Context) {
+			c.Header("Access-Control-Allow-Origin", "*")
+		},
+	)
+	engine.POST(
+		"/created", transaction.DBTransactionHandle(), func(c *gin.Context) {
+			c.Header("Location", "/created/1")
+			c.JSON(http.StatusCreated, gin.H{"id": 1})
+		},
+	)
+	engine.POST(
+		"/invalid", transaction.DBTransactionHandle(), func(c *gin.Context) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid"})
+		},
+	)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+		return recorder
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	created := serve("/created")
+	assert.Equal(t, http.StatusCreated, created.Code)
+	assert.JSONEq(t, `{"id":1}`, created.Body.String())
+	assert.Equal(t, "/created/1", created.Header().Get("Location"))
+
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillReturnError(errors.New("deadlock"))
+	failed := serve("/created")
+	assert.Equal(t, http.StatusInternalServerError, failed.Code, "success is not sent when the commit fails")
+	assert.NotContains(t, failed.Body.String(), `"id"`)
+	assert.Empty(t, failed.Header().Get("Location"))
+	assert.Equal(t, "*", failed.Header().Get("Access-Control-Allow-Origin"))
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	assert.Equal(t, http.StatusBadRequest, serve("/invalid").Code)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
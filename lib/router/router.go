@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/middlewares"
+	"boilerplate-api/lib/utils"
 
 	"github.com/getsentry/sentry-go"
 	sentrygin "github.com/getsentry/sentry-go/gin"
@@ -19,7 +21,11 @@ type Router struct {
 }
 
 // NewRouter : all the routes are defined here
-func NewRouter(env config.Env, logger config.Logger) Router {
+func NewRouter(
+	env config.Env,
+	logger config.Logger,
+	timeoutMiddleware middlewares.TimeoutMiddleware,
+) Router {
 	appEnv := env.Environment
 
 	if appEnv != "local" {
@@ -37,13 +43,16 @@ func NewRouter(env config.Env, logger config.Logger) Router {
 	// TODO :: after cli config
 	// gin.DefaultWriter = logger.GetGinLogger()
 
-	if appEnv == "production" {
+	// cli prints the routes itself, debug logs would be mixed with the command output
+	if appEnv == "production" || utils.IsCli() {
 		gin.SetMode(gin.ReleaseMode)
 	} else {
 		gin.SetMode(gin.DebugMode)
 	}
 
 	httpRouter := gin.Default()
+	// gin context passed as context.Context is canceled with the request
+	httpRouter.ContextWithFallback = true
 
 	httpRouter.Use(
 		cors.New(
@@ -72,6 +81,7 @@ func NewRouter(env config.Env, logger config.Logger) Router {
 
 	api := httpRouter.Group("/api")
 	v1 := api.Group("/v1")
+	v1.Use(timeoutMiddleware.Handle())
 
 	return Router{
 		Engine: httpRouter,
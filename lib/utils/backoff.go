@@ -0,0 +1,17 @@
+package utils
+
+import "time"
+
+// Backoff exponential delay before the next attempt, base after the first attempt and doubled on each one up to max
+//
+//	Backoff(3, 30*time.Second, time.Hour) => 2m
+func Backoff(attempts uint32, base, max time.Duration) time.Duration {
+	delay := base
+	for i := uint32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(0, 30*time.Second, 6*time.Hour))
+	assert.Equal(t, 30*time.Second, Backoff(1, 30*time.Second, 6*time.Hour))
+	assert.Equal(t, time.Minute, Backoff(2, 30*time.Second, 6*time.Hour))
+	assert.Equal(t, 4*time.Minute, Backoff(4, 30*time.Second, 6*time.Hour))
+	assert.Equal(t, 6*time.Hour, Backoff(20, 30*time.Second, 6*time.Hour))
+}
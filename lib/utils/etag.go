@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/json_response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETagMatches checks if the If-None-Match header lists the etag, "*" matches any.
+// ETags are compared weakly, ignoring the W/ prefix
+func ETagMatches(header string, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// DataWithETag responds the envelope with its ETag, or 304 without body when If-None-Match has it
+func DataWithETag(c *gin.Context, data json_response.Tagged) {
+	etag := data.ETag()
+	if etag != "" {
+		c.Header("ETag", etag)
+	}
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" && ETagMatches(ifNoneMatch, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// IfMatchVersion version in the If-Match header of the update, 0 for "*" which matches any version.
+// Updates of versioned rows require it, an ETag that isn't a version never matches
+func IfMatchVersion(c *gin.Context) (uint32, *api_errors.ErrorResponse) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" {
+		return 0, &api_errors.ErrorResponse{
+			ErrorType: api_errors.PreconditionRequired,
+			Message:   "If-Match header with the ETag of the record is required",
+		}
+	}
+	if ifMatch == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseUint(strings.Trim(ifMatch, `"`), 10, 32)
+	if err != nil || version == 0 || ifMatch != json_response.VersionETag(uint32(version)) {
+		return 0, &api_errors.ErrorResponse{
+			ErrorType: api_errors.PreconditionFailed,
+			Message:   "If-Match doesn't match the ETag of the record",
+		}
+	}
+	return uint32(version), nil
+}
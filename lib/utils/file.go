@@ -2,9 +2,17 @@ package utils
 
 import (
 	"errors"
+	"io"
+	"path"
 	"path/filepath"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
 )
 
+// ErrInvalidPath is returned when an upload path escapes the upload root
+var ErrInvalidPath = errors.New("invalid upload path")
+
 func GetFileName(filename string) (string, error) {
 	if filename == "" {
 		return "", errors.New("filename cannot be empty")
@@ -13,3 +21,46 @@ func GetFileName(filename string) (string, error) {
 	fileExtension := filepath.Ext(filename)
 	return GenerateRandomFileName() + fileExtension, nil
 }
+
+// DetectContentType detects the mime type from the file magic bytes
+// and seeks the file back to the beginning for further reads
+func DetectContentType(file io.ReadSeeker) (string, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	mime, err := mimetype.DetectReader(file)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	// strip parameters e.g: `text/plain; charset=utf-8`
+	contentType, _, _ := strings.Cut(mime.String(), ";")
+	return contentType, nil
+}
+
+// SanitizeUploadPath cleans client supplied object path
+//
+// e.g:
+//
+//	/users//avatars/ => users/avatars
+//	users/../../etc  => ErrInvalidPath
+func SanitizeUploadPath(uploadPath string) (string, error) {
+	uploadPath = strings.TrimSpace(strings.ReplaceAll(uploadPath, `\`, "/"))
+	for _, segment := range strings.Split(uploadPath, "/") {
+		if segment == ".." {
+			return "", ErrInvalidPath
+		}
+	}
+
+	cleaned := strings.Trim(path.Clean("/"+uploadPath), "/")
+	if cleaned == "" || strings.ContainsAny(cleaned, "\x00") {
+		return "", ErrInvalidPath
+	}
+
+	return cleaned, nil
+}
@@ -1,17 +1,18 @@
 package utils
 
 import (
+	"fmt"
 	"image"
 	"image/gif"
 	"image/jpeg"
 	"image/png"
-	"mime/multipart"
+	"io"
 
 	"github.com/chai2010/webp"
 )
 
 // DecodeImage image
-func DecodeImage(file multipart.File, fileType string) (image.Image, error) {
+func DecodeImage(file io.ReadSeeker, fileType string) (image.Image, error) {
 	var img image.Image
 	var err error
 
@@ -30,7 +31,7 @@ func DecodeImage(file multipart.File, fileType string) (image.Image, error) {
 	case "image/webp":
 		img, err = webp.Decode(file)
 	default:
-		return nil, err
+		return nil, fmt.Errorf("unsupported image type: %s", fileType)
 	}
-	return img, nil
+	return img, err
 }
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"fmt"
 	"image"
 	"image/gif"
 	"image/jpeg"
@@ -25,6 +26,9 @@ func EncodeImage(file image.Image, fileType string) (*bytes.Buffer, error) {
 	case "image/webp":
 		err = webp.Encode(&img, file, nil)
 	default:
+		return nil, fmt.Errorf("unsupported image type: %s", fileType)
+	}
+	if err != nil {
 		return nil, err
 	}
 	return &img, nil
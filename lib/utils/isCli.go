@@ -12,3 +12,8 @@ func IsCli() bool {
 	}
 	return false
 }
+
+// IsWorker checks if app is running in worker mode, jobs are processed instead of serving http
+func IsWorker() bool {
+	return len(os.Args) > 1 && os.Args[1] == "worker"
+}
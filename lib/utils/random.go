@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/rand"
+	"encoding/hex"
 	"io"
 	"strconv"
 	"time"
@@ -28,6 +29,15 @@ func GenerateRandomFileName() string {
 	return strconv.FormatInt(_time, 10)
 }
 
+// GenerateRandomHex generates hex string from n random bytes
+func GenerateRandomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
 // GenerateRandomDigitSequence generates random digit sequence
 func GenerateRandomDigitSequence(max int) string {
 	b := make([]byte, max)
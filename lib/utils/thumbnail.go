@@ -2,13 +2,13 @@ package utils
 
 import (
 	"bytes"
-	"mime/multipart"
+	"io"
 
 	"github.com/nfnt/resize"
 )
 
 // CreateThumbnail image
-func CreateThumbnail(file multipart.File, fileType string, width, height uint) (*bytes.Buffer, error) {
+func CreateThumbnail(file io.ReadSeeker, fileType string, width, height uint) (*bytes.Buffer, error) {
 	thumbnailImg, err := DecodeImage(file, fileType)
 	if err != nil {
 		return nil, err
@@ -1,18 +1,24 @@
 package utils
 
 import (
-	"io/ioutil"
+	"context"
+	"io"
 	"net/http"
 )
 
-// URLToBinary URL to Binary
-func URLToBinary(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+// URLToBinary URL to Binary, the download is canceled with the context
+func URLToBinary(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
-	imageBinary, err := ioutil.ReadAll(resp.Body)
+	imageBinary, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"context"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+)
+
+// Locker advisory locks of the database, they are held by the session so each lock keeps its own connection
+type Locker struct {
+	db     *config.Database
+	prefix string
+}
+
+// NewLocker creates new locker, lock names are prefixed with the database so apps sharing a server don't collide
+func NewLocker(db *config.Database, env config.Env) Locker {
+	return Locker{
+		db:     db,
+		prefix: env.DBName + ":task:",
+	}
+}
+
+// TryLock acquires lock of the task without waiting, ok is false when another instance holds it
+func (l Locker) TryLock(ctx context.Context, name constants.TaskName) (release func(), ok bool, err error) {
+	return l.db.AdvisoryLock(ctx, l.lockName(name), 0)
+}
+
+// lockName mysql limits lock names to 64 characters
+func (l Locker) lockName(name constants.TaskName) string {
+	lockName := l.prefix + string(name)
+	if len(lockName) > 64 {
+		lockName = lockName[len(lockName)-64:]
+	}
+	return lockName
+}
@@ -0,0 +1,19 @@
+package scheduler
+
+import (
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module(
+	"scheduler",
+	fx.Options(
+		fx.Provide(
+			NewRepository,
+			NewLocker,
+			fx.Annotate(
+				NewScheduler,
+				fx.ParamTags(``, ``, ``, ``, `group:"tasks"`),
+			),
+		),
+	),
+)
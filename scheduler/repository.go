@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/utils"
+
+	"gorm.io/gorm/clause"
+)
+
+// Repository run history of the tasks
+type Repository struct {
+	db     *config.Database
+	logger config.Logger
+}
+
+// NewRepository creates a new task run repository
+func NewRepository(db *config.Database, logger config.Logger) Repository {
+	return Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateRun records started run
+func (r Repository) CreateRun(ctx context.Context, run *dao.TaskRun) error {
+	return r.db.Conn(ctx).Create(run).Error
+}
+
+// CreateScheduledRun records run of the scheduled time, false when it is already recorded by another instance
+func (r Repository) CreateScheduledRun(ctx context.Context, run *dao.TaskRun) (bool, error) {
+	result := r.db.Conn(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(run)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FinishRun records result of the run
+func (r Repository) FinishRun(
+	ctx context.Context,
+	id uint64,
+	status constants.TaskRunStatus,
+	runErr *string,
+	finishedAt time.Time,
+) error {
+	return r.db.Conn(ctx).Model(&dao.TaskRun{}).
+		Where("id = ?", id).
+		Updates(
+			map[string]interface{}{
+				"status":      status,
+				"error":       runErr,
+				"finished_at": finishedAt,
+			},
+		).
+		Error
+}
+
+// GetLastRuns latest run of each task
+func (r Repository) GetLastRuns(ctx context.Context) (runs []dao.TaskRun, err error) {
+	db := r.db.Conn(ctx)
+	return runs, db.
+		Where("id IN (?)", db.Model(&dao.TaskRun{}).Select("MAX(id)").Group("task")).
+		Find(&runs).
+		Error
+}
+
+// GetRuns runs of the task, newest first
+func (r Repository) GetRuns(ctx context.Context, task constants.TaskName, pagination utils.Pagination) (
+	runs []dao.TaskRun,
+	count int64,
+	err error,
+) {
+	return runs, count, r.db.Conn(ctx).Model(&dao.TaskRun{}).
+		Where("task = ?", task).
+		Count(&count).
+		Order("id desc").
+		Limit(pagination.PageSize).
+		Offset(pagination.Offset).
+		Find(&runs).
+		Error
+}
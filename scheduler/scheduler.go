@@ -0,0 +1,301 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/lib/utils"
+
+	"github.com/robfig/cron/v3"
+)
+
+// TaskInfo task with its schedule and last run
+type TaskInfo struct {
+	Name     constants.TaskName `json:"name"`
+	Schedule string             `json:"schedule"`
+	// NextRunAt nil when the scheduler is not running in this instance
+	NextRunAt *time.Time   `json:"next_run_at"`
+	LastRun   *dao.TaskRun `json:"last_run"`
+} // @name TaskInfo
+
+// Scheduler runs the tasks on their schedule, every instance schedules them and the lock picks one
+type Scheduler struct {
+	logger     config.Logger
+	repository Repository
+	locker     Locker
+	tasks      map[constants.TaskName]Task
+	names      []constants.TaskName
+	entries    map[constants.TaskName]cron.EntryID
+	cron       *cron.Cron
+	host       string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	// manual runs in background, scheduled ones are tracked by cron
+	manual sync.WaitGroup
+}
+
+// NewScheduler creates new scheduler, invalid schedule fails the startup
+func NewScheduler(
+	logger config.Logger,
+	env config.Env,
+	repository Repository,
+	locker Locker,
+	tasks []Task,
+) (*Scheduler, error) {
+	location, err := time.LoadLocation(env.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("scheduler time zone: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scheduler := &Scheduler{
+		logger:     logger,
+		repository: repository,
+		locker:     locker,
+		tasks:      map[constants.TaskName]Task{},
+		entries:    map[constants.TaskName]cron.EntryID{},
+		cron:       cron.New(cron.WithLocation(location)),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	scheduler.host, _ = os.Hostname()
+
+	for _, task := range tasks {
+		if _, ok := scheduler.tasks[task.Name()]; ok {
+			return nil, fmt.Errorf("task %s is registered twice", task.Name())
+		}
+		task := task
+		var id cron.EntryID
+		// a slow run is not started again in this instance, other instances are stopped by the lock
+		job := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(
+			cron.FuncJob(
+				func() {
+					// cron sets Prev to the time the run was scheduled at before the snapshot is returned
+					scheduler.run(task, scheduler.cron.Entry(id).Prev)
+				},
+			),
+		)
+		id, err = scheduler.cron.AddJob(task.Schedule(), job)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q of task %s: %w", task.Schedule(), task.Name(), err)
+		}
+		scheduler.tasks[task.Name()] = task
+		scheduler.names = append(scheduler.names, task.Name())
+		scheduler.entries[task.Name()] = id
+	}
+	return scheduler, nil
+}
+
+// Start starts running the tasks on schedule
+func (s *Scheduler) Start() {
+	s.cron.Start()
+	s.logger.Info("✅ Scheduler started with ", len(s.tasks), " tasks")
+}
+
+// Stop stops scheduling and waits for running tasks, they are canceled when ctx is done
+func (s *Scheduler) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		<-s.cron.Stop().Done()
+		s.manual.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logger.Info("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
+
+// Tasks registered tasks with their last run
+func (s *Scheduler) Tasks(ctx context.Context) ([]TaskInfo, *api_errors.ErrorResponse) {
+	runs, err := s.repository.GetLastRuns(ctx)
+	if err != nil {
+		s.logger.Error("Error getting task runs: ", err.Error())
+		return nil, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get tasks",
+		}
+	}
+	lastRuns := map[constants.TaskName]dao.TaskRun{}
+	for _, run := range runs {
+		lastRuns[constants.TaskName(run.Task)] = run
+	}
+
+	infos := make([]TaskInfo, 0, len(s.names))
+	for _, name := range s.names {
+		info := TaskInfo{
+			Name:     name,
+			Schedule: s.tasks[name].Schedule(),
+		}
+		if next := s.cron.Entry(s.entries[name]).Next; !next.IsZero() {
+			info.NextRunAt = &next
+		}
+		if run, ok := lastRuns[name]; ok {
+			info.LastRun = &run
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Runs run history of the task
+func (s *Scheduler) Runs(
+	ctx context.Context,
+	name constants.TaskName,
+	pagination utils.Pagination,
+) ([]dao.TaskRun, int64, *api_errors.ErrorResponse) {
+	if _, ok := s.tasks[name]; !ok {
+		return nil, 0, &api_errors.ErrorResponse{
+			ErrorType: api_errors.NotFound,
+			Message:   "Task not found",
+		}
+	}
+	runs, count, err := s.repository.GetRuns(ctx, name, pagination)
+	if err != nil {
+		s.logger.Error("Error getting task runs: ", err.Error())
+		return nil, 0, &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to get task runs",
+		}
+	}
+	return runs, count, nil
+}
+
+// Trigger runs the task now in background, fails with conflict when it is already running
+func (s *Scheduler) Trigger(name constants.TaskName) *api_errors.ErrorResponse {
+	task, ok := s.tasks[name]
+	if !ok {
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.NotFound,
+			Message:   "Task not found",
+		}
+	}
+
+	release, err := s.lock(task)
+	if err != nil {
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.InternalError,
+			Message:   "Failed to lock task",
+		}
+	}
+	if release == nil {
+		return &api_errors.ErrorResponse{
+			ErrorType: api_errors.Conflict,
+			Message:   "Task is already running",
+		}
+	}
+
+	s.manual.Add(1)
+	go func() {
+		defer s.manual.Done()
+		defer release()
+		run := s.newRun(task, constants.TaskTriggers.Manual)
+		if err := s.repository.CreateRun(s.ctx, &run); err != nil {
+			s.logger.Error("Error recording task run of ", task.Name(), ": ", err.Error())
+		}
+		s.execute(task, run)
+	}()
+	return nil
+}
+
+// run runs scheduled task unless another instance is running it or already ran it for the scheduled time
+//
+// the lock is released when the run finishes, an instance whose clock is a little behind would
+// run a short task again, the run of each scheduled time is recorded once so it is skipped
+func (s *Scheduler) run(task Task, scheduledAt time.Time) {
+	release, err := s.lock(task)
+	if err != nil || release == nil {
+		return
+	}
+	defer release()
+
+	run := s.newRun(task, constants.TaskTriggers.Schedule)
+	run.ScheduledAt = &scheduledAt
+	created, err := s.repository.CreateScheduledRun(s.ctx, &run)
+	if err != nil {
+		s.logger.Error("Error recording task run of ", task.Name(), ": ", err.Error())
+		return
+	}
+	if !created {
+		s.logger.Info("Task ", task.Name(), " scheduled at ", scheduledAt, " already ran in another instance, skipped")
+		return
+	}
+	s.execute(task, run)
+}
+
+// lock returns nil release when the lock is held by another instance
+func (s *Scheduler) lock(task Task) (func(), error) {
+	release, ok, err := s.locker.TryLock(s.ctx, task.Name())
+	if err != nil {
+		s.logger.Error("Error locking task ", task.Name(), ": ", err.Error())
+		return nil, err
+	}
+	if !ok {
+		s.logger.Info("Task ", task.Name(), " is running in another instance, skipped")
+		return nil, nil
+	}
+	return release, nil
+}
+
+// newRun run of the task starting now
+func (s *Scheduler) newRun(task Task, trigger constants.TaskTrigger) dao.TaskRun {
+	return dao.TaskRun{
+		Task:        string(task.Name()),
+		TriggeredBy: string(trigger),
+		Status:      string(constants.TaskRunStatuses.Running),
+		Host:        s.host,
+		StartedAt:   time.Now(),
+	}
+}
+
+// execute runs the task with the lock held and records the result of the run
+func (s *Scheduler) execute(task Task, run dao.TaskRun) {
+	err := s.call(task)
+	status := constants.TaskRunStatuses.Succeeded
+	var runErr *string
+	if err != nil {
+		s.logger.Error("Task ", task.Name(), " failed: ", err.Error())
+		status = constants.TaskRunStatuses.Failed
+		message := err.Error()
+		runErr = &message
+	}
+
+	if run.ID == 0 {
+		return
+	}
+	// recorded even when the scheduler stopped and canceled the task
+	if err := s.repository.FinishRun(context.WithoutCancel(s.ctx), run.ID, status, runErr, time.Now()); err != nil {
+		s.logger.Error("Error recording task run of ", task.Name(), ": ", err.Error())
+	}
+}
+
+// call runs the task with timeout, panics are returned as errors
+func (s *Scheduler) call(task Task) (err error) {
+	ctx, cancel := context.WithTimeout(s.ctx, constants.TaskTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	err = task.Run(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s: %w", constants.TaskTimeout, err)
+	}
+	return err
+}
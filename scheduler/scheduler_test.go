@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/tests"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func newTestLocker(t *testing.T) (Locker, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{})
+	assert.NoError(t, err)
+	return NewLocker(&config.Database{DB: db}, config.Env{DBName: "app"}), mock
+}
+
+func TestLockerTryLock(t *testing.T) {
+	locker, mock := newTestLocker(t)
+
+	mock.ExpectQuery("SELECT GET_LOCK").
+		WithArgs("app:task:outbox.purge", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectExec("DO RELEASE_LOCK").
+		WithArgs("app:task:outbox.purge").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT GET_LOCK").
+		WithArgs("app:task:outbox.purge", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(0))
+
+	release, ok, err := locker.TryLock(context.Background(), constants.TaskNames.PurgeOutbox)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	release()
+
+	release, ok, err = locker.TryLock(context.Background(), constants.TaskNames.PurgeOutbox)
+	assert.NoError(t, err)
+	assert.False(t, ok, "held by another instance")
+	assert.Nil(t, release)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Len(t, locker.lockName(constants.TaskName(strings.Repeat("a", 100))), 64)
+}
+
+func TestNewScheduler(t *testing.T) {
+	locker, _ := newTestLocker(t)
+	noop := func(context.Context) error { return nil }
+	env := config.Env{TimeZone: "UTC"}
+
+	scheduler, err := NewScheduler(
+		config.GetLogger(), env, Repository{}, locker, []Task{
+			NewTask("hourly", "@hourly", noop),
+			NewTask("cron", "*/5 * * * *", noop),
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []constants.TaskName{"hourly", "cron"}, scheduler.names)
+
+	_, err = NewScheduler(config.GetLogger(), env, Repository{}, locker, []Task{NewTask("invalid", "every hour", noop)})
+	assert.ErrorContains(t, err, "invalid")
+
+	_, err = NewScheduler(
+		config.GetLogger(), env, Repository{}, locker, []Task{
+			NewTask("twice", "@daily", noop),
+			NewTask("twice", "@hourly", noop),
+		},
+	)
+	assert.ErrorContains(t, err, "registered twice")
+
+	errResponse := scheduler.Trigger("unknown")
+	assert.Equal(t, "Task not found", errResponse.Message)
+}
+
+func TestSchedulerRunOncePerScheduledTime(t *testing.T) {
+	db := tests.NewDatabase(t)
+	env := tests.NewEnv(t)
+	runs := 0
+	task := NewTask(
+		"counted", "@hourly", func(context.Context) error {
+			runs++
+			return nil
+		},
+	)
+	newScheduler := func() *Scheduler {
+		scheduler, err := NewScheduler(
+			config.GetLogger(), env, NewRepository(db, config.GetLogger()), NewLocker(db, env), []Task{task},
+		)
+		assert.NoError(t, err)
+		return scheduler
+	}
+
+	// the second instance ticks after the first one finished and released the lock
+	scheduledAt := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	newScheduler().run(task, scheduledAt)
+	newScheduler().run(task, scheduledAt)
+	assert.Equal(t, 1, runs)
+
+	newScheduler().run(task, scheduledAt.Add(time.Hour))
+	assert.Equal(t, 2, runs)
+
+	var recorded []dao.TaskRun
+	assert.NoError(t, db.Order("id").Find(&recorded).Error)
+	if assert.Len(t, recorded, 2) {
+		assert.Equal(t, string(constants.TaskRunStatuses.Succeeded), recorded[0].Status)
+		assert.True(t, scheduledAt.Equal(*recorded[0].ScheduledAt))
+	}
+}
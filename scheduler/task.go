@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+
+	"boilerplate-api/lib/constants"
+)
+
+// Task runs on a cron schedule, tasks are provided to the `group:"tasks"`
+//
+// schedule is a standard 5 field cron expression or a descriptor like @hourly, @every 10m,
+// in the TZ time zone. only one instance runs the task at a time
+type Task interface {
+	Name() constants.TaskName
+	Schedule() string
+	Run(ctx context.Context) error
+}
+
+type funcTask struct {
+	name     constants.TaskName
+	schedule string
+	run      func(ctx context.Context) error
+}
+
+// NewTask creates task running the function
+//
+//	scheduler.NewTask(constants.TaskNames.PurgeOutbox, "@daily", service.PurgeSent)
+func NewTask(name constants.TaskName, schedule string, run func(ctx context.Context) error) Task {
+	return funcTask{
+		name:     name,
+		schedule: schedule,
+		run:      run,
+	}
+}
+
+func (t funcTask) Name() constants.TaskName {
+	return t.name
+}
+
+func (t funcTask) Schedule() string {
+	return t.schedule
+}
+
+func (t funcTask) Run(ctx context.Context) error {
+	return t.run(ctx)
+}
@@ -2,6 +2,7 @@ package aws
 
 import (
 	"boilerplate-api/lib/config"
+	"boilerplate-api/services"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"go.uber.org/fx"
 )
@@ -36,5 +37,11 @@ var Module = fx.Module(
 				)
 			},
 		),
+		// signed url service presigns s3 objects through the bucket service
+		fx.Provide(
+			func(s3Bucket S3BucketService) services.S3Presigner {
+				return s3Bucket
+			},
+		),
 	),
 )
@@ -1,12 +1,20 @@
 package aws
 
 import (
+	"errors"
+
+	"boilerplate-api/services"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"go.uber.org/zap"
+	"io"
 	"mime/multipart"
+	"time"
 
 	"context"
 )
@@ -41,22 +49,77 @@ func NewS3BucketService(
 }
 
 // UploadToS3 uploads the file to the aws s3 bucket
+//
+// contentType should be detected from file content rather than the client header
 func (s S3BucketService) UploadToS3(
+	ctx context.Context,
 	file multipart.File,
-	fileHeader *multipart.FileHeader,
+	contentType string,
+	fileName string,
+) (string, error) {
+	return s.UploadFile(ctx, file, fileName, contentType)
+}
+
+// UploadFile streams the reader to the aws s3 bucket
+//
+// manager.Uploader switches to multipart upload for large files
+func (s S3BucketService) UploadFile(
+	ctx context.Context,
+	file io.Reader,
 	fileName string,
+	contentType string,
 ) (string, error) {
 	uploader := manager.NewUploader(s.client)
-	result, err := uploader.Upload(context.TODO(), &s3.PutObjectInput{
+	result, err := uploader.Upload(ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(s.s3bucket),
 		Key:         aws.String(fileName),
 		Body:        file,
-		ContentType: aws.String(fileHeader.Header.Get("content-type")),
+		ContentType: aws.String(contentType),
 		ACL:         types.ObjectCannedACLPublicRead,
 	})
 	if err != nil {
-		s.logger.Fatalf("aws s3 cloud bucket upload error: %v", err.Error())
-		return "", err
+		s.logger.Errorf("aws s3 cloud bucket upload error: %v", err.Error())
+		return "", s3Error(err, "Failed to upload file")
 	}
 	return result.Location, nil
 }
+
+// PresignGetObject creates presigned GET url for the object
+//
+// disposition sets response Content-Disposition header when not empty
+func (s S3BucketService) PresignGetObject(
+	ctx context.Context,
+	key string,
+	expiry time.Duration,
+	disposition string,
+) (string, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.s3bucket),
+		Key:    aws.String(key),
+	}
+	if disposition != "" {
+		input.ResponseContentDisposition = aws.String(disposition)
+	}
+
+	request, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, input, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", s3Error(err, "Failed to sign url")
+	}
+	return request.URL, nil
+}
+
+// s3Error wraps aws api error with its http status and error code
+func s3Error(err error, message string) error {
+	statusCode := 0
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) {
+		statusCode = responseErr.HTTPStatusCode()
+	}
+
+	code := ""
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code = apiErr.ErrorCode()
+	}
+	return services.NewError(services.Providers.S3, statusCode, code, message, err)
+}
@@ -0,0 +1,470 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/utils"
+)
+
+// headerLineLength lines are folded at whitespace beyond this length, RFC 5322 2.1.1
+const headerLineLength = 78
+
+// ErrEmailHeaderLineBreak header value with CR or LF, it would inject headers into the message
+var ErrEmailHeaderLineBreak = errors.New("email header contains a line break")
+
+// Charsets of the email text, headers are RFC 2047 encoded in the same charset
+var Charsets = struct {
+	UTF8      string
+	ISO2022JP string
+}{
+	UTF8:      "UTF-8",
+	ISO2022JP: "ISO-2022-JP",
+}
+
+// EmailSender sends emails through the driver selected by MAIL_DRIVER
+type EmailSender interface {
+	Send(ctx context.Context, params EmailParams) error
+}
+
+// EmailAttachment file attached to the email
+type EmailAttachment struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+	// ContentID makes the attachment inline, referenced as cid:<ContentID> from HTMLBody
+	ContentID string
+}
+
+func (a EmailAttachment) inline() bool {
+	return a.ContentID != ""
+}
+
+// EmailParams email to send, at least one of TextBody and HTMLBody is required
+type EmailParams struct {
+	From    mail.Address
+	To      []mail.Address
+	Cc      []mail.Address
+	Bcc     []mail.Address
+	ReplyTo []mail.Address
+	Subject string
+	// TextBody plain text alternative, clients without html support show this
+	TextBody    string
+	HTMLBody    string
+	Attachments []EmailAttachment
+	// Charset defaults to UTF-8, ISO-2022-JP is for japanese clients that can't read UTF-8
+	Charset string
+}
+
+// recipients envelope recipients including Bcc
+func (p EmailParams) recipients() []string {
+	recipients := make([]string, 0, len(p.To)+len(p.Cc)+len(p.Bcc))
+	for _, addresses := range [][]mail.Address{p.To, p.Cc, p.Bcc} {
+		for _, address := range addresses {
+			recipients = append(recipients, address.Address)
+		}
+	}
+	return recipients
+}
+
+// validateHeaders rejects CR and LF in the values that end up in a header
+func (p EmailParams) validateHeaders() error {
+	values := []string{p.Subject, p.From.Name, p.From.Address}
+	for _, addresses := range [][]mail.Address{p.To, p.Cc, p.Bcc, p.ReplyTo} {
+		for _, address := range addresses {
+			values = append(values, address.Name, address.Address)
+		}
+	}
+	for _, attachment := range p.Attachments {
+		values = append(values, attachment.FileName, attachment.ContentType, attachment.ContentID)
+	}
+	for _, value := range values {
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%w: %q", ErrEmailHeaderLineBreak, value)
+		}
+	}
+	return nil
+}
+
+func (p EmailParams) charset() string {
+	if p.Charset == "" {
+		return Charsets.UTF8
+	}
+	return p.Charset
+}
+
+// compose builds MIME message of the email
+//
+//	multipart/mixed             when there are attachments
+//	  multipart/related         when there are inline images
+//	    multipart/alternative   when there are both text and html
+//	      text/plain
+//	      text/html
+//	    image/png               inline
+//	  application/pdf           attachment
+//
+// Bcc header is only written when withBcc is set, e.g. gmail api reads recipients from it
+// but smtp must not reveal it to the other recipients
+func (p EmailParams) compose(withBcc bool) ([]byte, error) {
+	if len(p.To)+len(p.Cc)+len(p.Bcc) == 0 {
+		return nil, fmt.Errorf("email has no recipients")
+	}
+	if p.TextBody == "" && p.HTMLBody == "" {
+		return nil, fmt.Errorf("email has no body")
+	}
+	if err := p.validateHeaders(); err != nil {
+		return nil, err
+	}
+
+	charset := p.charset()
+	buf := new(bytes.Buffer)
+
+	header := textproto.MIMEHeader{}
+	header.Set("MIME-Version", "1.0")
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID(p.From.Address))
+	if p.From.Address != "" {
+		header.Set("From", formatAddresses(charset, []mail.Address{p.From}))
+	}
+	if len(p.To) > 0 {
+		header.Set("To", formatAddresses(charset, p.To))
+	}
+	if len(p.Cc) > 0 {
+		header.Set("Cc", formatAddresses(charset, p.Cc))
+	}
+	if withBcc && len(p.Bcc) > 0 {
+		header.Set("Bcc", formatAddresses(charset, p.Bcc))
+	}
+	if len(p.ReplyTo) > 0 {
+		header.Set("Reply-To", formatAddresses(charset, p.ReplyTo))
+	}
+	subject, err := encodeHeader(charset, p.Subject)
+	if err != nil {
+		return nil, err
+	}
+	header.Set("Subject", subject)
+
+	var inline, attached []EmailAttachment
+	for _, attachment := range p.Attachments {
+		if attachment.inline() {
+			inline = append(inline, attachment)
+		} else {
+			attached = append(attached, attachment)
+		}
+	}
+
+	writeBody := p.writeAlternative
+	if len(inline) > 0 {
+		writeBody = related(writeBody, inline)
+	}
+	if len(attached) > 0 {
+		writeBody = mixed(writeBody, attached)
+	}
+
+	bodyHeader, body, err := writeBody(charset)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range bodyHeader {
+		header[key] = values
+	}
+	writeHeader(buf, header)
+	buf.Write(body)
+	return buf.Bytes(), nil
+}
+
+// partWriter writes the part body and returns its headers
+type partWriter func(charset string) (textproto.MIMEHeader, []byte, error)
+
+// writeAlternative text and html bodies, single part when only one of them is set
+func (p EmailParams) writeAlternative(charset string) (textproto.MIMEHeader, []byte, error) {
+	if p.HTMLBody == "" {
+		return textPart(charset, "text/plain", p.TextBody)
+	}
+	if p.TextBody == "" {
+		return textPart(charset, "text/html", p.HTMLBody)
+	}
+
+	return multipartOf(
+		"alternative",
+		func(writer *multipart.Writer) error {
+			for _, part := range []struct{ contentType, text string }{
+				{"text/plain", p.TextBody},
+				{"text/html", p.HTMLBody},
+			} {
+				header, body, err := textPart(charset, part.contentType, part.text)
+				if err != nil {
+					return err
+				}
+				if err := writePart(writer, header, body); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// related html body with the inline images it references
+func related(writeBody partWriter, inline []EmailAttachment) partWriter {
+	return func(charset string) (textproto.MIMEHeader, []byte, error) {
+		return multipartOf(
+			"related",
+			func(writer *multipart.Writer) error {
+				header, body, err := writeBody(charset)
+				if err != nil {
+					return err
+				}
+				if err := writePart(writer, header, body); err != nil {
+					return err
+				}
+				return writeAttachments(writer, charset, inline)
+			},
+		)
+	}
+}
+
+// mixed body followed by the attachments
+func mixed(writeBody partWriter, attached []EmailAttachment) partWriter {
+	return func(charset string) (textproto.MIMEHeader, []byte, error) {
+		return multipartOf(
+			"mixed",
+			func(writer *multipart.Writer) error {
+				header, body, err := writeBody(charset)
+				if err != nil {
+					return err
+				}
+				if err := writePart(writer, header, body); err != nil {
+					return err
+				}
+				return writeAttachments(writer, charset, attached)
+			},
+		)
+	}
+}
+
+func multipartOf(subtype string, write func(writer *multipart.Writer) error) (textproto.MIMEHeader, []byte, error) {
+	buf := new(bytes.Buffer)
+	writer := multipart.NewWriter(buf)
+	if err := write(writer); err != nil {
+		return nil, nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": writer.Boundary()}))
+	return header, buf.Bytes(), nil
+}
+
+// textPart UTF-8 text is quoted-printable encoded, ISO-2022-JP is already 7bit
+func textPart(charset, contentType, text string) (textproto.MIMEHeader, []byte, error) {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(contentType, map[string]string{"charset": charset}))
+
+	text = normalizeNewlines(text)
+	if strings.EqualFold(charset, Charsets.ISO2022JP) {
+		encoded, err := utils.ToISO2022JP(text)
+		if err != nil {
+			return nil, nil, err
+		}
+		header.Set("Content-Transfer-Encoding", "7bit")
+		return header, encoded, nil
+	}
+
+	buf := new(bytes.Buffer)
+	writer := quotedprintable.NewWriter(buf)
+	if _, err := writer.Write([]byte(text)); err != nil {
+		return nil, nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, nil, err
+	}
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	return header, buf.Bytes(), nil
+}
+
+func writeAttachments(writer *multipart.Writer, charset string, attachments []EmailAttachment) error {
+	for _, attachment := range attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		fileName, err := encodeHeader(charset, attachment.FileName)
+		if err != nil {
+			return err
+		}
+
+		disposition := "attachment"
+		header := textproto.MIMEHeader{}
+		if attachment.inline() {
+			disposition = "inline"
+			header.Set("Content-ID", "<"+attachment.ContentID+">")
+		}
+		header.Set("Content-Type", mime.FormatMediaType(contentType, map[string]string{"name": fileName}))
+		header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": fileName}))
+		header.Set("Content-Transfer-Encoding", "base64")
+
+		if err := writePart(writer, header, base64Lines(attachment.Content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePart(writer *multipart.Writer, header textproto.MIMEHeader, body []byte) error {
+	for key, values := range header {
+		for i, value := range values {
+			values[i] = strings.TrimPrefix(foldHeader(key, value), key+": ")
+		}
+	}
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(body)
+	return err
+}
+
+// writeHeader writes folded headers in a stable order, followed by the blank line
+func writeHeader(w io.Writer, header textproto.MIMEHeader) {
+	order := []string{
+		"From", "To", "Cc", "Bcc", "Reply-To", "Subject", "Date", "Message-ID",
+		"MIME-Version", "Content-Type", "Content-Transfer-Encoding",
+	}
+	for _, key := range order {
+		for _, value := range header.Values(key) {
+			_, _ = io.WriteString(w, foldHeader(key, value)+"\r\n")
+		}
+		header.Del(key)
+	}
+	for key, values := range header {
+		for _, value := range values {
+			_, _ = io.WriteString(w, foldHeader(key, value)+"\r\n")
+		}
+	}
+	_, _ = io.WriteString(w, "\r\n")
+}
+
+// foldHeader header line folded at whitespace so lines stay within 78 characters,
+// a word longer than that stays on its own line as RFC 5322 2.2.3 allows
+func foldHeader(key, value string) string {
+	line := key + ": " + value
+	folded := new(strings.Builder)
+	// the first line keeps "Key: " together, continuation lines start with the whitespace
+	start := len(key) + 2
+	for len(line) > headerLineLength {
+		at := strings.LastIndexAny(line[start:headerLineLength+1], " \t")
+		if at == -1 {
+			at = strings.IndexAny(line[headerLineLength+1:], " \t")
+			if at == -1 {
+				break
+			}
+			at += headerLineLength + 1
+		} else {
+			at += start
+		}
+		folded.WriteString(line[:at] + "\r\n")
+		line = line[at:]
+		start = 1
+	}
+	folded.WriteString(line)
+	return folded.String()
+}
+
+// encodeHeader RFC 2047 encoded-word of non ascii text in the charset
+func encodeHeader(charset, text string) (string, error) {
+	if isASCII(text) {
+		return text, nil
+	}
+	if strings.EqualFold(charset, Charsets.ISO2022JP) {
+		encoded, err := utils.ToISO2022JP(text)
+		if err != nil {
+			return "", err
+		}
+		return mime.BEncoding.Encode(Charsets.ISO2022JP, string(encoded)), nil
+	}
+	return mime.BEncoding.Encode(charset, text), nil
+}
+
+func formatAddresses(charset string, addresses []mail.Address) string {
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if address.Name == "" {
+			formatted = append(formatted, address.Address)
+			continue
+		}
+		name, err := encodeHeader(charset, address.Name)
+		if err != nil || name == address.Name {
+			// mail.Address quotes the name when it has specials like commas
+			formatted = append(formatted, (&mail.Address{Name: address.Name, Address: address.Address}).String())
+			continue
+		}
+		formatted = append(formatted, fmt.Sprintf("%s <%s>", name, address.Address))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// messageID unique id in the domain of the sender
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at != -1 {
+		domain = from[at+1:]
+	}
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
+
+// base64Lines base64 wrapped at 76 characters as MIME requires
+func base64Lines(content []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	buf := new(bytes.Buffer)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded)
+	return buf.Bytes()
+}
+
+// normalizeNewlines text parts use CRLF line endings
+func normalizeNewlines(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\n", "\r\n")
+}
+
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// emailParamsError invalid params are bugs of ours, not of the provider
+func emailParamsError(provider Provider, err error) error {
+	return &Error{
+		Provider:   provider,
+		Code:       "invalid_email",
+		Message:    "Failed to send email",
+		HTTPStatus: api_errors.InternalError,
+		Err:        err,
+	}
+}
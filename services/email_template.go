@@ -0,0 +1,255 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// ErrEmailTemplateNotFound template doesn't exist in the locale nor the default locale
+var ErrEmailTemplateNotFound = errors.New("email template not found")
+
+// RenderedEmail subject and bodies of the template, HTML is wrapped in the layout
+type RenderedEmail struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+} // @name RenderedEmail
+
+type etLogger interface {
+	Info(args ...interface{})
+}
+
+type EmailTemplateConfig struct {
+	// fsys contains the emails directory:
+	//
+	//	emails/layouts/*.html           layout executing {{template "content"}} and partials
+	//	emails/partials/*.html          {{define}} blocks shared by templates
+	//	emails/<locale>/<name>.subject.txt
+	//	emails/<locale>/<name>.html     content of the layout, optional
+	//	emails/<locale>/<name>.txt      plain text alternative, optional
+	//	emails/samples/<name>.json      preview data, optional
+	fsys          fs.FS
+	defaultLocale string
+	logger        etLogger
+}
+
+type emailTemplate struct {
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+// EmailTemplates templates parsed once at startup
+type EmailTemplates struct {
+	defaultLocale string
+	// templates by locale and name
+	templates map[string]map[string]emailTemplate
+	samples   map[string]map[string]interface{}
+}
+
+// emailTemplateFuncs functions available in every template
+var emailTemplateFuncs = map[string]interface{}{
+	// dict builds map to pass several values to a partial
+	"dict": func(pairs ...interface{}) (map[string]interface{}, error) {
+		if len(pairs)%2 != 0 {
+			return nil, errors.New("dict expects key value pairs")
+		}
+		dict := make(map[string]interface{}, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			key, ok := pairs[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("dict key %v is not a string", pairs[i])
+			}
+			dict[key] = pairs[i+1]
+		}
+		return dict, nil
+	},
+}
+
+// NewEmailTemplates parses every template, invalid templates fail the startup instead of the send
+func NewEmailTemplates(templateConfig EmailTemplateConfig) (EmailTemplates, error) {
+	templates := EmailTemplates{
+		defaultLocale: templateConfig.defaultLocale,
+		templates:     map[string]map[string]emailTemplate{},
+		samples:       map[string]map[string]interface{}{},
+	}
+
+	layout, err := htmltemplate.New("layout").
+		Funcs(emailTemplateFuncs).
+		Option("missingkey=error").
+		ParseFS(templateConfig.fsys, "emails/layouts/*.html", "emails/partials/*.html")
+	if err != nil {
+		return templates, fmt.Errorf("email layouts: %w", err)
+	}
+
+	subjects, err := fs.Glob(templateConfig.fsys, "emails/*/*.subject.txt")
+	if err != nil {
+		return templates, err
+	}
+	for _, subjectPath := range subjects {
+		locale := path.Base(path.Dir(subjectPath))
+		name := strings.TrimSuffix(path.Base(subjectPath), ".subject.txt")
+
+		tmpl, err := parseEmailTemplate(templateConfig.fsys, layout, path.Join("emails", locale, name))
+		if err != nil {
+			return templates, fmt.Errorf("email template %s/%s: %w", locale, name, err)
+		}
+		if templates.templates[locale] == nil {
+			templates.templates[locale] = map[string]emailTemplate{}
+		}
+		templates.templates[locale][name] = tmpl
+	}
+
+	samples, err := fs.Glob(templateConfig.fsys, "emails/samples/*.json")
+	if err != nil {
+		return templates, err
+	}
+	for _, samplePath := range samples {
+		content, err := fs.ReadFile(templateConfig.fsys, samplePath)
+		if err != nil {
+			return templates, err
+		}
+		sample := map[string]interface{}{}
+		if err := json.Unmarshal(content, &sample); err != nil {
+			return templates, fmt.Errorf("email sample %s: %w", samplePath, err)
+		}
+		templates.samples[strings.TrimSuffix(path.Base(samplePath), ".json")] = sample
+	}
+
+	templateConfig.logger.Info("✅ Email templates parsed: ", len(subjects))
+	return templates, nil
+}
+
+func parseEmailTemplate(fsys fs.FS, layout *htmltemplate.Template, base string) (emailTemplate, error) {
+	tmpl := emailTemplate{}
+
+	subject, err := fs.ReadFile(fsys, base+".subject.txt")
+	if err != nil {
+		return tmpl, err
+	}
+	if tmpl.subject, err = newTextTemplate(strings.TrimSpace(string(subject))); err != nil {
+		return tmpl, err
+	}
+
+	if text, err := fs.ReadFile(fsys, base+".txt"); err == nil {
+		if tmpl.text, err = newTextTemplate(string(text)); err != nil {
+			return tmpl, err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return tmpl, err
+	}
+
+	if content, err := fs.ReadFile(fsys, base+".html"); err == nil {
+		if tmpl.html, err = layout.Clone(); err != nil {
+			return tmpl, err
+		}
+		if _, err = tmpl.html.New("content").Parse(string(content)); err != nil {
+			return tmpl, err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return tmpl, err
+	}
+
+	if tmpl.html == nil && tmpl.text == nil {
+		return tmpl, errors.New("template has neither html nor text body")
+	}
+	return tmpl, nil
+}
+
+func newTextTemplate(text string) (*texttemplate.Template, error) {
+	return texttemplate.New("").Funcs(emailTemplateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// Render renders the template in the locale, falls back to the language and then the default locale
+//
+//	ja-JP => ja-JP, ja, en
+func (e EmailTemplates) Render(name, locale string, data map[string]interface{}) (RenderedEmail, error) {
+	rendered := RenderedEmail{}
+	tmpl, locale, ok := e.lookup(name, locale)
+	if !ok {
+		return rendered, fmt.Errorf("%w: %s", ErrEmailTemplateNotFound, name)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.subject.Execute(buf, data); err != nil {
+		return rendered, err
+	}
+	rendered.Subject = buf.String()
+
+	if tmpl.text != nil {
+		buf.Reset()
+		if err := tmpl.text.Execute(buf, data); err != nil {
+			return rendered, err
+		}
+		rendered.Text = buf.String()
+	}
+
+	if tmpl.html != nil {
+		buf.Reset()
+		layoutData := map[string]interface{}{
+			"Locale":  locale,
+			"Subject": rendered.Subject,
+			"Data":    data,
+		}
+		if err := tmpl.html.ExecuteTemplate(buf, "layout", layoutData); err != nil {
+			return rendered, err
+		}
+		rendered.HTML = buf.String()
+	}
+	return rendered, nil
+}
+
+// Has template exists in the locale or one of its fallbacks
+func (e EmailTemplates) Has(name, locale string) bool {
+	_, _, ok := e.lookup(name, locale)
+	return ok
+}
+
+// Preview renders the template with its sample data, given data overrides the sample values
+func (e EmailTemplates) Preview(name, locale string, data map[string]interface{}) (RenderedEmail, error) {
+	merged := map[string]interface{}{}
+	for key, value := range e.samples[name] {
+		merged[key] = value
+	}
+	for key, value := range data {
+		merged[key] = value
+	}
+	return e.Render(name, locale, merged)
+}
+
+// Locales locales of each template name
+func (e EmailTemplates) Locales() map[string][]string {
+	locales := map[string][]string{}
+	for locale, templates := range e.templates {
+		for name := range templates {
+			locales[name] = append(locales[name], locale)
+		}
+	}
+	for name := range locales {
+		sort.Strings(locales[name])
+	}
+	return locales
+}
+
+func (e EmailTemplates) lookup(name, locale string) (emailTemplate, string, bool) {
+	candidates := []string{locale}
+	if language, _, found := strings.Cut(locale, "-"); found {
+		candidates = append(candidates, language)
+	}
+	candidates = append(candidates, e.defaultLocale)
+
+	for _, candidate := range candidates {
+		if tmpl, ok := e.templates[candidate][name]; ok {
+			return tmpl, candidate, true
+		}
+	}
+	return emailTemplate{}, "", false
+}
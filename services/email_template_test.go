@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"boilerplate-api/templates"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(...interface{}) {}
+
+func TestEmailTemplates(t *testing.T) {
+	fsys := fstest.MapFS{
+		"emails/layouts/base.html":      {Data: []byte(`{{define "layout"}}<html lang="{{.Locale}}"><title>{{.Subject}}</title>{{template "content" .Data}}</html>{{end}}`)},
+		"emails/partials/footer.html":   {Data: []byte(`{{define "footer"}}bye{{end}}`)},
+		"emails/en/welcome.subject.txt": {Data: []byte("Welcome {{.Name}}\n")},
+		"emails/en/welcome.html":        {Data: []byte(`<p>Hi {{.Name}}</p>{{template "footer"}}`)},
+		"emails/en/welcome.txt":         {Data: []byte("Hi {{.Name}}")},
+		"emails/ja/welcome.subject.txt": {Data: []byte("ようこそ {{.Name}}")},
+		"emails/ja/welcome.txt":         {Data: []byte("こんにちは {{.Name}}")},
+		"emails/samples/welcome.json":   {Data: []byte(`{"Name": "Sample"}`)},
+	}
+	emailTemplates, err := NewEmailTemplates(EmailTemplateConfig{fsys: fsys, defaultLocale: "en", logger: nopLogger{}})
+	assert.NoError(t, err)
+
+	rendered, err := emailTemplates.Render("welcome", "fr", map[string]interface{}{"Name": "<Bob>"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome <Bob>", rendered.Subject)
+	assert.Equal(t, "Hi <Bob>", rendered.Text)
+	assert.Equal(
+		t,
+		`<html lang="en"><title>Welcome &lt;Bob&gt;</title><p>Hi &lt;Bob&gt;</p>bye</html>`,
+		rendered.HTML,
+	)
+
+	rendered, err = emailTemplates.Render("welcome", "ja-JP", map[string]interface{}{"Name": "太郎"})
+	assert.NoError(t, err)
+	assert.Equal(t, RenderedEmail{Subject: "ようこそ 太郎", Text: "こんにちは 太郎"}, rendered)
+
+	rendered, err = emailTemplates.Preview("welcome", "en", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome Sample", rendered.Subject)
+
+	_, err = emailTemplates.Render("welcome", "en", map[string]interface{}{})
+	assert.Error(t, err, "missing keys fail the render")
+
+	_, err = emailTemplates.Render("goodbye", "en", nil)
+	assert.ErrorIs(t, err, ErrEmailTemplateNotFound)
+	assert.True(t, emailTemplates.Has("welcome", "fr"), "falls back to the default locale")
+	assert.False(t, emailTemplates.Has("goodbye", "en"))
+
+	assert.Equal(t, map[string][]string{"welcome": {"en", "ja"}}, emailTemplates.Locales())
+}
+
+func TestEmbeddedEmailTemplates(t *testing.T) {
+	emailTemplates, err := NewEmailTemplates(EmailTemplateConfig{fsys: templates.Emails, defaultLocale: "en", logger: nopLogger{}})
+	assert.NoError(t, err)
+
+	// every template renders with its sample data
+	for name, locales := range emailTemplates.Locales() {
+		for _, locale := range locales {
+			_, err := emailTemplates.Preview(name, locale, nil)
+			assert.NoError(t, err, name, locale)
+		}
+	}
+}
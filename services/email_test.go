@@ -0,0 +1,244 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"boilerplate-api/lib/api_errors"
+	"boilerplate-api/lib/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMailCaptureSender(t *testing.T) {
+	dir := t.TempDir()
+	sender := NewMailCaptureSender(MailCaptureConfig{dir: dir, logger: config.GetLogger().SugaredLogger})
+
+	err := sender.Send(
+		context.Background(), EmailParams{
+			From:     mail.Address{Name: "Boilerplate", Address: "noreply@example.com"},
+			To:       []mail.Address{{Address: "user@example.com"}},
+			Bcc:      []mail.Address{{Address: "audit@example.com"}},
+			Subject:  "Welcome",
+			TextBody: "Hello",
+		},
+	)
+	assert.NoError(t, err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.Len(t, files, 1)
+	raw, _ := os.ReadFile(files[0])
+	assert.Contains(t, string(raw), "To: user@example.com\r\n")
+	assert.Contains(t, string(raw), "Bcc: audit@example.com\r\n")
+	assert.Contains(t, string(raw), "Subject: Welcome\r\n")
+	assert.Contains(t, string(raw), "\r\n\r\nHello")
+}
+
+func TestSendGridSender(t *testing.T) {
+	var received sendGridMail
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v3/mail/send", r.URL.Path)
+				assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				if received.Personalizations[0].To[0].Email == "invalid" {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"errors":[{"message":"Does not contain a valid address.","field":"personalizations.0.to.0.email"}]}`))
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			},
+		),
+	)
+	defer server.Close()
+
+	sender := NewSendGridSender(
+		SendGridConfig{baseURL: server.URL, apiKey: "key", logger: config.GetLogger().SugaredLogger},
+	)
+
+	err := sender.Send(
+		context.Background(), EmailParams{
+			From:     mail.Address{Address: "noreply@example.com"},
+			To:       []mail.Address{{Address: "user@example.com"}},
+			Subject:  "Welcome",
+			TextBody: "Hello",
+			HTMLBody: "<p>Hello</p>",
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "noreply@example.com", received.From.Email)
+	assert.Equal(t, []sendGridContent{{"text/plain", "Hello"}, {"text/html", "<p>Hello</p>"}}, received.Content)
+
+	err = sender.Send(context.Background(), EmailParams{To: []mail.Address{{Address: "invalid"}}, TextBody: "Hello"})
+	assert.Equal(t, api_errors.BadRequest, ToErrorResponse(err, "").ErrorType)
+	assert.False(t, IsRetryable(err))
+}
+
+func TestComposeEmail(t *testing.T) {
+	params := EmailParams{
+		From:     mail.Address{Name: "運営チーム", Address: "noreply@example.com"},
+		To:       []mail.Address{{Name: "Taro", Address: "taro@example.com"}, {Address: "hanako@example.com"}},
+		Cc:       []mail.Address{{Address: "cc@example.com"}},
+		Bcc:      []mail.Address{{Address: "bcc@example.com"}},
+		ReplyTo:  []mail.Address{{Address: "support@example.com"}},
+		Subject:  "ご登録ありがとうございます",
+		TextBody: "Hello\nWorld",
+		HTMLBody: `<p>Hello</p><img src="cid:logo">`,
+		Attachments: []EmailAttachment{
+			{FileName: "logo.png", ContentType: "image/png", Content: []byte("png"), ContentID: "logo"},
+			{FileName: "invoice.pdf", ContentType: "application/pdf", Content: []byte("pdf")},
+		},
+	}
+
+	raw, err := params.compose(false)
+	assert.NoError(t, err)
+
+	message, err := mail.ReadMessage(bytes.NewReader(raw))
+	assert.NoError(t, err)
+	assert.Empty(t, message.Header.Get("Bcc"), "smtp message must not reveal bcc recipients")
+	assert.Equal(t, "taro@example.com, hanako@example.com, cc@example.com, bcc@example.com", strings.Join(params.recipients(), ", "))
+
+	decoder := new(mime.WordDecoder)
+	subject, _ := decoder.DecodeHeader(message.Header.Get("Subject"))
+	assert.Equal(t, "ご登録ありがとうございます", subject)
+	from, _ := message.Header.AddressList("From")
+	assert.Equal(t, "運営チーム", from[0].Name)
+	to, _ := message.Header.AddressList("To")
+	assert.Len(t, to, 2)
+
+	// mixed(related(alternative(text, html), logo), invoice)
+	mixed := readParts(t, message.Header.Get("Content-Type"), message.Body)
+	assert.Len(t, mixed, 2)
+	assert.Equal(t, "attachment; filename=invoice.pdf", mixed[1].header.Get("Content-Disposition"))
+	assert.Equal(t, "pdf", string(mixed[1].body))
+
+	related := readParts(t, mixed[0].header.Get("Content-Type"), bytes.NewReader(mixed[0].body))
+	assert.Len(t, related, 2)
+	assert.Equal(t, "<logo>", related[1].header.Get("Content-ID"))
+
+	alternative := readParts(t, related[0].header.Get("Content-Type"), bytes.NewReader(related[0].body))
+	assert.Len(t, alternative, 2)
+	assert.Equal(t, "text/plain; charset=UTF-8", alternative[0].header.Get("Content-Type"))
+	assert.Equal(t, "Hello\r\nWorld", string(alternative[0].body))
+	assert.Equal(t, "text/html; charset=UTF-8", alternative[1].header.Get("Content-Type"))
+
+	raw, err = params.compose(true)
+	assert.NoError(t, err)
+	message, _ = mail.ReadMessage(bytes.NewReader(raw))
+	assert.Equal(t, "bcc@example.com", message.Header.Get("Bcc"))
+}
+
+func TestComposeEmailISO2022JP(t *testing.T) {
+	raw, err := EmailParams{
+		To:       []mail.Address{{Address: "taro@example.com"}},
+		Subject:  "お知らせ",
+		TextBody: "こんにちは",
+		Charset:  Charsets.ISO2022JP,
+	}.compose(false)
+	assert.NoError(t, err)
+
+	message, _ := mail.ReadMessage(bytes.NewReader(raw))
+	assert.True(t, strings.HasPrefix(message.Header.Get("Subject"), "=?ISO-2022-JP?b?"))
+	assert.Equal(t, "text/plain; charset=ISO-2022-JP", message.Header.Get("Content-Type"))
+	assert.Equal(t, "7bit", message.Header.Get("Content-Transfer-Encoding"))
+	for _, b := range raw {
+		assert.Less(t, b, byte(0x80), "ISO-2022-JP message must be 7bit")
+	}
+}
+
+func TestComposeEmailHeaderLineBreak(t *testing.T) {
+	for name, params := range map[string]EmailParams{
+		"subject":    {Subject: "Hello\r\nBcc: victim@example.com"},
+		"name":       {To: []mail.Address{{Name: "Taro\nBcc: victim@example.com", Address: "taro@example.com"}}},
+		"address":    {ReplyTo: []mail.Address{{Address: "support@example.com\r\nX-Injected: 1"}}},
+		"attachment": {Attachments: []EmailAttachment{{FileName: "invoice.pdf\r\nX-Injected: 1", Content: []byte("pdf")}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if params.To == nil {
+				params.To = []mail.Address{{Address: "taro@example.com"}}
+			}
+			params.TextBody = "Hello"
+			_, err := params.compose(false)
+			assert.ErrorIs(t, err, ErrEmailHeaderLineBreak)
+		})
+	}
+}
+
+func TestComposeEmailFoldHeaders(t *testing.T) {
+	subject := strings.Repeat("Your weekly report is ready ", 6) + strings.Repeat("x", 100)
+	raw, err := EmailParams{
+		To:       []mail.Address{{Name: "佐藤 太郎", Address: "taro@example.com"}, {Name: "Hanako Yamada", Address: "hanako@example.com"}, {Address: "jiro@example.com"}},
+		Subject:  subject,
+		TextBody: "Hello",
+		Attachments: []EmailAttachment{
+			{FileName: strings.Repeat("年次報告書", 10) + ".pdf", ContentType: "application/pdf", Content: []byte("pdf")},
+		},
+	}.compose(false)
+	assert.NoError(t, err)
+
+	head, _, _ := strings.Cut(string(raw), "\r\n\r\n")
+	for _, line := range strings.Split(head, "\r\n") {
+		if !strings.HasSuffix(line, strings.Repeat("x", 100)) {
+			assert.LessOrEqual(t, len(line), headerLineLength, line)
+		}
+	}
+
+	message, err := mail.ReadMessage(bytes.NewReader(raw))
+	assert.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(subject), message.Header.Get("Subject"))
+	to, err := message.Header.AddressList("To")
+	assert.NoError(t, err)
+	assert.Len(t, to, 3)
+	assert.Equal(t, "佐藤 太郎", to[0].Name)
+
+	mixed := readParts(t, message.Header.Get("Content-Type"), message.Body)
+	assert.Len(t, mixed, 2)
+	_, params, err := mime.ParseMediaType(mixed[1].header.Get("Content-Disposition"))
+	assert.NoError(t, err)
+	fileName, _ := new(mime.WordDecoder).DecodeHeader(params["filename"])
+	assert.Equal(t, strings.Repeat("年次報告書", 10)+".pdf", fileName)
+}
+
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// readParts decoded parts of the multipart body
+func readParts(t *testing.T, contentType string, body io.Reader) []mimePart {
+	_, params, err := mime.ParseMediaType(contentType)
+	assert.NoError(t, err)
+
+	var parts []mimePart
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		assert.NoError(t, err)
+
+		var content []byte
+		if part.Header.Get("Content-Transfer-Encoding") == "base64" {
+			content, _ = io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		} else {
+			// multipart reader decodes quoted-printable itself
+			content, _ = io.ReadAll(part)
+		}
+		parts = append(parts, mimePart{header: part.Header, body: content})
+	}
+}
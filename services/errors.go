@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"boilerplate-api/lib/api_errors"
+)
+
+// Provider external service behind the wrapper
+type Provider string
+
+var Providers = struct {
+	Stripe   Provider
+	Twilio   Provider
+	Gmail    Provider
+	SMTP     Provider
+	SendGrid Provider
+	Capture  Provider
+	S3       Provider
+	Firebase Provider
+}{
+	Stripe:   "stripe",
+	Twilio:   "twilio",
+	Gmail:    "gmail",
+	SMTP:     "smtp",
+	SendGrid: "sendgrid",
+	Capture:  "capture",
+	S3:       "s3",
+	Firebase: "firebase",
+}
+
+// Error error returned by every external service wrapper
+//
+// Message is safe to show to api clients, the provider error is kept in Err
+type Error struct {
+	Provider Provider
+	// Code provider error code, e.g. card_declined for stripe or 21211 for twilio
+	Code    string
+	Message string
+	// Retryable the same request may succeed later
+	Retryable bool
+	// HTTPStatus status our api should respond with
+	HTTPStatus api_errors.HttpErrorType
+	Err        error
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("%s: %s", e.Provider, e.Message)
+	if e.Code != "" {
+		message = fmt.Sprintf("%s: %s (%s)", e.Provider, e.Message, e.Code)
+	}
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorResponse api error response of the error
+func (e *Error) ErrorResponse() *api_errors.ErrorResponse {
+	return &api_errors.ErrorResponse{
+		ErrorType: e.HTTPStatus,
+		Message:   e.Message,
+	}
+}
+
+// ToErrorResponse converts error returned by the service wrappers to api error response
+//
+// errors of other types are reported as internal error with the given message
+func ToErrorResponse(err error, message string) *api_errors.ErrorResponse {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.ErrorResponse()
+	}
+	return &api_errors.ErrorResponse{
+		ErrorType: api_errors.InternalError,
+		Message:   message,
+	}
+}
+
+// IsRetryable the failed request may succeed when retried
+func IsRetryable(err error) bool {
+	var serviceErr *Error
+	return errors.As(err, &serviceErr) && serviceErr.Retryable
+}
+
+// NewError creates error of the provider from its http status code
+//
+// provider 4xx are client errors, 401/403 mean our credentials are wrong,
+// 408/429/5xx and network failures are retryable
+func NewError(provider Provider, statusCode int, code, message string, err error) *Error {
+	serviceErr := &Error{
+		Provider:   provider,
+		Code:       code,
+		Message:    message,
+		HTTPStatus: api_errors.BadGateway,
+		Err:        err,
+	}
+
+	switch {
+	case statusCode == 0:
+		serviceErr.Retryable = isTemporary(err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			serviceErr.HTTPStatus = api_errors.GatewayTimeout
+		}
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		serviceErr.HTTPStatus = api_errors.InternalError
+	case statusCode == http.StatusNotFound:
+		serviceErr.HTTPStatus = api_errors.NotFound
+	case statusCode == http.StatusConflict:
+		serviceErr.HTTPStatus = api_errors.Conflict
+	case statusCode == http.StatusRequestTimeout:
+		serviceErr.HTTPStatus = api_errors.GatewayTimeout
+		serviceErr.Retryable = true
+	case statusCode == http.StatusTooManyRequests:
+		serviceErr.HTTPStatus = api_errors.Unavailable
+		serviceErr.Retryable = true
+	case statusCode >= 500:
+		serviceErr.Retryable = true
+	case statusCode >= 400:
+		serviceErr.HTTPStatus = api_errors.BadRequest
+	}
+	return serviceErr
+}
+
+// isTemporary network errors and timeouts without response from the provider
+func isTemporary(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"boilerplate-api/lib/api_errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stripe/stripe-go/v76"
+)
+
+func TestNewError(t *testing.T) {
+	cases := []struct {
+		statusCode int
+		err        error
+		status     api_errors.HttpErrorType
+		retryable  bool
+	}{
+		{http.StatusBadRequest, nil, api_errors.BadRequest, false},
+		{http.StatusUnauthorized, nil, api_errors.InternalError, false},
+		{http.StatusNotFound, nil, api_errors.NotFound, false},
+		{http.StatusConflict, nil, api_errors.Conflict, false},
+		{http.StatusTooManyRequests, nil, api_errors.Unavailable, true},
+		{http.StatusServiceUnavailable, nil, api_errors.BadGateway, true},
+		{0, context.DeadlineExceeded, api_errors.GatewayTimeout, true},
+		{0, errors.New("malformed response"), api_errors.BadGateway, false},
+	}
+
+	for _, c := range cases {
+		serviceErr := NewError(Providers.Twilio, c.statusCode, "", "Failed to send sms", c.err)
+		assert.Equal(t, c.status, serviceErr.HTTPStatus, c.statusCode)
+		assert.Equal(t, c.retryable, serviceErr.Retryable, c.statusCode)
+	}
+}
+
+func TestToErrorResponse(t *testing.T) {
+	wrapped := fmt.Errorf("subscribe: %w", NewError(Providers.Stripe, http.StatusNotFound, "resource_missing", "No such price", nil))
+	assert.Equal(
+		t,
+		&api_errors.ErrorResponse{ErrorType: api_errors.NotFound, Message: "No such price"},
+		ToErrorResponse(wrapped, "Failed"),
+	)
+	assert.Equal(
+		t,
+		&api_errors.ErrorResponse{ErrorType: api_errors.InternalError, Message: "Failed"},
+		ToErrorResponse(errors.New("db error"), "Failed"),
+	)
+}
+
+func TestStripeCardError(t *testing.T) {
+	backend := new(stripeBackendMock)
+	stripeService := newTestStripeService(backend)
+
+	backend.On(
+		"Call",
+		"POST",
+		"/v1/subscriptions",
+		"sk_test",
+		mock.Anything,
+		mock.Anything,
+	).Return(
+		&stripe.Error{
+			Type:           stripe.ErrorTypeCard,
+			Code:           stripe.ErrorCodeCardDeclined,
+			DeclineCode:    stripe.DeclineCodeInsufficientFunds,
+			HTTPStatusCode: http.StatusPaymentRequired,
+			Msg:            "Your card has insufficient funds.",
+		},
+	).Once()
+
+	_, err := stripeService.CreateSubscription(context.Background(), SubscriptionOptions{CustomerID: "cus_1", PriceID: "price_1"})
+
+	var serviceErr *Error
+	assert.ErrorAs(t, err, &serviceErr)
+	assert.Equal(t, Providers.Stripe, serviceErr.Provider)
+	assert.Equal(t, "insufficient_funds", serviceErr.Code)
+	assert.Equal(t, api_errors.PaymentRequired, serviceErr.HTTPStatus)
+	assert.Equal(t, "Your card has insufficient funds.", serviceErr.Message)
+	assert.False(t, IsRetryable(err))
+
+	var stripeErr *stripe.Error
+	assert.ErrorAs(t, err, &stripeErr)
+	backend.AssertExpectations(t)
+}
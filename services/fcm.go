@@ -0,0 +1,148 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"boilerplate-api/lib/api_errors"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// ErrFCMNotConfigured service account key is not available
+var ErrFCMNotConfigured = errors.New("fcm is not configured")
+
+// PushMessage notification sent to a single device
+type PushMessage struct {
+	Token string
+	Title string
+	Body  string
+	// Data key value pairs delivered to the app, optional
+	Data map[string]string
+}
+
+type fcmLogger interface {
+	Info(args ...interface{})
+	Error(args ...interface{})
+}
+
+type FCMConfig struct {
+	serviceAccountPath string
+	// baseURL overrides fcm api url, used in tests
+	baseURL string
+	logger  fcmLogger
+}
+
+// FCMService sends push notifications through firebase cloud messaging http v1 api
+type FCMService struct {
+	client    *http.Client
+	baseURL   string
+	projectID string
+	logger    fcmLogger
+}
+
+// NewFCMService creates fcm client from the service account key, Send fails when it is missing
+func NewFCMService(fcmConfig FCMConfig) FCMService {
+	service := FCMService{
+		baseURL: fcmConfig.baseURL,
+		logger:  fcmConfig.logger,
+	}
+	if service.baseURL == "" {
+		service.baseURL = "https://fcm.googleapis.com"
+	}
+
+	jsonKey, err := os.ReadFile(fcmConfig.serviceAccountPath)
+	if err != nil {
+		fcmConfig.logger.Error("Unable to read service account key, push notifications are disabled: ", err.Error())
+		return service
+	}
+	credentials, err := google.CredentialsFromJSON(context.Background(), jsonKey, fcmScope)
+	if err != nil {
+		fcmConfig.logger.Error("Invalid service account key, push notifications are disabled: ", err.Error())
+		return service
+	}
+
+	service.client = oauth2.NewClient(context.Background(), credentials.TokenSource)
+	service.projectID = credentials.ProjectID
+	fcmConfig.logger.Info("✅ FCM service created.")
+	return service
+}
+
+// Send sends the message, unregistered tokens are returned as *Error with NotFound status
+func (s FCMService) Send(ctx context.Context, message PushMessage) error {
+	if s.client == nil {
+		return &Error{
+			Provider:   Providers.Firebase,
+			Code:       "not_configured",
+			Message:    "Failed to send push notification",
+			HTTPStatus: api_errors.InternalError,
+			Err:        ErrFCMNotConfigured,
+		}
+	}
+
+	payload, err := json.Marshal(
+		map[string]interface{}{
+			"message": map[string]interface{}{
+				"token": message.Token,
+				"notification": map[string]string{
+					"title": message.Title,
+					"body":  message.Body,
+				},
+				"data": message.Data,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/v1/projects/%s/messages:send", s.baseURL, s.projectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return NewError(Providers.Firebase, 0, "", "Failed to send push notification", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	result := struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}{}
+	_ = json.Unmarshal(body, &result)
+	return NewError(
+		Providers.Firebase,
+		res.StatusCode,
+		result.Error.Status,
+		"Failed to send push notification",
+		errors.New(result.Error.Message),
+	)
+}
+
+// IsUnregisteredToken the device token is no longer valid and should be removed
+func IsUnregisteredToken(err error) bool {
+	var serviceErr *Error
+	return errors.As(err, &serviceErr) &&
+		serviceErr.Provider == Providers.Firebase &&
+		(serviceErr.Code == "NOT_FOUND" || serviceErr.Code == "UNREGISTERED")
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-api/lib/api_errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFCMSend(t *testing.T) {
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/projects/project-1/messages:send", r.URL.Path)
+
+				body := struct {
+					Message struct {
+						Token string `json:"token"`
+					} `json:"message"`
+				}{}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				if body.Message.Token == "expired" {
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND"}}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"name":"projects/project-1/messages/1"}`))
+			},
+		),
+	)
+	defer server.Close()
+
+	fcm := FCMService{client: server.Client(), baseURL: server.URL, projectID: "project-1"}
+
+	assert.NoError(t, fcm.Send(context.Background(), PushMessage{Token: "valid", Title: "Hi"}))
+
+	err := fcm.Send(context.Background(), PushMessage{Token: "expired", Title: "Hi"})
+	assert.True(t, IsUnregisteredToken(err))
+	assert.Equal(t, api_errors.NotFound, ToErrorResponse(err, "").ErrorType)
+
+	err = FCMService{}.Send(context.Background(), PushMessage{Token: "valid"})
+	assert.ErrorIs(t, err, ErrFCMNotConfigured)
+}
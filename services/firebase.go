@@ -2,12 +2,6 @@ package services
 
 import "context"
 
-// AuthErrorResponse structure
-type AuthErrorResponse struct {
-	Message   string `json:"message"`
-	ErrorType int    `json:"error_type"`
-}
-
 // FirebaseToken Replace this with firebase.google.com/go/auth auth.Token
 type FirebaseToken struct {
 	AuthTime int64                  `json:"auth_time"`
@@ -21,10 +15,11 @@ type FirebaseToken struct {
 }
 
 type IFirebaseMiddlewareService interface {
-	VerifyToken(token string) (*FirebaseToken, *AuthErrorResponse)
+	// VerifyToken invalid tokens are returned as *Error with Unauthorized status
+	VerifyToken(token string) (*FirebaseToken, error)
 }
 
 type IFirebaseAdminSeed interface {
 	GetUserByEmail(context context.Context, email string) (interface{}, error)
-	CreateUser(displayName, email, password, role string) (string, *AuthErrorResponse)
+	CreateUser(displayName, email, password, role string) (string, error)
 }
@@ -4,31 +4,23 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
-	"fmt"
 	"time"
 
-	"boilerplate-api/lib/utils"
+	"boilerplate-api/lib/api_errors"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/option"
 
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
 )
 
-type EmailParams struct {
-	To              string
-	From            string
-	SenderEmail     string
-	SubjectData     string
-	SubjectTemplate string
-	BodyData        interface{}
-	BodyTemplate    string
-	Lang            string
-}
+// ErrGmailNotConfigured gmail client could not be created from the MAIL_* variables
+var ErrGmailNotConfigured = errors.New("gmail client is not configured")
 
 type gLogger interface {
-	Fatal(args ...interface{})
+	Error(args ...interface{})
 }
 
 type GmailConfig struct {
@@ -64,7 +56,8 @@ func NewGmailService(gmailConfig GmailConfig) GmailService {
 	var tokenSource = oauthConfig.TokenSource(ctx, &token)
 	_service, err := gmail.NewService(ctx, option.WithTokenSource(tokenSource))
 	if err != nil {
-		gmailConfig.logger.Fatal("failed to receive gmail client", err.Error())
+		// SendEmail reports ErrGmailNotConfigured instead of stopping the whole api
+		gmailConfig.logger.Error("failed to receive gmail client", err.Error())
 	}
 
 	return GmailService{
@@ -73,50 +66,42 @@ func NewGmailService(gmailConfig GmailConfig) GmailService {
 	}
 }
 
-func (g GmailService) SendEmail(params EmailParams) (bool, error) {
-	to := params.To
-	from := params.From
-	sender := params.SenderEmail
-	emailBody, err := utils.ParseTemplate(params.BodyTemplate, params.BodyData)
-	if err != nil {
-		return false, errors.New("unable to parse email body template")
-	}
-	var msgString string
-	emailTo := "To: " + to + "\r\n"
-	msgString = emailTo
-	subject := "Subject: " + params.SubjectData + "\n"
-	msgString = msgString + subject
-	msgString = msgString + "\n" + emailBody
-	var msg []byte
-
-	var _from string
-
-	if from != "" && sender != "" {
-		// sender should be email from which mail is being sent
-		if params.Lang != "en" {
-			encodedName := base64.StdEncoding.EncodeToString([]byte(from))
-			_from = fmt.Sprintf("From: =?UTF-8?B?%s?= <%s>\r\n", encodedName, sender)
-		} else {
-			_from = fmt.Sprintf("From: \"%s\" <%s>\r\n", from, sender)
+// Send sends the email as the authorized gmail account
+func (g GmailService) Send(ctx context.Context, params EmailParams) error {
+	if g.Service == nil {
+		return &Error{
+			Provider:   Providers.Gmail,
+			Code:       "not_configured",
+			Message:    "Failed to send email",
+			HTTPStatus: api_errors.InternalError,
+			Err:        ErrGmailNotConfigured,
 		}
 	}
 
-	if _from != "" {
-		msgString = _from + msgString
-	}
-
-	if params.Lang != "en" {
-		msgStringJP, _ := utils.ToISO2022JP(msgString)
-		msg = msgStringJP
-	} else {
-		msg = []byte(msgString)
+	// gmail delivers to the Bcc header and removes it from the sent message
+	msg, err := params.compose(true)
+	if err != nil {
+		return emailParamsError(Providers.Gmail, err)
 	}
 	message := gmail.Message{
 		Raw: base64.URLEncoding.EncodeToString(msg),
 	}
-	_, err = g.Users.Messages.Send("me", &message).Do()
-	if err != nil {
-		return false, err
+	if _, err := g.Users.Messages.Send("me", &message).Context(ctx).Do(); err != nil {
+		return gmailError(err)
+	}
+	return nil
+}
+
+// gmailError wraps google api error with its http status, oauth token failures have none
+func gmailError(err error) error {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return NewError(Providers.Gmail, 0, "", "Failed to send email", err)
+	}
+
+	code := ""
+	if len(apiErr.Errors) > 0 {
+		code = apiErr.Errors[0].Reason
 	}
-	return true, nil
+	return NewError(Providers.Gmail, apiErr.Code, code, "Failed to send email", err)
 }
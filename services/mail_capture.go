@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type captureLogger interface {
+	Info(args ...interface{})
+}
+
+type MailCaptureConfig struct {
+	dir    string
+	logger captureLogger
+}
+
+// MailCaptureSender writes emails as .eml files instead of sending them, for development and tests
+type MailCaptureSender struct {
+	dir    string
+	logger captureLogger
+}
+
+// NewMailCaptureSender creates capture sender, emails are written to dir (defaults to tmp/mail)
+func NewMailCaptureSender(captureConfig MailCaptureConfig) MailCaptureSender {
+	if captureConfig.dir == "" {
+		captureConfig.dir = filepath.Join("tmp", "mail")
+	}
+	captureConfig.logger.Info("✅ Mail capture sender created, emails are written to ", captureConfig.dir)
+	return MailCaptureSender{
+		dir:    captureConfig.dir,
+		logger: captureConfig.logger,
+	}
+}
+
+// Send writes the raw message, files are named by time so they sort in the order sent
+func (m MailCaptureSender) Send(_ context.Context, params EmailParams) error {
+	msg, err := params.compose(true)
+	if err != nil {
+		return emailParamsError(Providers.Capture, err)
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(
+		m.dir,
+		fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), uuid.NewString()),
+	)
+	if err := os.WriteFile(path, msg, 0o644); err != nil {
+		return err
+	}
+
+	m.logger.Info("Email to ", params.recipients(), " captured: ", path)
+	return nil
+}
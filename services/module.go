@@ -2,12 +2,12 @@ package services
 
 import (
 	"boilerplate-api/lib/config"
-	"boilerplate-api/services/aws"
+	"boilerplate-api/lib/constants"
+	"boilerplate-api/templates"
 	"go.uber.org/fx"
 )
 
 var Module = fx.Options(
-	aws.Module,
 	// StripeService provider
 	fx.Provide(
 		func(
@@ -16,27 +16,75 @@ var Module = fx.Options(
 		) StripeService {
 			return NewStripeService(
 				StripeConfig{
-					stripeSecretKey: env.StripeSecretKey,
-					stripeProductID: env.StripeProductID,
-					logger:          logger.SugaredLogger,
+					stripeSecretKey:   env.StripeSecretKey,
+					stripeProductID:   env.StripeProductID,
+					stripeWebhookKey:  env.StripeWebhookKey,
+					stripeRedirectURL: env.StripeRedirectUrl,
+					logger:            logger.SugaredLogger,
 				},
 			)
 		},
 	),
-	// GmailService provider
+	// EmailSender provider, only the driver selected by MAIL_DRIVER is created
 	fx.Provide(
 		func(
 			env config.Env,
 			logger config.Logger,
-		) GmailService {
-			return NewGmailService(
-				GmailConfig{
-					clientID:     env.MailClientID,
-					clientSecret: env.MailClientSecret,
-					accessToken:  env.MailAccesstoken,
-					refreshToken: env.MailRefreshToken,
-					hostURL:      env.HOST,
-					logger:       logger.SugaredLogger,
+		) EmailSender {
+			switch constants.MailDriver(env.MailDriver) {
+			case constants.MailDrivers.Gmail, "":
+				return NewGmailService(
+					GmailConfig{
+						clientID:     env.MailClientID,
+						clientSecret: env.MailClientSecret,
+						accessToken:  env.MailAccesstoken,
+						refreshToken: env.MailRefreshToken,
+						hostURL:      env.HOST,
+						logger:       logger.SugaredLogger,
+					},
+				)
+			case constants.MailDrivers.SMTP:
+				return NewSMTPSender(
+					SMTPConfig{
+						host:       env.MailSMTPHost,
+						port:       env.MailSMTPPort,
+						username:   env.MailSMTPUsername,
+						password:   env.MailSMTPPassword,
+						encryption: constants.SMTPEncryption(env.MailSMTPEncryption),
+						timeout:    env.MailTimeout,
+						logger:     logger.SugaredLogger,
+					},
+				)
+			case constants.MailDrivers.SendGrid:
+				return NewSendGridSender(
+					SendGridConfig{
+						baseURL: env.MailAPIURL,
+						apiKey:  env.MailAPIKey,
+						timeout: env.MailTimeout,
+						logger:  logger.SugaredLogger,
+					},
+				)
+			case constants.MailDrivers.Capture:
+				return NewMailCaptureSender(
+					MailCaptureConfig{
+						dir:    env.MailCaptureDir,
+						logger: logger.SugaredLogger,
+					},
+				)
+			default:
+				logger.Fatal("Unknown MAIL_DRIVER: ", env.MailDriver)
+				return nil
+			}
+		},
+	),
+	// EmailTemplates provider
+	fx.Provide(
+		func(logger config.Logger) (EmailTemplates, error) {
+			return NewEmailTemplates(
+				EmailTemplateConfig{
+					fsys:          templates.Emails,
+					defaultLocale: constants.DefaultLocale,
+					logger:        logger.SugaredLogger,
 				},
 			)
 		},
@@ -58,4 +106,54 @@ var Module = fx.Options(
 			)
 		},
 	),
+	// FCMService provider
+	fx.Provide(
+		func(
+			logger config.Logger,
+		) FCMService {
+			return NewFCMService(
+				FCMConfig{
+					serviceAccountPath: "serviceAccountKey.json",
+					logger:             logger.SugaredLogger,
+				},
+			)
+		},
+	),
+	// FileScanner provider
+	fx.Provide(
+		func(
+			env config.Env,
+			logger config.Logger,
+		) FileScanner {
+			if env.ClamAVAddress == "" {
+				logger.Info("CLAMAV_ADDRESS not set, upload scanning is disabled")
+				return NoopScanner{}
+			}
+			return NewClamAVScanner(
+				ClamAVConfig{
+					address: env.ClamAVAddress,
+					timeout: env.ClamAVTimeout,
+					logger:  logger.SugaredLogger,
+				},
+			)
+		},
+	),
+	// SignedURLService provider
+	fx.Provide(
+		func(
+			env config.Env,
+			logger config.Logger,
+			s3Bucket S3Presigner,
+		) SignedURLService {
+			return NewSignedURLService(
+				SignedURLConfig{
+					gcsBucket:          env.StorageBucketName,
+					serviceAccountPath: "serviceAccountKey.json",
+					expiry:             env.SignedURLExpiry,
+					s3Bucket:           s3Bucket,
+					logger:             logger.SugaredLogger,
+				},
+			)
+		},
+	),
 )
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamd INSTREAM chunk size, must stay below clamd StreamMaxLength
+const clamAVChunkSize = 64 * 1024
+
+// ScanResult result of a malware scan
+type ScanResult struct {
+	Infected  bool
+	Signature string
+}
+
+// FileScanner scans uploaded content before it is persisted
+type FileScanner interface {
+	Scan(ctx context.Context, file io.Reader) (ScanResult, error)
+}
+
+type scLogger interface {
+	Info(args ...interface{})
+}
+
+type ClamAVConfig struct {
+	address string
+	timeout time.Duration
+	logger  scLogger
+}
+
+// ClamAVScanner scans files with a clamd daemon using the INSTREAM command
+type ClamAVScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVScanner creates new clamd scanner
+//
+// address can be tcp (`clamav:3310`) or unix socket (`unix:/run/clamav/clamd.ctl`)
+func NewClamAVScanner(clamAVConfig ClamAVConfig) ClamAVScanner {
+	network, address := "tcp", clamAVConfig.address
+	if strings.HasPrefix(address, "unix:") {
+		network, address = "unix", strings.TrimPrefix(address, "unix:")
+	}
+
+	timeout := clamAVConfig.timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	clamAVConfig.logger.Info("✅ ClamAV scanner created.")
+	return ClamAVScanner{
+		network: network,
+		address: address,
+		timeout: timeout,
+	}
+}
+
+// Scan streams file to clamd and parses the verdict
+func (s ClamAVScanner) Scan(ctx context.Context, file io.Reader) (ScanResult, error) {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+
+	if _, err = conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return ScanResult{}, err
+	}
+
+	buf := make([]byte, clamAVChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := file.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err = conn.Write(size); err != nil {
+				return ScanResult{}, err
+			}
+			if _, err = conn.Write(buf[:n]); err != nil {
+				return ScanResult{}, err
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return ScanResult{}, readErr
+		}
+	}
+
+	// zero length chunk terminates the stream
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err = conn.Write(size); err != nil {
+		return ScanResult{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return ScanResult{}, err
+	}
+
+	return parseClamAVReply(reply)
+}
+
+// parseClamAVReply parses replies like `stream: OK` or `stream: Eicar-Signature FOUND`
+func parseClamAVReply(reply string) (ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case reply == "OK":
+		return ScanResult{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return ScanResult{
+			Infected:  true,
+			Signature: strings.TrimSuffix(reply, " FOUND"),
+		}, nil
+	default:
+		return ScanResult{}, fmt.Errorf("clamav scan failed: %s", reply)
+	}
+}
+
+// NoopScanner is used when no scanner is configured, every file is reported clean
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(context.Context, io.Reader) (ScanResult, error) {
+	return ScanResult{}, nil
+}
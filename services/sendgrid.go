@@ -0,0 +1,181 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+type sendGridLogger interface {
+	Info(args ...interface{})
+}
+
+type SendGridConfig struct {
+	// baseURL of the api, other providers compatible with sendgrid v3 mail send api can be used
+	baseURL string
+	apiKey  string
+	timeout time.Duration
+	logger  sendGridLogger
+}
+
+// SendGridSender sends emails through sendgrid v3 mail send api
+type SendGridSender struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+// NewSendGridSender creates http api email sender
+func NewSendGridSender(sendGridConfig SendGridConfig) SendGridSender {
+	if sendGridConfig.baseURL == "" {
+		sendGridConfig.baseURL = "https://api.sendgrid.com"
+	}
+	if sendGridConfig.timeout == 0 {
+		sendGridConfig.timeout = 30 * time.Second
+	}
+	sendGridConfig.logger.Info("✅ SendGrid sender created.")
+	return SendGridSender{
+		client:  &http.Client{Timeout: sendGridConfig.timeout},
+		baseURL: strings.TrimSuffix(sendGridConfig.baseURL, "/"),
+		apiKey:  sendGridConfig.apiKey,
+	}
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"`
+	Type        string `json:"type,omitempty"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To  []sendGridAddress `json:"to,omitempty"`
+	Cc  []sendGridAddress `json:"cc,omitempty"`
+	Bcc []sendGridAddress `json:"bcc,omitempty"`
+}
+
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyToList      []sendGridAddress         `json:"reply_to_list,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Attachments      []sendGridAttachment      `json:"attachments,omitempty"`
+}
+
+// Send sends the email as json, the provider builds the MIME message itself and only accepts utf-8
+func (s SendGridSender) Send(ctx context.Context, params EmailParams) error {
+	if len(params.recipients()) == 0 {
+		return emailParamsError(Providers.SendGrid, errors.New("email has no recipients"))
+	}
+	if err := params.validateHeaders(); err != nil {
+		return emailParamsError(Providers.SendGrid, err)
+	}
+
+	message := sendGridMail{
+		Personalizations: []sendGridPersonalization{
+			{
+				To:  sendGridAddresses(params.To),
+				Cc:  sendGridAddresses(params.Cc),
+				Bcc: sendGridAddresses(params.Bcc),
+			},
+		},
+		From:        sendGridAddress{Email: params.From.Address, Name: params.From.Name},
+		ReplyToList: sendGridAddresses(params.ReplyTo),
+		Subject:     params.Subject,
+	}
+	// text/plain must come before text/html
+	if params.TextBody != "" {
+		message.Content = append(message.Content, sendGridContent{Type: "text/plain", Value: params.TextBody})
+	}
+	if params.HTMLBody != "" {
+		message.Content = append(message.Content, sendGridContent{Type: "text/html", Value: params.HTMLBody})
+	}
+	for _, attachment := range params.Attachments {
+		disposition := "attachment"
+		if attachment.inline() {
+			disposition = "inline"
+		}
+		message.Attachments = append(
+			message.Attachments, sendGridAttachment{
+				Content:     base64.StdEncoding.EncodeToString(attachment.Content),
+				Type:        attachment.ContentType,
+				Filename:    attachment.FileName,
+				Disposition: disposition,
+				ContentID:   attachment.ContentID,
+			},
+		)
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/v3/mail/send", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return NewError(Providers.SendGrid, 0, "", "Failed to send email", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	result := struct {
+		Errors []struct {
+			Message string `json:"message"`
+			Field   string `json:"field"`
+		} `json:"errors"`
+	}{}
+	responseBody, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	_ = json.Unmarshal(responseBody, &result)
+
+	messages := make([]string, 0, len(result.Errors))
+	for _, e := range result.Errors {
+		messages = append(messages, e.Message)
+	}
+	return NewError(
+		Providers.SendGrid,
+		res.StatusCode,
+		"",
+		"Failed to send email",
+		errors.New(strings.Join(messages, "; ")),
+	)
+}
+
+func sendGridAddresses(addresses []mail.Address) []sendGridAddress {
+	if len(addresses) == 0 {
+		return nil
+	}
+	result := make([]sendGridAddress, 0, len(addresses))
+	for _, address := range addresses {
+		result = append(result, sendGridAddress{Email: address.Address, Name: address.Name})
+	}
+	return result
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"boilerplate-api/lib/constants"
+
+	"cloud.google.com/go/storage"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
+)
+
+const (
+	// signedURLRefreshMargin cached urls are regenerated this long before they expire
+	signedURLRefreshMargin = time.Minute
+
+	// signedURLCacheSize cache is swept for expired entries once it grows past this size
+	signedURLCacheSize = 10000
+)
+
+// ErrSigningNotConfigured returned when signing credentials are not available
+var ErrSigningNotConfigured = errors.New("signed url credentials are not configured")
+
+// Disposition Content-Disposition type of the signed url response
+type Disposition string
+
+var Dispositions = struct {
+	Inline     Disposition
+	Attachment Disposition
+}{
+	Inline:     "inline",
+	Attachment: "attachment",
+}
+
+// SignedURLOptions options for the signed url
+type SignedURLOptions struct {
+	Storage constants.Storage
+	// Expiry defaults to SIGNED_URL_EXPIRY
+	Expiry time.Duration
+	// Disposition sets the response Content-Disposition, optional
+	Disposition Disposition
+	// FileName used as Content-Disposition filename, optional
+	FileName string
+}
+
+func (o SignedURLOptions) contentDisposition() string {
+	if o.Disposition == "" {
+		return ""
+	}
+	if o.FileName == "" {
+		return string(o.Disposition)
+	}
+	return mime.FormatMediaType(string(o.Disposition), map[string]string{"filename": o.FileName})
+}
+
+// S3Presigner presigns s3 object urls, implemented by aws.S3BucketService
+type S3Presigner interface {
+	PresignGetObject(ctx context.Context, key string, expiry time.Duration, disposition string) (string, error)
+}
+
+type suLogger interface {
+	Info(args ...interface{})
+	Error(args ...interface{})
+}
+
+type SignedURLConfig struct {
+	gcsBucket          string
+	serviceAccountPath string
+	expiry             time.Duration
+	s3Bucket           S3Presigner
+	logger             suLogger
+}
+
+// SignedURLService signs GCS and S3 object urls and caches them until shortly before expiry
+type SignedURLService struct {
+	logger        suLogger
+	gcsBucket     string
+	defaultExpiry time.Duration
+	s3Bucket      S3Presigner
+	credentials   func() (*jwt.Config, error)
+	cache         *signedURLCache
+}
+
+// NewSignedURLService creates new signed url service
+//
+// service account key is read on first use and cached once it loads
+func NewSignedURLService(signedURLConfig SignedURLConfig) SignedURLService {
+	expiry := signedURLConfig.expiry
+	if expiry == 0 {
+		expiry = 15 * time.Minute
+	}
+
+	credentials := &signingCredentials{
+		path:   signedURLConfig.serviceAccountPath,
+		logger: signedURLConfig.logger,
+	}
+
+	signedURLConfig.logger.Info("✅ Signed url service created.")
+	return SignedURLService{
+		logger:        signedURLConfig.logger,
+		gcsBucket:     signedURLConfig.gcsBucket,
+		defaultExpiry: expiry,
+		s3Bucket:      signedURLConfig.s3Bucket,
+		credentials:   credentials.load,
+		cache:         newSignedURLCache(),
+	}
+}
+
+// Sign signs the object url, cached url is returned if it is still valid
+func (s SignedURLService) Sign(ctx context.Context, object string, opts SignedURLOptions) (string, error) {
+	if opts.Expiry == 0 {
+		opts.Expiry = s.defaultExpiry
+	}
+	if opts.Storage == "" {
+		opts.Storage = constants.Storages.GCS
+	}
+
+	key := fmt.Sprintf("%s|%s|%s|%s", opts.Storage, object, opts.Expiry, opts.contentDisposition())
+	if signedURL, ok := s.cache.get(key); ok {
+		return signedURL, nil
+	}
+
+	expiresAt := time.Now().Add(opts.Expiry)
+
+	var signedURL string
+	var err error
+	switch opts.Storage {
+	case constants.Storages.S3:
+		signedURL, err = s.s3Bucket.PresignGetObject(ctx, object, opts.Expiry, opts.contentDisposition())
+	case constants.Storages.GCS:
+		signedURL, err = s.signGCS(object, expiresAt, opts)
+	default:
+		err = fmt.Errorf("unsupported storage: %s", opts.Storage)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	s.cache.set(key, signedURL, expiresAt)
+	return signedURL, nil
+}
+
+// SignBatch signs urls of all objects with same options, used for list responses
+//
+// returns map of object => signed url, empty objects are skipped
+func (s SignedURLService) SignBatch(
+	ctx context.Context,
+	objects []string,
+	opts SignedURLOptions,
+) (map[string]string, error) {
+	signedURLs := make(map[string]string, len(objects))
+	for _, object := range objects {
+		if object == "" {
+			continue
+		}
+		if _, ok := signedURLs[object]; ok {
+			continue
+		}
+
+		signedURL, err := s.Sign(ctx, object, opts)
+		if err != nil {
+			return nil, err
+		}
+		signedURLs[object] = signedURL
+	}
+	return signedURLs, nil
+}
+
+func (s SignedURLService) signGCS(object string, expiresAt time.Time, opts SignedURLOptions) (string, error) {
+	conf, err := s.credentials()
+	if err != nil {
+		return "", err
+	}
+
+	signOptions := &storage.SignedURLOptions{
+		Scheme:         storage.SigningSchemeV4,
+		Method:         "GET",
+		GoogleAccessID: conf.Email,
+		PrivateKey:     conf.PrivateKey,
+		Expires:        expiresAt,
+	}
+	if disposition := opts.contentDisposition(); disposition != "" {
+		signOptions.QueryParameters = url.Values{"response-content-disposition": {disposition}}
+	}
+
+	return storage.SignedURL(s.gcsBucket, object, signOptions)
+}
+
+// signingCredentials service account key of the gcs signer, a failed read is retried on the next use
+type signingCredentials struct {
+	mu     sync.RWMutex
+	path   string
+	logger suLogger
+	conf   *jwt.Config
+}
+
+func (c *signingCredentials) load() (*jwt.Config, error) {
+	c.mu.RLock()
+	conf := c.conf
+	c.mu.RUnlock()
+	if conf != nil {
+		return conf, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conf != nil {
+		return c.conf, nil
+	}
+
+	jsonKey, err := os.ReadFile(c.path)
+	if err != nil {
+		c.logger.Error("Unable to read service account key: ", err.Error())
+		return nil, fmt.Errorf("%w: %w", ErrSigningNotConfigured, err)
+	}
+	conf, err = google.JWTConfigFromJSON(jsonKey)
+	if err != nil {
+		c.logger.Error("Unable to parse service account key: ", err.Error())
+		return nil, fmt.Errorf("%w: %w", ErrSigningNotConfigured, err)
+	}
+	c.conf = conf
+	return conf, nil
+}
+
+type signedURLCacheEntry struct {
+	url       string
+	expiresAt time.Time
+}
+
+// signedURLCache in memory cache of signed urls
+type signedURLCache struct {
+	mu      sync.RWMutex
+	entries map[string]signedURLCacheEntry
+}
+
+func newSignedURLCache() *signedURLCache {
+	return &signedURLCache{entries: map[string]signedURLCacheEntry{}}
+}
+
+func (c *signedURLCache) get(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().Add(signedURLRefreshMargin).After(entry.expiresAt) {
+		return "", false
+	}
+	return entry.url, true
+}
+
+func (c *signedURLCache) set(key, signedURL string, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= signedURLCacheSize {
+		now := time.Now()
+		for k, entry := range c.entries {
+			if now.Add(signedURLRefreshMargin).After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= signedURLCacheSize {
+			c.entries = map[string]signedURLCacheEntry{}
+		}
+	}
+	c.entries[key] = signedURLCacheEntry{url: signedURL, expiresAt: expiresAt}
+}
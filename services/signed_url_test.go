@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"boilerplate-api/lib/config"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2/jwt"
+)
+
+func newTestPrivateKey(t *testing.T) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	return pem.EncodeToMemory(
+		&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		},
+	)
+}
+
+func newTestSignedURLService(t *testing.T) SignedURLService {
+	privateKey := newTestPrivateKey(t)
+
+	service := NewSignedURLService(
+		SignedURLConfig{
+			gcsBucket:          "test-bucket",
+			serviceAccountPath: "",
+			logger:             config.GetLogger().SugaredLogger,
+		},
+	)
+	service.credentials = func() (*jwt.Config, error) {
+		return &jwt.Config{Email: "signer@test.iam.gserviceaccount.com", PrivateKey: privateKey}, nil
+	}
+	return service
+}
+
+func TestSignedURLService(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run(
+		"caches signed url per object and options", func(t *testing.T) {
+			service := newTestSignedURLService(t)
+
+			first, err := service.Sign(ctx, "images/original/1.png", SignedURLOptions{})
+			assert.NoError(t, err)
+
+			// generated urls embed the signing time, identical url means it came from cache
+			time.Sleep(1100 * time.Millisecond)
+			second, err := service.Sign(ctx, "images/original/1.png", SignedURLOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, first, second)
+
+			other, err := service.Sign(ctx, "images/original/1.png", SignedURLOptions{Expiry: time.Hour})
+			assert.NoError(t, err)
+			assert.NotEqual(t, first, other)
+		},
+	)
+
+	t.Run(
+		"sets expiry and content disposition", func(t *testing.T) {
+			service := newTestSignedURLService(t)
+
+			signedURL, err := service.Sign(
+				ctx, "files/report.pdf", SignedURLOptions{
+					Expiry:      2 * time.Hour,
+					Disposition: Dispositions.Attachment,
+					FileName:    "report.pdf",
+				},
+			)
+			assert.NoError(t, err)
+
+			parsed, err := url.Parse(signedURL)
+			assert.NoError(t, err)
+			assert.Contains(t, []string{"7199", "7200"}, parsed.Query().Get("X-Goog-Expires"))
+			assert.Equal(t, `attachment; filename=report.pdf`, parsed.Query().Get("response-content-disposition"))
+		},
+	)
+
+	t.Run(
+		"signs batches skipping duplicates and empty paths", func(t *testing.T) {
+			service := newTestSignedURLService(t)
+
+			signedURLs, err := service.SignBatch(ctx, []string{"a.png", "", "b.png", "a.png"}, SignedURLOptions{})
+			assert.NoError(t, err)
+			assert.Len(t, signedURLs, 2)
+			assert.Contains(t, signedURLs, "a.png")
+			assert.Contains(t, signedURLs, "b.png")
+		},
+	)
+
+	t.Run(
+		"returns error when service account key is missing and retries it", func(t *testing.T) {
+			keyPath := filepath.Join(t.TempDir(), "service-account.json")
+			service := NewSignedURLService(
+				SignedURLConfig{
+					gcsBucket:          "test-bucket",
+					serviceAccountPath: keyPath,
+					logger:             config.GetLogger().SugaredLogger,
+				},
+			)
+
+			signedURL, err := service.Sign(ctx, "a.png", SignedURLOptions{})
+			assert.ErrorIs(t, err, ErrSigningNotConfigured)
+			assert.Empty(t, signedURL)
+
+			// the key mounted after the failure is picked up without a restart
+			jsonKey, err := json.Marshal(
+				map[string]string{
+					"type":         "service_account",
+					"client_email": "signer@test.iam.gserviceaccount.com",
+					"private_key":  string(newTestPrivateKey(t)),
+				},
+			)
+			assert.NoError(t, err)
+			assert.NoError(t, os.WriteFile(keyPath, jsonKey, 0o600))
+
+			signedURL, err = service.Sign(ctx, "a.png", SignedURLOptions{})
+			assert.NoError(t, err)
+			assert.Contains(t, signedURL, "signer%40test.iam.gserviceaccount.com")
+		},
+	)
+}
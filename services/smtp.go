@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"boilerplate-api/lib/constants"
+)
+
+type smtpLogger interface {
+	Info(args ...interface{})
+}
+
+type SMTPConfig struct {
+	host       string
+	port       int
+	username   string
+	password   string
+	encryption constants.SMTPEncryption
+	timeout    time.Duration
+	logger     smtpLogger
+}
+
+// SMTPSender sends emails through smtp server, authenticates when username is set
+type SMTPSender struct {
+	host       string
+	port       int
+	username   string
+	password   string
+	encryption constants.SMTPEncryption
+	timeout    time.Duration
+	// rootCAs overrides the system roots verifying the server certificate, used in tests
+	rootCAs *x509.CertPool
+}
+
+// NewSMTPSender creates smtp sender, connections are opened per email
+func NewSMTPSender(smtpConfig SMTPConfig) SMTPSender {
+	if smtpConfig.encryption == "" {
+		smtpConfig.encryption = constants.SMTPEncryptions.StartTLS
+	}
+	if smtpConfig.timeout == 0 {
+		smtpConfig.timeout = 30 * time.Second
+	}
+	smtpConfig.logger.Info("✅ SMTP sender created.")
+	return SMTPSender{
+		host:       smtpConfig.host,
+		port:       smtpConfig.port,
+		username:   smtpConfig.username,
+		password:   smtpConfig.password,
+		encryption: smtpConfig.encryption,
+		timeout:    smtpConfig.timeout,
+	}
+}
+
+// Send sends the email, envelope sender is From or the smtp username
+func (s SMTPSender) Send(ctx context.Context, params EmailParams) error {
+	msg, err := params.compose(false)
+	if err != nil {
+		return emailParamsError(Providers.SMTP, err)
+	}
+
+	from := params.From.Address
+	if from == "" {
+		from = s.username
+	}
+	if err := s.send(ctx, from, params.recipients(), msg); err != nil {
+		return smtpError(err)
+	}
+	return nil
+}
+
+func (s SMTPSender) send(ctx context.Context, from string, to []string, msg []byte) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(s.timeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if s.encryption == constants.SMTPEncryptions.StartTLS {
+		if err := client.StartTLS(s.tlsConfig()); err != nil {
+			return err
+		}
+	}
+	if s.username != "" {
+		// PlainAuth refuses to send the password over plain connection except to localhost
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(msg); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (s SMTPSender) dial(ctx context.Context) (net.Conn, error) {
+	address := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	dialer := &net.Dialer{Timeout: s.timeout}
+	if s.encryption == constants.SMTPEncryptions.TLS {
+		tlsDialer := &tls.Dialer{
+			NetDialer: dialer,
+			Config:    s.tlsConfig(),
+		}
+		return tlsDialer.DialContext(ctx, "tcp", address)
+	}
+	return dialer.DialContext(ctx, "tcp", address)
+}
+
+func (s SMTPSender) tlsConfig() *tls.Config {
+	return &tls.Config{ServerName: s.host, RootCAs: s.rootCAs}
+}
+
+// smtpError 4xx replies are transient and can be retried, 5xx replies are permanent
+func smtpError(err error) error {
+	var protoErr *textproto.Error
+	if !errors.As(err, &protoErr) {
+		return NewError(Providers.SMTP, 0, "", "Failed to send email", err)
+	}
+
+	serviceErr := NewError(Providers.SMTP, 0, strconv.Itoa(protoErr.Code), "Failed to send email", err)
+	serviceErr.Retryable = protoErr.Code >= 400 && protoErr.Code < 500
+	return serviceErr
+}
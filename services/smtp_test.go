@@ -0,0 +1,227 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"boilerplate-api/lib/config"
+	"boilerplate-api/lib/constants"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// smtpTestServer in process smtp server accepting one login, it records the mail it receives
+type smtpTestServer struct {
+	listener    net.Listener
+	tlsConfig   *tls.Config
+	implicitTLS bool
+
+	mu sync.Mutex
+	// authTLS whether AUTH was sent over tls
+	authTLS bool
+	from    string
+	to      []string
+	data    string
+}
+
+func newSMTPTestServer(t *testing.T, implicitTLS bool) (*smtpTestServer, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(certificate)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	server := &smtpTestServer{
+		listener:    listener,
+		tlsConfig:   &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}},
+		implicitTLS: implicitTLS,
+	}
+	go server.serve()
+	return server, roots
+}
+
+func (s *smtpTestServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpTestServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpTestServer) handle(conn net.Conn) {
+	if s.implicitTLS {
+		conn = tls.Server(conn, s.tlsConfig)
+	}
+	defer func() { _ = conn.Close() }()
+
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("220 127.0.0.1 ESMTP test")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		_, secure := conn.(*tls.Conn)
+
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			if !secure {
+				_ = text.PrintfLine("250-127.0.0.1\r\n250-STARTTLS\r\n250 AUTH PLAIN")
+			} else {
+				_ = text.PrintfLine("250-127.0.0.1\r\n250 AUTH PLAIN")
+			}
+		case "STARTTLS":
+			_ = text.PrintfLine("220 ready to start tls")
+			conn = tls.Server(conn, s.tlsConfig)
+			text = textproto.NewConn(conn)
+		case "AUTH":
+			credentials, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(arg, "PLAIN "))
+			if string(credentials) != "\x00mailer\x00secret" {
+				_ = text.PrintfLine("535 authentication failed")
+				continue
+			}
+			s.mu.Lock()
+			s.authTLS = secure
+			s.mu.Unlock()
+			_ = text.PrintfLine("235 authenticated")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = arg
+			s.mu.Unlock()
+			_ = text.PrintfLine("250 ok")
+		case "RCPT":
+			if strings.Contains(arg, "busy@") {
+				_ = text.PrintfLine("451 mailbox busy")
+				continue
+			}
+			s.mu.Lock()
+			s.to = append(s.to, arg)
+			s.mu.Unlock()
+			_ = text.PrintfLine("250 ok")
+		case "DATA":
+			_ = text.PrintfLine("354 end with .")
+			data, _ := text.ReadDotBytes()
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			_ = text.PrintfLine("250 queued")
+		case "QUIT":
+			_ = text.PrintfLine("221 bye")
+			return
+		default:
+			_ = text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	params := EmailParams{
+		From:     mail.Address{Address: "noreply@example.com"},
+		To:       []mail.Address{{Address: "taro@example.com"}},
+		Bcc:      []mail.Address{{Address: "audit@example.com"}},
+		Subject:  "Hello",
+		TextBody: "Hello",
+	}
+
+	for _, encryption := range []constants.SMTPEncryption{constants.SMTPEncryptions.StartTLS, constants.SMTPEncryptions.TLS} {
+		t.Run(
+			string(encryption), func(t *testing.T) {
+				server, roots := newSMTPTestServer(t, encryption == constants.SMTPEncryptions.TLS)
+				newSender := func(password string) SMTPSender {
+					sender := NewSMTPSender(
+						SMTPConfig{
+							host:       "127.0.0.1",
+							port:       server.port(),
+							username:   "mailer",
+							password:   password,
+							encryption: encryption,
+							timeout:    5 * time.Second,
+							logger:     config.GetLogger().SugaredLogger,
+						},
+					)
+					sender.rootCAs = roots
+					return sender
+				}
+
+				assert.NoError(t, newSender("secret").Send(context.Background(), params))
+
+				server.mu.Lock()
+				assert.True(t, server.authTLS, "credentials are only sent over tls")
+				assert.Equal(t, "FROM:<noreply@example.com>", server.from)
+				assert.Equal(t, []string{"TO:<taro@example.com>", "TO:<audit@example.com>"}, server.to)
+				message, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(server.data)))
+				server.mu.Unlock()
+				if assert.NoError(t, err) {
+					assert.Equal(t, "Hello", message.Header.Get("Subject"))
+					assert.Empty(t, message.Header.Get("Bcc"))
+				}
+
+				err = newSender("wrong").Send(context.Background(), params)
+				assert.Error(t, err)
+				assert.False(t, IsRetryable(err), "rejected login is permanent")
+			},
+		)
+	}
+
+	t.Run(
+		"transient reply is retryable", func(t *testing.T) {
+			server, roots := newSMTPTestServer(t, false)
+			sender := NewSMTPSender(
+				SMTPConfig{
+					host:   "127.0.0.1",
+					port:   server.port(),
+					logger: config.GetLogger().SugaredLogger,
+				},
+			)
+			sender.rootCAs = roots
+
+			err := sender.Send(
+				context.Background(), EmailParams{
+					To:       []mail.Address{{Address: "busy@example.com"}},
+					TextBody: "Hello",
+				},
+			)
+			assert.True(t, IsRetryable(err))
+		},
+	)
+}
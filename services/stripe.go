@@ -1,9 +1,13 @@
 package services
 
 import (
+	"context"
+	"errors"
+
+	"boilerplate-api/lib/api_errors"
+
 	"github.com/stripe/stripe-go/v76"
 	"github.com/stripe/stripe-go/v76/client"
-	"net/http"
 )
 
 type sLogger interface {
@@ -11,179 +15,220 @@ type sLogger interface {
 }
 
 type StripeConfig struct {
-	stripeSecretKey string
-	stripeProductID string
-	logger          sLogger
+	stripeSecretKey   string
+	stripeProductID   string
+	stripeWebhookKey  string
+	stripeRedirectURL string
+	// backends overrides stripe api backends, used in tests
+	backends *stripe.Backends
+	logger   sLogger
 }
 
-type CustomerSubscription struct {
-	StripePriceID    string
-	StripeCustomerID string
+// NewStripeTestConfig config calling the backends instead of the stripe api, used in tests of other packages
+func NewStripeTestConfig(productID string, backends *stripe.Backends, logger sLogger) StripeConfig {
+	return StripeConfig{
+		stripeSecretKey: "sk_test",
+		stripeProductID: productID,
+		backends:        backends,
+		logger:          logger,
+	}
 }
 
 type StripeService struct {
 	*client.API
-	stripeProductID string
-}
-
-// StripeErrorResponse struct
-type StripeErrorResponse struct {
-	Message   string `json:"message"`
-	ErrorType int    `json:"error_type"`
+	stripeProductID   string
+	stripeWebhookKey  string
+	stripeRedirectURL string
 }
 
 func NewStripeService(
 	stripeConfig StripeConfig,
 ) StripeService {
 	_client := &client.API{}
-	_client.Init(stripeConfig.stripeSecretKey, nil)
+	_client.Init(stripeConfig.stripeSecretKey, stripeConfig.backends)
 	stripeConfig.logger.Info("✅ Stripe client created.")
 	return StripeService{
-		API:             _client,
-		stripeProductID: stripeConfig.stripeProductID,
+		API:               _client,
+		stripeProductID:   stripeConfig.stripeProductID,
+		stripeWebhookKey:  stripeConfig.stripeWebhookKey,
+		stripeRedirectURL: stripeConfig.stripeRedirectURL,
 	}
 }
 
-func (service StripeService) CreateCustomer(name, email string) (*stripe.Customer, error) {
-	stripeCustomer, err := service.Customers.New(&stripe.CustomerParams{
-		Name:  &name,
-		Email: &email,
-	})
-
+// CreateCustomer creates customer, retries with the same idempotency key return the same customer
+func (service StripeService) CreateCustomer(
+	ctx context.Context,
+	name, email string,
+	idempotencyKey string,
+) (*stripe.Customer, error) {
+	params := &stripe.CustomerParams{
+		Params: stripe.Params{Context: ctx},
+		Name:   &name,
+		Email:  &email,
+	}
+	if idempotencyKey != "" {
+		params.SetIdempotencyKey(idempotencyKey)
+	}
+	stripeCustomer, err := service.Customers.New(params)
 	if err != nil {
-		return nil, err
+		return nil, stripeError(err, "Error while creating customer")
 	}
-	return stripeCustomer, err
+	return stripeCustomer, nil
 }
 
+// CreateSubscription creates subscription waiting for the first payment,
+// expands latest invoice payment intent and pending setup intent for the client secret
 func (service StripeService) CreateSubscription(
-	customer CustomerSubscription, // FIXME
-	backdateStartDate *int64,
-) (subs *stripe.Subscription, err error) {
-
-	itemsParams := []*stripe.SubscriptionItemsParams{
-		{
-			Price:    &customer.StripePriceID,
-			Quantity: stripe.Int64(1),
-		},
-	}
-
-	paymentSettings := stripe.SubscriptionPaymentSettingsParams{
-		SaveDefaultPaymentMethod: stripe.String("on_subscription"),
-	}
-	subscriptionParams := stripe.SubscriptionParams{
-		Customer:        &customer.StripeCustomerID,
-		Items:           itemsParams,
-		PaymentSettings: &paymentSettings,
-		PaymentBehavior: stripe.String("default_incomplete"),
-		BillingCycleAnchorConfig: &stripe.SubscriptionBillingCycleAnchorConfigParams{
-			DayOfMonth: stripe.Int64(1),
-		},
-		BackdateStartDate: backdateStartDate,
-	}
+	ctx context.Context,
+	opts SubscriptionOptions,
+) (*stripe.Subscription, error) {
+	subscriptionParams := opts.params()
+	subscriptionParams.Context = ctx
 	subscriptionParams.AddExpand("latest_invoice.payment_intent")
 	subscriptionParams.AddExpand("pending_setup_intent")
-	subs, err = service.Subscriptions.New(&subscriptionParams)
+	subscription, err := service.Subscriptions.New(subscriptionParams)
 	if err != nil {
-		return nil, err
+		return nil, stripeError(err, "Error while creating subscription")
 	}
-	return subs, err
+	return subscription, nil
+}
+
+// GetSubscription gets current state of the subscription with its price
+func (service StripeService) GetSubscription(
+	ctx context.Context,
+	stripeSubscriptionID string,
+) (*stripe.Subscription, error) {
+	params := &stripe.SubscriptionParams{
+		Params: stripe.Params{Context: ctx},
+	}
+	params.AddExpand("items.data.price")
+	subscription, err := service.Subscriptions.Get(stripeSubscriptionID, params)
+	if err != nil {
+		return nil, stripeError(err, "Error while getting subscription")
+	}
+	return subscription, nil
 }
 
 func (service StripeService) UpdateSubscription(
+	ctx context.Context,
 	stripeSubscriptionID string,
 	stripeParams *stripe.SubscriptionParams,
-) *StripeErrorResponse {
-	_, err := service.Subscriptions.Update(stripeSubscriptionID, stripeParams)
-	if err != nil {
-		return &StripeErrorResponse{
-			Message:   "Errors while updating subscription",
-			ErrorType: http.StatusInternalServerError,
-		}
+) error {
+	stripeParams.Context = ctx
+	if _, err := service.Subscriptions.Update(stripeSubscriptionID, stripeParams); err != nil {
+		return stripeError(err, "Errors while updating subscription")
 	}
 	return nil
 }
 
 func (service StripeService) CancelSubscription(
+	ctx context.Context,
 	stripeSubscriptionID string,
 	stripeParams *stripe.SubscriptionCancelParams,
-) *StripeErrorResponse {
-	_, err := service.Subscriptions.Cancel(stripeSubscriptionID, stripeParams)
-	if err != nil {
-		return &StripeErrorResponse{
-			Message:   "Errors while canceling subscription",
-			ErrorType: http.StatusInternalServerError,
-		}
+) error {
+	stripeParams.Context = ctx
+	if _, err := service.Subscriptions.Cancel(stripeSubscriptionID, stripeParams); err != nil {
+		return stripeError(err, "Errors while canceling subscription")
 	}
 	return nil
 }
 
-func (service StripeService) CreatePrices(
-	title string, price int64,
-) (*stripe.Price, *StripeErrorResponse) {
-	priceParams := stripe.PriceParams{
+// ListPlans active recurring prices of the product
+func (service StripeService) ListPlans(ctx context.Context) ([]*stripe.Price, error) {
+	params := &stripe.PriceListParams{
+		ListParams: stripe.ListParams{Context: ctx},
 		Product:    stripe.String(service.stripeProductID),
-		Currency:   stripe.String(string(stripe.CurrencyJPY)),
-		Nickname:   stripe.String(title),
-		UnitAmount: stripe.Int64(price),
-		Recurring: &stripe.PriceRecurringParams{
-			Interval: stripe.String(string(stripe.PriceRecurringIntervalMonth)),
-		},
-	}
-	prices, err := service.Prices.New(&priceParams)
-	if err != nil {
-		return nil, &StripeErrorResponse{
-			ErrorType: http.StatusInternalServerError,
-			Message:   "Error while creating price",
-		}
+		Active:     stripe.Bool(true),
+		Type:       stripe.String(string(stripe.PriceTypeRecurring)),
+	}
+
+	var prices []*stripe.Price
+	iter := service.Prices.List(params)
+	for iter.Next() {
+		prices = append(prices, iter.Price())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, stripeError(err, "Error while listing prices")
 	}
 	return prices, nil
+}
 
+// CreatePrices creates recurring price of the product
+func (service StripeService) CreatePrices(ctx context.Context, opts PriceOptions) (*stripe.Price, error) {
+	params := opts.params(service.stripeProductID)
+	params.Context = ctx
+	prices, err := service.Prices.New(params)
+	if err != nil {
+		return nil, stripeError(err, "Error while creating price")
+	}
+	return prices, nil
 }
 
 func (service StripeService) UpdatePrices(
+	ctx context.Context,
 	stripePriceID string,
 	priceParams *stripe.PriceParams,
-) (prices *stripe.Price, errResponse *StripeErrorResponse) {
+) (*stripe.Price, error) {
+	priceParams.Context = ctx
 	prices, err := service.Prices.Update(
 		stripePriceID,
 		priceParams,
 	)
 	if err != nil {
-		return nil, &StripeErrorResponse{
-			ErrorType: http.StatusInternalServerError,
-			Message:   "Error while updating price",
-		}
+		return nil, stripeError(err, "Error while updating price")
 	}
 	return prices, nil
-
 }
 
 func (service StripeService) CreatePaymentIntent(
+	ctx context.Context,
 	paymentParams *stripe.PaymentIntentParams,
-) (payment *stripe.PaymentIntent, errResponse *StripeErrorResponse) {
+) (*stripe.PaymentIntent, error) {
+	paymentParams.Context = ctx
 	paymentMethod := stripe.PaymentIntentAutomaticPaymentMethodsParams{
 		Enabled: stripe.Bool(true),
 	}
 	paymentParams.AutomaticPaymentMethods = &paymentMethod
 	payment, err := service.PaymentIntents.New(paymentParams)
 	if err != nil {
-		return nil, &StripeErrorResponse{
-			ErrorType: http.StatusInternalServerError,
-			Message:   "Error while creating payment intent",
-		}
+		return nil, stripeError(err, "Error while creating payment intent")
 	}
 	return payment, nil
-
 }
 
-func (service StripeService) VoidInvoice(invoiceID string) *StripeErrorResponse {
-	params := &stripe.InvoiceVoidInvoiceParams{}
+func (service StripeService) VoidInvoice(ctx context.Context, invoiceID string) error {
+	params := &stripe.InvoiceVoidInvoiceParams{
+		Params: stripe.Params{Context: ctx},
+	}
 	if _, err := service.Invoices.VoidInvoice(invoiceID, params); err != nil {
-		return &StripeErrorResponse{
-			Message:   "Error while voiding invoice",
-			ErrorType: http.StatusInternalServerError,
-		}
+		return stripeError(err, "Error while voiding invoice")
 	}
 	return nil
 }
+
+// stripeError wraps stripe api error, card errors keep the message stripe localizes for customers
+func stripeError(err error, message string) error {
+	var apiErr *stripe.Error
+	if !errors.As(err, &apiErr) {
+		return NewError(Providers.Stripe, 0, "", message, err)
+	}
+
+	code := string(apiErr.Code)
+	if apiErr.DeclineCode != "" {
+		code = string(apiErr.DeclineCode)
+	}
+	serviceErr := NewError(Providers.Stripe, apiErr.HTTPStatusCode, code, message, err)
+
+	switch apiErr.Type {
+	case stripe.ErrorTypeCard:
+		serviceErr.HTTPStatus = api_errors.PaymentRequired
+		serviceErr.Message = apiErr.Msg
+	case stripe.ErrorTypeIdempotency:
+		serviceErr.HTTPStatus = api_errors.Conflict
+		serviceErr.Message = "Idempotency key was already used for another request"
+	}
+	if apiErr.Code == stripe.ErrorCodeLockTimeout {
+		serviceErr.Retryable = true
+	}
+	return serviceErr
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/stripe/stripe-go/v76"
+)
+
+// stripeMetadataUserID metadata key of our user id on stripe objects
+const stripeMetadataUserID = "user_id"
+
+// CheckoutSessionOptions options of the hosted checkout page
+type CheckoutSessionOptions struct {
+	// Mode subscription or payment
+	Mode       stripe.CheckoutSessionMode
+	CustomerID string
+	PriceID    string
+	Quantity   int64
+	// UserID is attached as client reference and metadata of the session and created objects
+	UserID string
+	// AllowPromotionCodes shows promotion code input on the checkout page
+	AllowPromotionCodes bool
+	IdempotencyKey      string
+}
+
+// GetPrice gets price with its product id
+func (service StripeService) GetPrice(ctx context.Context, stripePriceID string) (*stripe.Price, error) {
+	price, err := service.Prices.Get(
+		stripePriceID, &stripe.PriceParams{
+			Params: stripe.Params{Context: ctx},
+		},
+	)
+	if err != nil {
+		return nil, stripeError(err, "Error while getting price")
+	}
+	return price, nil
+}
+
+// IsProductPrice price is active and belongs to the product
+func (service StripeService) IsProductPrice(price *stripe.Price) bool {
+	return price.Active && price.Product != nil && price.Product.ID == service.stripeProductID
+}
+
+// CreateCheckoutSession creates hosted checkout page, user is redirected back to STRIPE_REDIRECT_URL
+//
+// success url gets `status=success&session_id=...`, cancel url gets `status=canceled`
+func (service StripeService) CreateCheckoutSession(
+	ctx context.Context,
+	opts CheckoutSessionOptions,
+) (*stripe.CheckoutSession, error) {
+	quantity := opts.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	params := &stripe.CheckoutSessionParams{
+		Params:            stripe.Params{Context: ctx},
+		Mode:              stripe.String(string(opts.Mode)),
+		Customer:          stripe.String(opts.CustomerID),
+		ClientReferenceID: stripe.String(opts.UserID),
+		LineItems: []*stripe.CheckoutSessionLineItemParams{
+			{
+				Price:    stripe.String(opts.PriceID),
+				Quantity: stripe.Int64(quantity),
+			},
+		},
+		// stripe replaces {CHECKOUT_SESSION_ID} so it must not be url encoded
+		SuccessURL: stripe.String(service.redirectURL("status=success&session_id={CHECKOUT_SESSION_ID}")),
+		CancelURL:  stripe.String(service.redirectURL("status=canceled")),
+	}
+	params.AddMetadata(stripeMetadataUserID, opts.UserID)
+	if opts.AllowPromotionCodes {
+		params.AllowPromotionCodes = stripe.Bool(true)
+	}
+
+	switch opts.Mode {
+	case stripe.CheckoutSessionModeSubscription:
+		params.SubscriptionData = &stripe.CheckoutSessionSubscriptionDataParams{}
+		params.SubscriptionData.AddMetadata(stripeMetadataUserID, opts.UserID)
+	case stripe.CheckoutSessionModePayment:
+		params.PaymentIntentData = &stripe.CheckoutSessionPaymentIntentDataParams{}
+		params.PaymentIntentData.AddMetadata(stripeMetadataUserID, opts.UserID)
+	}
+
+	if opts.IdempotencyKey != "" {
+		params.SetIdempotencyKey(opts.IdempotencyKey)
+	}
+
+	session, err := service.CheckoutSessions.New(params)
+	if err != nil {
+		return nil, stripeError(err, "Error while creating checkout session")
+	}
+	return session, nil
+}
+
+// CreatePortalSession creates billing portal session of the customer, returns to STRIPE_REDIRECT_URL
+func (service StripeService) CreatePortalSession(
+	ctx context.Context,
+	stripeCustomerID string,
+	idempotencyKey string,
+) (*stripe.BillingPortalSession, error) {
+	params := &stripe.BillingPortalSessionParams{
+		Params:    stripe.Params{Context: ctx},
+		Customer:  stripe.String(stripeCustomerID),
+		ReturnURL: stripe.String(service.stripeRedirectURL),
+	}
+	if idempotencyKey != "" {
+		params.SetIdempotencyKey(idempotencyKey)
+	}
+
+	session, err := service.BillingPortalSessions.New(params)
+	if err != nil {
+		return nil, stripeError(err, "Error while creating portal session")
+	}
+	return session, nil
+}
+
+func (service StripeService) redirectURL(query string) string {
+	if strings.Contains(service.stripeRedirectURL, "?") {
+		return service.stripeRedirectURL + "&" + query
+	}
+	return service.stripeRedirectURL + "?" + query
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"boilerplate-api/lib/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stripe/stripe-go/v76"
+)
+
+func TestCreateCheckoutSession(t *testing.T) {
+	backend := new(stripeBackendMock)
+	stripeService := NewStripeService(
+		StripeConfig{
+			stripeSecretKey:   "sk_test",
+			stripeRedirectURL: "https://example.com/billing?tab=plans",
+			backends:          &stripe.Backends{API: backend, Connect: backend, Uploads: backend},
+			logger:            config.GetLogger().SugaredLogger,
+		},
+	)
+
+	backend.On(
+		"Call",
+		"POST",
+		"/v1/checkout/sessions",
+		"sk_test",
+		mock.Anything,
+		mock.Anything,
+	).Run(
+		func(args mock.Arguments) {
+			params := args.Get(3).(*stripe.CheckoutSessionParams)
+			assert.Equal(t, "subscription", *params.Mode)
+			assert.Equal(t, "cus_1", *params.Customer)
+			assert.Equal(t, "42", *params.ClientReferenceID)
+			assert.Equal(t, "42", params.Metadata["user_id"])
+			assert.Equal(t, "42", params.SubscriptionData.Metadata["user_id"])
+			assert.Nil(t, params.PaymentIntentData)
+			assert.Equal(t, int64(1), *params.LineItems[0].Quantity)
+			assert.Equal(
+				t, "https://example.com/billing?tab=plans&status=success&session_id={CHECKOUT_SESSION_ID}",
+				*params.SuccessURL,
+			)
+			assert.Equal(t, "https://example.com/billing?tab=plans&status=canceled", *params.CancelURL)
+			assert.Equal(t, "checkout-1", *params.IdempotencyKey)
+
+			session := args.Get(4).(*stripe.CheckoutSession)
+			*session = stripe.CheckoutSession{ID: "cs_1", URL: "https://checkout.stripe.com/c/cs_1"}
+		},
+	).Return(nil).Once()
+
+	session, err := stripeService.CreateCheckoutSession(
+		context.Background(), CheckoutSessionOptions{
+			Mode:           stripe.CheckoutSessionModeSubscription,
+			CustomerID:     "cus_1",
+			PriceID:        "price_1",
+			UserID:         "42",
+			IdempotencyKey: "checkout-1",
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "cs_1", session.ID)
+	backend.AssertExpectations(t)
+}
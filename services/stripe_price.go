@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/stripe/stripe-go/v76"
+)
+
+// zeroDecimalCurrencies stripe amounts of these currencies are in the major unit
+//
+// https://stripe.com/docs/currencies#zero-decimal
+var zeroDecimalCurrencies = map[stripe.Currency]bool{
+	"bif": true, "clp": true, "djf": true, "gnf": true,
+	"jpy": true, "kmf": true, "krw": true, "mga": true,
+	"pyg": true, "rwf": true, "ugx": true, "vnd": true,
+	"vuv": true, "xaf": true, "xof": true, "xpf": true,
+}
+
+// threeDecimalCurrencies stripe amounts of these currencies have three decimals
+var threeDecimalCurrencies = map[stripe.Currency]bool{
+	"bhd": true, "jod": true, "kwd": true, "omr": true, "tnd": true,
+}
+
+// PriceOptions options of the recurring price
+type PriceOptions struct {
+	Title string
+	// UnitAmount in the smallest currency unit, see CurrencyDecimals
+	UnitAmount int64
+	// Currency defaults to JPY
+	Currency stripe.Currency
+	// Interval defaults to month
+	Interval stripe.PriceRecurringInterval
+	// IntervalCount bills every n intervals, defaults to 1
+	IntervalCount int64
+	// TaxBehavior whether UnitAmount includes tax, optional
+	TaxBehavior stripe.PriceTaxBehavior
+}
+
+func (o PriceOptions) params(productID string) *stripe.PriceParams {
+	currency := o.Currency
+	if currency == "" {
+		currency = stripe.CurrencyJPY
+	}
+	interval := o.Interval
+	if interval == "" {
+		interval = stripe.PriceRecurringIntervalMonth
+	}
+
+	params := &stripe.PriceParams{
+		Product:    stripe.String(productID),
+		Currency:   stripe.String(strings.ToLower(string(currency))),
+		Nickname:   stripe.String(o.Title),
+		UnitAmount: stripe.Int64(o.UnitAmount),
+		Recurring: &stripe.PriceRecurringParams{
+			Interval: stripe.String(string(interval)),
+		},
+	}
+	if o.IntervalCount > 0 {
+		params.Recurring.IntervalCount = stripe.Int64(o.IntervalCount)
+	}
+	if o.TaxBehavior != "" {
+		params.TaxBehavior = stripe.String(string(o.TaxBehavior))
+	}
+	return params
+}
+
+// SubscriptionOptions options of the new subscription
+type SubscriptionOptions struct {
+	CustomerID string
+	PriceID    string
+	// Quantity number of seats, defaults to 1
+	Quantity int64
+	// TrialDays free trial before the first invoice, optional
+	TrialDays int64
+	// BillingCycleAnchorDay bills on this day of month, defaults to the 1st
+	BillingCycleAnchorDay int64
+	// AnchorToStartDate bills from the start date instead, daily and weekly prices can't anchor on a day of month
+	AnchorToStartDate bool
+	BackdateStartDate *int64
+	// Coupon id of the coupon, optional
+	Coupon string
+	// PromotionCode id of the customer facing promotion code, optional
+	PromotionCode string
+	// AutomaticTax calculates tax with stripe tax, for prices with a tax behavior
+	AutomaticTax bool
+	// IdempotencyKey retries with the same key return the same subscription, optional
+	IdempotencyKey string
+}
+
+func (o SubscriptionOptions) params() *stripe.SubscriptionParams {
+	quantity := o.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	params := &stripe.SubscriptionParams{
+		Customer: stripe.String(o.CustomerID),
+		Items: []*stripe.SubscriptionItemsParams{
+			{
+				Price:    stripe.String(o.PriceID),
+				Quantity: stripe.Int64(quantity),
+			},
+		},
+		PaymentSettings: &stripe.SubscriptionPaymentSettingsParams{
+			SaveDefaultPaymentMethod: stripe.String("on_subscription"),
+		},
+		PaymentBehavior:   stripe.String("default_incomplete"),
+		BackdateStartDate: o.BackdateStartDate,
+	}
+	if o.TrialDays > 0 {
+		params.TrialPeriodDays = stripe.Int64(o.TrialDays)
+	}
+	if !o.AnchorToStartDate {
+		anchorDay := o.BillingCycleAnchorDay
+		if anchorDay == 0 {
+			anchorDay = 1
+		}
+		params.BillingCycleAnchorConfig = &stripe.SubscriptionBillingCycleAnchorConfigParams{
+			DayOfMonth: stripe.Int64(anchorDay),
+		}
+	}
+	if o.Coupon != "" {
+		params.Coupon = stripe.String(o.Coupon)
+	}
+	if o.PromotionCode != "" {
+		params.PromotionCode = stripe.String(o.PromotionCode)
+	}
+	if o.AutomaticTax {
+		params.AutomaticTax = &stripe.SubscriptionAutomaticTaxParams{Enabled: stripe.Bool(true)}
+	}
+	if o.IdempotencyKey != "" {
+		params.SetIdempotencyKey(o.IdempotencyKey)
+	}
+	return params
+}
+
+// FindPromotionCode active promotion code by the code customers enter, nil when there is none
+func (service StripeService) FindPromotionCode(ctx context.Context, code string) (*stripe.PromotionCode, error) {
+	params := &stripe.PromotionCodeListParams{
+		ListParams: stripe.ListParams{Context: ctx},
+		Code:       stripe.String(code),
+		Active:     stripe.Bool(true),
+	}
+	params.Limit = stripe.Int64(1)
+
+	iter := service.PromotionCodes.List(params)
+	if iter.Next() {
+		return iter.PromotionCode(), nil
+	}
+	if err := iter.Err(); err != nil {
+		return nil, stripeError(err, "Error while finding promotion code")
+	}
+	return nil, nil
+}
+
+// FindCoupon coupon by its id, nil when there is none or it can't be redeemed anymore
+func (service StripeService) FindCoupon(ctx context.Context, id string) (*stripe.Coupon, error) {
+	coupon, err := service.Coupons.Get(id, &stripe.CouponParams{Params: stripe.Params{Context: ctx}})
+	var apiErr *stripe.Error
+	if errors.As(err, &apiErr) && apiErr.Code == stripe.ErrorCodeResourceMissing {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, stripeError(err, "Error while finding coupon")
+	}
+	if !coupon.Valid {
+		return nil, nil
+	}
+	return coupon, nil
+}
+
+// CurrencyDecimals number of decimals of the currency in stripe amounts
+func CurrencyDecimals(currency stripe.Currency) int {
+	currency = stripe.Currency(strings.ToLower(string(currency)))
+	switch {
+	case zeroDecimalCurrencies[currency]:
+		return 0
+	case threeDecimalCurrencies[currency]:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// FormatAmount formats stripe amount in the smallest currency unit
+//
+//	FormatAmount(123456, "usd") => "1,234.56 USD"
+//	FormatAmount(123456, "jpy") => "123,456 JPY"
+func FormatAmount(amount int64, currency stripe.Currency) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	decimals := CurrencyDecimals(currency)
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	major, minor := digits[:len(digits)-decimals], digits[len(digits)-decimals:]
+
+	var grouped strings.Builder
+	for i, digit := range major {
+		if i > 0 && (len(major)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	if minor != "" {
+		grouped.WriteString("." + minor)
+	}
+
+	return sign + grouped.String() + " " + strings.ToUpper(string(currency))
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stripe/stripe-go/v76"
+)
+
+func TestFormatAmount(t *testing.T) {
+	cases := []struct {
+		amount   int64
+		currency stripe.Currency
+		expected string
+	}{
+		{123456, "usd", "1,234.56 USD"},
+		{5, "usd", "0.05 USD"},
+		{0, "eur", "0.00 EUR"},
+		{123456, "jpy", "123,456 JPY"},
+		{1000, "JPY", "1,000 JPY"},
+		{999, "krw", "999 KRW"},
+		{1234567, "kwd", "1,234.567 KWD"},
+		{-2500, "usd", "-25.00 USD"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, FormatAmount(c.amount, c.currency))
+	}
+}
+
+func TestPriceOptionsParams(t *testing.T) {
+	t.Run(
+		"defaults to monthly JPY", func(t *testing.T) {
+			params := PriceOptions{Title: "Basic", UnitAmount: 1000}.params("prod_1")
+			assert.Equal(t, "jpy", *params.Currency)
+			assert.Equal(t, "month", *params.Recurring.Interval)
+			assert.Nil(t, params.Recurring.IntervalCount)
+			assert.Nil(t, params.TaxBehavior)
+		},
+	)
+
+	t.Run(
+		"sets currency, interval and tax behavior", func(t *testing.T) {
+			params := PriceOptions{
+				UnitAmount:    9900,
+				Currency:      stripe.CurrencyUSD,
+				Interval:      stripe.PriceRecurringIntervalYear,
+				IntervalCount: 2,
+				TaxBehavior:   stripe.PriceTaxBehaviorExclusive,
+			}.params("prod_1")
+			assert.Equal(t, "usd", *params.Currency)
+			assert.Equal(t, "year", *params.Recurring.Interval)
+			assert.Equal(t, int64(2), *params.Recurring.IntervalCount)
+			assert.Equal(t, "exclusive", *params.TaxBehavior)
+		},
+	)
+}
+
+func TestSubscriptionOptionsParams(t *testing.T) {
+	params := SubscriptionOptions{CustomerID: "cus_1", PriceID: "price_1"}.params()
+	assert.Equal(t, int64(1), *params.Items[0].Quantity)
+	assert.Equal(t, int64(1), *params.BillingCycleAnchorConfig.DayOfMonth, "bills on the 1st by default")
+	assert.Nil(t, params.TrialPeriodDays)
+	assert.Nil(t, params.PromotionCode)
+	assert.Nil(t, params.AutomaticTax)
+
+	params = SubscriptionOptions{
+		CustomerID:            "cus_1",
+		PriceID:               "price_1",
+		Quantity:              5,
+		TrialDays:             14,
+		BillingCycleAnchorDay: 15,
+		PromotionCode:         "promo_1",
+	}.params()
+	assert.Equal(t, int64(5), *params.Items[0].Quantity)
+	assert.Equal(t, int64(14), *params.TrialPeriodDays)
+	assert.Equal(t, int64(15), *params.BillingCycleAnchorConfig.DayOfMonth)
+	assert.Equal(t, "promo_1", *params.PromotionCode)
+
+	params = SubscriptionOptions{
+		CustomerID:        "cus_1",
+		PriceID:           "price_weekly",
+		AnchorToStartDate: true,
+		Coupon:            "co_1",
+		AutomaticTax:      true,
+	}.params()
+	assert.Nil(t, params.BillingCycleAnchorConfig)
+	assert.Equal(t, "co_1", *params.Coupon)
+	assert.True(t, *params.AutomaticTax.Enabled)
+}
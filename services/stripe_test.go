@@ -1,41 +1,18 @@
 package services
 
 import (
-	"bytes"
+	"context"
 	"testing"
 
 	"boilerplate-api/lib/config"
+	"boilerplate-api/tests"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stripe/stripe-go/v76"
-	"github.com/stripe/stripe-go/v76/form"
 )
 
-type stripeBackendMock struct {
-	mock.Mock
-}
-
-func (s *stripeBackendMock) Call(
-	method,
-	path,
-	key string,
-	params stripe.ParamsContainer,
-	v stripe.LastResponseSetter,
-) error {
-	args := s.Called(method, path, key, params, v)
-	return args.Error(0)
-}
-
-func (s *stripeBackendMock) CallStreaming(
-	method,
-	path,
-	key string,
-	params stripe.ParamsContainer,
-	v stripe.StreamingLastResponseSetter,
-) error {
-	args := s.Called(method, path, key, params, v)
-	return args.Error(0)
-}
+// stripeBackendMock shared with the tests of other packages
+type stripeBackendMock = tests.StripeBackendMock
 
 // MockEnv is a mock implementation of config.Env
 type MockEnv struct {
@@ -47,35 +24,6 @@ func (m *MockEnv) GetEnvironment() string {
 	return args.String(0)
 }
 
-func (s *stripeBackendMock) CallRaw(
-	method,
-	path,
-	key string,
-	body *form.Values,
-	params *stripe.Params,
-	v stripe.LastResponseSetter,
-) error {
-	args := s.Called(method, path, key, params, v)
-	return args.Error(0)
-}
-
-func (s *stripeBackendMock) CallMultipart(
-	method,
-	path,
-	key,
-	boundary string,
-	body *bytes.Buffer,
-	params *stripe.Params,
-	v stripe.LastResponseSetter,
-) error {
-	args := s.Called(method, path, key, boundary, body, params, v)
-	return args.Error(0)
-}
-
-func (s *stripeBackendMock) SetMaxNetworkRetries(maxNetworkRetries int64) {
-	s.Called(maxNetworkRetries)
-}
-
 func TestCreateCustomer(t *testing.T) {
 	stripeBackendMock := new(stripeBackendMock)
 	//stripeTestBackends := &stripe.Backends{
@@ -119,7 +67,7 @@ func TestCreateCustomer(t *testing.T) {
 		"Test if user is created is stripe", func(t *testing.T) {
 			name := *stripe.String("test")
 			email := *stripe.String("test@gmail.com")
-			customer, err := stripeService.CreateCustomer(name, email)
+			customer, err := stripeService.CreateCustomer(context.Background(), name, email, "")
 			if err != nil {
 				t.Error(err)
 				return
@@ -171,10 +119,10 @@ func TestCreateSubscription(t *testing.T) {
 	).Return(nil).Once()
 
 	stripeService.CreateSubscription(
-		CustomerSubscription{
-			StripeCustomerID: "test@gmail.com",
-			StripePriceID:    "test@gmail.com",
-		}, nil,
+		context.Background(), SubscriptionOptions{
+			CustomerID: "test@gmail.com",
+			PriceID:    "test@gmail.com",
+		},
 	)
 }
 
@@ -215,7 +163,7 @@ func TestCreatePrices(t *testing.T) {
 			}
 		},
 	).Return(nil).Once()
-	stripeService.CreatePrices("Test Price", 1000)
+	stripeService.CreatePrices(context.Background(), PriceOptions{Title: "Test Price", UnitAmount: 1000})
 }
 
 func TestPaymentIntent(t *testing.T) {
@@ -261,7 +209,7 @@ func TestPaymentIntent(t *testing.T) {
 		Enabled: stripe.Bool(true),
 	}
 	stripeService.CreatePaymentIntent(
-		&stripe.PaymentIntentParams{
+		context.Background(), &stripe.PaymentIntentParams{
 			Amount:                  stripe.Int64(1000),
 			Currency:                stripe.String("USD"),
 			AutomaticPaymentMethods: &paymentMethod,
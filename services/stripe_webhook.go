@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"boilerplate-api/lib/api_errors"
+
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/webhook"
+)
+
+// ErrWebhookNotConfigured returned when STRIPE_WEBHOOK_KEY is not set
+var ErrWebhookNotConfigured = errors.New("stripe webhook key is not configured")
+
+// ConstructWebhookEvent verifies Stripe-Signature header of the payload and parses the event
+//
+// api version of the event is not checked, the webhook endpoint version is managed on the stripe dashboard
+func (service StripeService) ConstructWebhookEvent(payload []byte, signature string) (stripe.Event, error) {
+	if service.stripeWebhookKey == "" {
+		return stripe.Event{}, &Error{
+			Provider:   Providers.Stripe,
+			Code:       "webhook_not_configured",
+			Message:    "Webhook is not configured",
+			HTTPStatus: api_errors.InternalError,
+			Err:        ErrWebhookNotConfigured,
+		}
+	}
+
+	event, err := webhook.ConstructEventWithOptions(
+		payload, signature, service.stripeWebhookKey, webhook.ConstructEventOptions{
+			IgnoreAPIVersionMismatch: true,
+		},
+	)
+	if err != nil {
+		return event, &Error{
+			Provider:   Providers.Stripe,
+			Code:       "invalid_signature",
+			Message:    "Invalid webhook signature",
+			HTTPStatus: api_errors.BadRequest,
+			Err:        err,
+		}
+	}
+	return event, nil
+}
+
+// StripeWebhookHandlers typed handlers of stripe webhook events, nil handlers are skipped
+type StripeWebhookHandlers struct {
+	InvoicePaid          func(ctx context.Context, event stripe.Event, invoice *stripe.Invoice) error
+	InvoicePaymentFailed func(ctx context.Context, event stripe.Event, invoice *stripe.Invoice) error
+	SubscriptionCreated  func(ctx context.Context, event stripe.Event, subscription *stripe.Subscription) error
+	SubscriptionUpdated  func(ctx context.Context, event stripe.Event, subscription *stripe.Subscription) error
+	SubscriptionDeleted  func(ctx context.Context, event stripe.Event, subscription *stripe.Subscription) error
+}
+
+// Dispatch decodes event object and calls handler of the event type
+//
+// events without handler are ignored
+func (h StripeWebhookHandlers) Dispatch(ctx context.Context, event stripe.Event) error {
+	switch event.Type {
+	case stripe.EventTypeInvoicePaid:
+		return dispatchStripeEvent(ctx, event, h.InvoicePaid)
+	case stripe.EventTypeInvoicePaymentFailed:
+		return dispatchStripeEvent(ctx, event, h.InvoicePaymentFailed)
+	case stripe.EventTypeCustomerSubscriptionCreated:
+		return dispatchStripeEvent(ctx, event, h.SubscriptionCreated)
+	case stripe.EventTypeCustomerSubscriptionUpdated:
+		return dispatchStripeEvent(ctx, event, h.SubscriptionUpdated)
+	case stripe.EventTypeCustomerSubscriptionDeleted:
+		return dispatchStripeEvent(ctx, event, h.SubscriptionDeleted)
+	}
+	return nil
+}
+
+func dispatchStripeEvent[T any](
+	ctx context.Context,
+	event stripe.Event,
+	handler func(context.Context, stripe.Event, *T) error,
+) error {
+	if handler == nil {
+		return nil
+	}
+	if event.Data == nil {
+		return fmt.Errorf("event %s has no data", event.ID)
+	}
+
+	object := new(T)
+	if err := json.Unmarshal(event.Data.Raw, object); err != nil {
+		return fmt.Errorf("decoding %s event %s: %w", event.Type, event.ID, err)
+	}
+	return handler(ctx, event, object)
+}
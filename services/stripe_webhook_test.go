@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"boilerplate-api/lib/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/webhook"
+)
+
+func newTestStripeService(backend *stripeBackendMock) StripeService {
+	return NewStripeService(
+		StripeConfig{
+			stripeSecretKey:  "sk_test",
+			stripeWebhookKey: "whsec_test",
+			backends: &stripe.Backends{
+				API:     backend,
+				Connect: backend,
+				Uploads: backend,
+			},
+			logger: config.GetLogger().SugaredLogger,
+		},
+	)
+}
+
+func TestConstructWebhookEvent(t *testing.T) {
+	stripeService := newTestStripeService(new(stripeBackendMock))
+	payload := []byte(`{"id":"evt_1","object":"event","type":"invoice.paid","api_version":"2020-08-27","data":{"object":{"id":"in_1"}}}`)
+
+	t.Run(
+		"accepts valid signature regardless of api version", func(t *testing.T) {
+			signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: "whsec_test"})
+
+			event, err := stripeService.ConstructWebhookEvent(signed.Payload, signed.Header)
+			assert.NoError(t, err)
+			assert.Equal(t, "evt_1", event.ID)
+			assert.Equal(t, stripe.EventTypeInvoicePaid, event.Type)
+		},
+	)
+
+	t.Run(
+		"rejects signature of another secret", func(t *testing.T) {
+			signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: "whsec_other"})
+
+			_, err := stripeService.ConstructWebhookEvent(signed.Payload, signed.Header)
+			assert.Error(t, err)
+		},
+	)
+
+	t.Run(
+		"fails when webhook key is not configured", func(t *testing.T) {
+			_, err := StripeService{}.ConstructWebhookEvent(payload, "")
+			assert.ErrorIs(t, err, ErrWebhookNotConfigured)
+		},
+	)
+}
+
+func TestStripeWebhookHandlersDispatch(t *testing.T) {
+	var paid, deleted []string
+	handlers := StripeWebhookHandlers{
+		InvoicePaid: func(_ context.Context, event stripe.Event, invoice *stripe.Invoice) error {
+			paid = append(paid, invoice.ID)
+			return nil
+		},
+		SubscriptionDeleted: func(_ context.Context, event stripe.Event, subscription *stripe.Subscription) error {
+			deleted = append(deleted, subscription.ID)
+			return errors.New("handler failed")
+		},
+	}
+
+	newEvent := func(eventType stripe.EventType, object string) stripe.Event {
+		return stripe.Event{ID: "evt_1", Type: eventType, Data: &stripe.EventData{Raw: []byte(object)}}
+	}
+
+	assert.NoError(t, handlers.Dispatch(context.Background(), newEvent(stripe.EventTypeInvoicePaid, `{"id":"in_1"}`)))
+	assert.Equal(t, []string{"in_1"}, paid)
+
+	err := handlers.Dispatch(context.Background(), newEvent(stripe.EventTypeCustomerSubscriptionDeleted, `{"id":"sub_1"}`))
+	assert.EqualError(t, err, "handler failed")
+	assert.Equal(t, []string{"sub_1"}, deleted)
+
+	// event types without handler are ignored
+	assert.NoError(t, handlers.Dispatch(context.Background(), newEvent(stripe.EventTypeInvoicePaymentFailed, `{"id":"in_2"}`)))
+	assert.NoError(t, handlers.Dispatch(context.Background(), newEvent(stripe.EventTypeCustomerCreated, `{"id":"cus_1"}`)))
+
+	assert.Error(t, handlers.Dispatch(context.Background(), newEvent(stripe.EventTypeInvoicePaid, `not json`)))
+}
+
+func TestGetSubscription(t *testing.T) {
+	backend := new(stripeBackendMock)
+	stripeService := newTestStripeService(backend)
+
+	backend.On(
+		"Call",
+		"GET",
+		"/v1/subscriptions/sub_1",
+		"sk_test",
+		mock.Anything,
+		mock.Anything,
+	).Run(
+		func(args mock.Arguments) {
+			params := args.Get(3).(*stripe.SubscriptionParams)
+			assert.Contains(t, params.Expand, stripe.String("items.data.price"))
+
+			subscription := args.Get(4).(*stripe.Subscription)
+			*subscription = stripe.Subscription{
+				ID:     "sub_1",
+				Status: stripe.SubscriptionStatusPastDue,
+			}
+		},
+	).Return(nil).Once()
+
+	subscription, err := stripeService.GetSubscription(context.Background(), "sub_1")
+	assert.NoError(t, err)
+	assert.Equal(t, stripe.SubscriptionStatusPastDue, subscription.Status)
+	backend.AssertExpectations(t)
+}
@@ -2,12 +2,14 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"mime/multipart"
 	"net/http"
+	"strconv"
 )
 
 type PhoneMessage struct {
@@ -15,8 +17,8 @@ type PhoneMessage struct {
 	Message string
 }
 
-// ErrorResponse twilio error response
-type ErrorResponse struct {
+// TwilioErrorResponse twilio error response
+type TwilioErrorResponse struct {
 	Code     uint   `json:"code"`
 	Message  string `json:"message"`
 	MoreInfo string `json:"more_info"`
@@ -78,11 +80,10 @@ type SMSInput struct {
 	Body string
 }
 
-func (t TwilioService) SendSMS(input SMSInput) (*SuccessResponse, *ErrorResponse, error) {
+// SendSMS sends sms, rejected messages are returned as *Error with the twilio error code
+func (t TwilioService) SendSMS(ctx context.Context, input SMSInput) (*SuccessResponse, error) {
 	url := fmt.Sprintf("%s/Accounts/%s/Messages.json", t.baseURL, t.sID)
 
-	t.logger.Info(url)
-
 	method := "POST"
 
 	payload := &bytes.Buffer{}
@@ -93,47 +94,50 @@ func (t TwilioService) SendSMS(input SMSInput) (*SuccessResponse, *ErrorResponse
 
 	err := writer.Close()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	client := &http.Client{}
-	req, err := http.NewRequest(method, url, payload)
+	req, err := http.NewRequestWithContext(ctx, method, url, payload)
 
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	token := fmt.Sprintf("Basic %s", t.getBasicToken())
-	t.logger.Info(token)
-
-	req.Header.Add("Authorization", token)
+	req.Header.Add("Authorization", fmt.Sprintf("Basic %s", t.getBasicToken()))
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	res, err := client.Do(req)
 	if err != nil {
-		return nil, nil, err
+		return nil, NewError(Providers.Twilio, 0, "", "Failed to send sms", err)
 	}
 	defer res.Body.Close()
 
-	body, err := ioutil.ReadAll(res.Body)
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, nil, err
+		return nil, NewError(Providers.Twilio, 0, "", "Failed to send sms", err)
 	}
 	if res.StatusCode != http.StatusCreated {
-		result := ErrorResponse{}
+		result := TwilioErrorResponse{}
 		if err := json.Unmarshal(body, &result); err != nil {
-			return nil, nil, err
+			return nil, NewError(Providers.Twilio, res.StatusCode, "", "Failed to send sms", err)
 		}
 
-		return nil, &result, nil
+		return nil, NewError(
+			Providers.Twilio,
+			res.StatusCode,
+			strconv.FormatUint(uint64(result.Code), 10),
+			"Failed to send sms",
+			fmt.Errorf("%s (%s)", result.Message, result.MoreInfo),
+		)
 	}
 
 	result := SuccessResponse{}
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	return &result, nil, nil
+	return &result, nil
 
 }
 
@@ -142,8 +146,8 @@ func (t TwilioService) getBasicToken() string {
 	return base64.StdEncoding.EncodeToString([]byte(token))
 }
 
-func (t TwilioService) MessageSuccess(payload PhoneMessage) error {
-	_, twilioErr, err := t.SendSMS(SMSInput{
+func (t TwilioService) MessageSuccess(ctx context.Context, payload PhoneMessage) error {
+	_, err := t.SendSMS(ctx, SMSInput{
 		From: t.smsFrom,
 		To:   payload.Phone,
 		Body: payload.Message,
@@ -152,9 +156,5 @@ func (t TwilioService) MessageSuccess(payload PhoneMessage) error {
 		t.logger.Error("user message send error: ", err.Error())
 		return err
 	}
-	if twilioErr != nil {
-		t.logger.Errorf("twilio message send error: %+v \n", twilioErr)
-		return err
-	}
 	return nil
 }
@@ -0,0 +1,8 @@
+package templates
+
+import "embed"
+
+// Emails email layouts, partials, samples and templates per locale
+//
+//go:embed emails
+var Emails embed.FS
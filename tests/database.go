@@ -0,0 +1,48 @@
+// Package tests helpers of the tests running repositories and controllers against a real database
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"boilerplate-api/lib/config"
+)
+
+// NewEnv env of the test database, a sqlite file in the temp directory of the test
+func NewEnv(t testing.TB) config.Env {
+	return config.Env{
+		Environment: "test",
+		TimeZone:    "UTC",
+		DBType:      config.DBTypeSqlite.ToString(),
+		DBName:      filepath.Join(t.TempDir(), "test.db"),
+	}
+}
+
+// NewDatabase fresh database of the test with the migrations applied, it is closed when the test ends
+func NewDatabase(t testing.TB) *config.Database {
+	t.Helper()
+	logger := config.GetLogger()
+	env := NewEnv(t)
+
+	db := config.NewDatabase(logger, config.NewDSNConfig(env))
+	if db.ConnectionError != nil {
+		t.Fatalf("test database: %v", *db.ConnectionError)
+	}
+	t.Cleanup(
+		func() {
+			if sqlDB, err := db.DB.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		},
+	)
+
+	migrations, err := config.NewMigrations(logger, env, db)
+	if err != nil {
+		t.Fatalf("test database migrations: %v", err)
+	}
+	if err := migrations.Up(context.Background()); err != nil {
+		t.Fatalf("test database migrations: %v", err)
+	}
+	return db
+}
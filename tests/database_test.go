@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"boilerplate-api/database/dao"
+	"boilerplate-api/lib/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMigrations sqlite migrations roll back and apply again
+func TestMigrations(t *testing.T) {
+	db := NewDatabase(t)
+	migrations, err := config.NewMigrations(config.GetLogger(), NewEnv(t), db)
+	assert.NoError(t, err)
+
+	status, err := migrations.Status()
+	assert.NoError(t, err)
+	assert.NotZero(t, status.Version)
+	assert.Empty(t, status.Pending)
+
+	var templates int64
+	assert.NoError(t, db.Model(&dao.NotificationTemplate{}).Count(&templates).Error)
+	assert.NotZero(t, templates)
+
+	assert.NoError(t, migrations.Down(context.Background()))
+	tables, err := db.Migrator().GetTables()
+	assert.NoError(t, err)
+	assert.NotContains(t, tables, "users")
+
+	assert.NoError(t, migrations.Up(context.Background()))
+	version, dirty, err := migrations.Version()
+	assert.NoError(t, err)
+	assert.False(t, dirty)
+	assert.Equal(t, status.Version, version)
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"bytes"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/form"
+)
+
+// StripeBackendMock stripe api backend answering the calls with the expectations of the test
+type StripeBackendMock struct {
+	mock.Mock
+}
+
+func (s *StripeBackendMock) Call(
+	method,
+	path,
+	key string,
+	params stripe.ParamsContainer,
+	v stripe.LastResponseSetter,
+) error {
+	args := s.Called(method, path, key, params, v)
+	return args.Error(0)
+}
+
+func (s *StripeBackendMock) CallStreaming(
+	method,
+	path,
+	key string,
+	params stripe.ParamsContainer,
+	v stripe.StreamingLastResponseSetter,
+) error {
+	args := s.Called(method, path, key, params, v)
+	return args.Error(0)
+}
+
+func (s *StripeBackendMock) CallRaw(
+	method,
+	path,
+	key string,
+	body *form.Values,
+	params *stripe.Params,
+	v stripe.LastResponseSetter,
+) error {
+	args := s.Called(method, path, key, params, v)
+	return args.Error(0)
+}
+
+func (s *StripeBackendMock) CallMultipart(
+	method,
+	path,
+	key,
+	boundary string,
+	body *bytes.Buffer,
+	params *stripe.Params,
+	v stripe.LastResponseSetter,
+) error {
+	args := s.Called(method, path, key, boundary, body, params, v)
+	return args.Error(0)
+}
+
+func (s *StripeBackendMock) SetMaxNetworkRetries(maxNetworkRetries int64) {
+	s.Called(maxNetworkRetries)
+}
+
+// Backends all stripe api backends answered by the mock
+func (s *StripeBackendMock) Backends() *stripe.Backends {
+	return &stripe.Backends{API: s, Connect: s, Uploads: s}
+}